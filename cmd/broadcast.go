@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/riricardoMa/claude-grid/internal/script"
+	"github.com/riricardoMa/claude-grid/internal/session"
+	"github.com/riricardoMa/claude-grid/internal/terminal"
+	"github.com/spf13/cobra"
+)
+
+func NewBroadcastCmd(storePath string, executor script.ScriptExecutor) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "broadcast <session-name> <text>",
+		Short: "Send the same input to every window in a session",
+		Long: `Send the same input to every window in a session, as if it were typed into
+each window followed by Return. For example:
+
+  claude-grid broadcast sprint "run the tests and commit"`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionName, text := args[0], args[1]
+
+			store := session.NewStore(storePath)
+			sess, err := loadSession(store, sessionName, cmd.ErrOrStderr())
+			if err != nil {
+				return err
+			}
+
+			if sess.Status == "stopped" {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Session '%s' is stopped. Run 'claude-grid resume %s' first.\n", sessionName, sessionName)
+				return fmt.Errorf("session '%s' is stopped", sessionName)
+			}
+
+			sender, err := inputSenderForSession(sess, executor)
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				return err
+			}
+
+			windows := append([]session.WindowRef(nil), sess.Windows...)
+			sort.SliceStable(windows, func(i, j int) bool { return windows[i].Index < windows[j].Index })
+
+			failed := 0
+			for _, w := range windows {
+				if err := sender.SendInput(cmd.Context(), w, text); err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Warning: window %d: %v\n", w.Index+1, err)
+					failed++
+				}
+			}
+
+			sent := len(windows) - failed
+			fmt.Fprintf(cmd.OutOrStdout(), "Sent to %d/%d windows in session '%s'.\n", sent, len(windows), sessionName)
+			if failed > 0 {
+				return fmt.Errorf("failed to send to %d windows", failed)
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// inputSenderForSession returns the session's backend if it can send input to
+// its windows.
+func inputSenderForSession(sess session.Session, executor script.ScriptExecutor) (terminal.InputSender, error) {
+	if sess.Backend == headlessBackend {
+		return nil, fmt.Errorf("headless agents do not read input")
+	}
+	backend, err := backendForSession(sess.Backend, executor)
+	if err != nil {
+		return nil, err
+	}
+	sender, ok := backend.(terminal.InputSender)
+	if !ok {
+		return nil, fmt.Errorf("the %s backend cannot send input to windows", backend.Name())
+	}
+	return sender, nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/riricardoMa/claude-grid/internal/session"
+)
+
+func runBroadcast(t *testing.T, storeDir string, executor *stubExecutor, args ...string) (string, string, error) {
+	t.Helper()
+	cmd := NewBroadcastCmd(storeDir, executor)
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+func TestBroadcastSendsToEveryWindow(t *testing.T) {
+	storeDir := t.TempDir()
+	store := session.NewStore(storeDir)
+	if err := store.SaveSession(session.Session{
+		Name:    "sprint",
+		Backend: "terminal",
+		Count:   2,
+		Status:  "active",
+		Windows: []session.WindowRef{{ID: "43", Index: 1}, {ID: "42", Index: 0}},
+	}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	executor := &stubExecutor{}
+	stdout, _, err := runBroadcast(t, storeDir, executor, "sprint", "run the tests and commit")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if len(executor.scripts) != 2 {
+		t.Fatalf("script count = %d, want 2", len(executor.scripts))
+	}
+	for i, id := range []string{"42", "43"} {
+		want := `do script "run the tests and commit" in window id ` + id
+		if !strings.Contains(executor.scripts[i], want) {
+			t.Errorf("script %d = %q, want it to contain %q", i, executor.scripts[i], want)
+		}
+	}
+	if !strings.Contains(stdout, "Sent to 2/2 windows") {
+		t.Errorf("stdout = %q, want summary", stdout)
+	}
+}
+
+func TestBroadcastRejectsStoppedSession(t *testing.T) {
+	storeDir := t.TempDir()
+	store := session.NewStore(storeDir)
+	if err := store.SaveSession(session.Session{
+		Name:    "sprint",
+		Backend: "terminal",
+		Count:   1,
+		Status:  "stopped",
+		Windows: []session.WindowRef{{ID: "42", Index: 0}},
+	}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	executor := &stubExecutor{}
+	_, stderr, err := runBroadcast(t, storeDir, executor, "sprint", "hello")
+	if err == nil {
+		t.Fatal("Execute() error = nil, want error")
+	}
+	if !strings.Contains(stderr, "claude-grid resume sprint") {
+		t.Errorf("stderr = %q, want resume hint", stderr)
+	}
+	if len(executor.scripts) != 0 {
+		t.Errorf("scripts run = %d, want 0", len(executor.scripts))
+	}
+}
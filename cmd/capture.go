@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/riricardoMa/claude-grid/internal/script"
+	"github.com/riricardoMa/claude-grid/internal/session"
+	"github.com/riricardoMa/claude-grid/internal/terminal"
+	"github.com/spf13/cobra"
+)
+
+// windowCapture is the captured text of one window. Window is numbered from 1.
+type windowCapture struct {
+	Window int    `json:"window" yaml:"window"`
+	Output string `json:"output" yaml:"output"`
+	Error  string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func NewCaptureCmd(storePath string, executor script.ScriptExecutor) *cobra.Command {
+	var output outputOptions
+	var scrollback bool
+	var follow bool
+	var interval time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "capture <session-name> [window]",
+		Short: "Print the text shown in a session's windows",
+		Long: `Print the text currently shown in one window of a session, or in all of them.
+Windows are numbered from 1 in spawn order. With --follow, keep polling and
+print new lines as they appear, prefixed with the window number when more
+than one window is followed.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionName := args[0]
+
+			if err := output.validate(); err != nil {
+				return err
+			}
+			if follow && output.structured() {
+				return fmt.Errorf("--follow cannot be combined with --output or --template")
+			}
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+
+			store := session.NewStore(storePath)
+			sess, err := loadSession(store, sessionName, cmd.ErrOrStderr())
+			if err != nil {
+				return err
+			}
+
+			// Run logs of headless sessions outlive their agents.
+			if sess.Status == "stopped" && sess.Backend != headlessBackend {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Session '%s' is stopped. Run 'claude-grid resume %s' first.\n", sessionName, sessionName)
+				return fmt.Errorf("session '%s' is stopped", sessionName)
+			}
+
+			windows := append([]session.WindowRef(nil), sess.Windows...)
+			sort.SliceStable(windows, func(i, j int) bool { return windows[i].Index < windows[j].Index })
+			if len(args) == 2 {
+				window, err := windowByNumber(sess, args[1])
+				if err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+					return err
+				}
+				windows = []session.WindowRef{window}
+			}
+
+			capturer, err := outputCapturerForSession(sess, executor)
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				return err
+			}
+
+			live, known := liveWindowIDs(cmd.Context(), executor, sess)
+			if sess.Backend == headlessBackend {
+				// Run logs can be read whether or not their agent still runs.
+				known = false
+			}
+			var open []session.WindowRef
+			var captures []windowCapture
+			for _, w := range windows {
+				if known && !live[w.ID] {
+					captures = append(captures, windowCapture{Window: w.Index + 1, Error: "window is no longer open"})
+					continue
+				}
+				open = append(open, w)
+			}
+
+			if follow {
+				for _, c := range captures {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Warning: window %d: %s\n", c.Window, c.Error)
+				}
+				if len(open) == 0 {
+					return fmt.Errorf("no open windows to follow in session '%s'", sessionName)
+				}
+				return followCaptures(cmd.Context(), capturer, open, scrollback, interval, cmd.OutOrStdout(), cmd.ErrOrStderr())
+			}
+
+			for _, w := range open {
+				text, err := capturer.CaptureOutput(cmd.Context(), w, scrollback)
+				c := windowCapture{Window: w.Index + 1, Output: text}
+				if err != nil {
+					c.Error = err.Error()
+				}
+				captures = append(captures, c)
+			}
+			sort.SliceStable(captures, func(i, j int) bool { return captures[i].Window < captures[j].Window })
+
+			failed := 0
+			for _, c := range captures {
+				if c.Error != "" {
+					failed++
+				}
+			}
+
+			if output.structured() {
+				if err := printResults(&output, cmd.OutOrStdout(), captures); err != nil {
+					return err
+				}
+			} else {
+				printCaptures(cmd.OutOrStdout(), cmd.ErrOrStderr(), captures)
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("failed to capture %d windows", failed)
+			}
+			return nil
+		},
+	}
+
+	output.addFlags(cmd)
+	cmd.Flags().BoolVar(&scrollback, "scrollback", false, "Include the window's scrollback history, not just the visible screen")
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "Keep polling and print new lines as they appear")
+	cmd.Flags().DurationVar(&interval, "interval", time.Second, "Polling interval for --follow")
+
+	return cmd
+}
+
+// printCaptures writes captured text, with a header per window when there is
+// more than one, like tail does for several files.
+func printCaptures(stdout, stderr io.Writer, captures []windowCapture) {
+	for i, c := range captures {
+		if c.Error != "" {
+			fmt.Fprintf(stderr, "Warning: window %d: %s\n", c.Window, c.Error)
+			continue
+		}
+		if len(captures) > 1 {
+			if i > 0 {
+				fmt.Fprintln(stdout)
+			}
+			fmt.Fprintf(stdout, "==> window %d <==\n", c.Window)
+		}
+		fmt.Fprintln(stdout, strings.TrimRight(c.Output, "\n"))
+	}
+}
+
+// followCaptures polls windows every interval until ctx is done, printing the
+// lines that are new since the previous poll. The last line of a capture is
+// only printed once a later line follows it, since it may still be typed to.
+func followCaptures(ctx context.Context, capturer terminal.OutputCapturer, windows []session.WindowRef, scrollback bool, interval time.Duration, stdout, stderr io.Writer) error {
+	previous := make(map[string][]string, len(windows))
+	failing := make(map[string]bool, len(windows))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, w := range windows {
+			text, err := capturer.CaptureOutput(ctx, w, scrollback)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				if !failing[w.ID] {
+					fmt.Fprintf(stderr, "Warning: window %d: %v\n", w.Index+1, err)
+					failing[w.ID] = true
+				}
+				continue
+			}
+			failing[w.ID] = false
+
+			lines := completeLines(text)
+			for _, line := range newLines(previous[w.ID], lines) {
+				if len(windows) > 1 {
+					fmt.Fprintf(stdout, "[%d] %s\n", w.Index+1, line)
+				} else {
+					fmt.Fprintln(stdout, line)
+				}
+			}
+			previous[w.ID] = lines
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// completeLines splits captured text into lines, dropping trailing blank lines
+// and the last line, which may still be incomplete.
+func completeLines(text string) []string {
+	lines := strings.Split(strings.TrimRight(text, "\n "), "\n")
+	return lines[:len(lines)-1]
+}
+
+// maxFooterLines bounds the block below an agent's output, such as its input
+// box, hint and status lines, that followCaptures lets differ between polls.
+const maxFooterLines = 10
+
+// newLines returns the lines of cur that are new since prev. cur continues
+// prev from the earliest line of prev it matches, as long as the lines of
+// prev after the match fit in a footer of at most maxFooterLines, which TUIs
+// redraw below their output. Trailing lines that cur ends with like prev are
+// that footer unchanged and are not printed again. When the two do not line
+// up, all of cur is new.
+func newLines(prev, cur []string) []string {
+	for d := range prev {
+		m := commonPrefix(prev[d:], cur)
+		if m == 0 || len(prev)-d-m > maxFooterLines {
+			continue
+		}
+		fresh := cur[m:]
+		return fresh[:len(fresh)-commonSuffix(prev, fresh)]
+	}
+	return cur[:len(cur)-commonSuffix(prev, cur)]
+}
+
+// commonPrefix returns how many lines a and b start with alike.
+func commonPrefix(a, b []string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// commonSuffix returns how many lines a and b end with alike.
+func commonSuffix(a, b []string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[len(a)-1-n] == b[len(b)-1-n] {
+		n++
+	}
+	return n
+}
+
+// outputCapturerForSession returns the session's backend if it can read back
+// the text of its windows. Headless sessions are read from their run logs.
+func outputCapturerForSession(sess session.Session, executor script.ScriptExecutor) (terminal.OutputCapturer, error) {
+	if sess.Backend == headlessBackend {
+		return runLogCapturer{}, nil
+	}
+	backend, err := backendForSession(sess.Backend, executor)
+	if err != nil {
+		return nil, err
+	}
+	capturer, ok := backend.(terminal.OutputCapturer)
+	if !ok {
+		return nil, fmt.Errorf("the %s backend cannot capture window output", backend.Name())
+	}
+	return capturer, nil
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/riricardoMa/claude-grid/internal/session"
+)
+
+// scriptedExecutor answers the liveness query with windowIDs and every other
+// script with the result of capture.
+type scriptedExecutor struct {
+	windowIDs string
+	capture   func(script string) string
+	scripts   []string
+}
+
+func (s *scriptedExecutor) RunAppleScript(ctx context.Context, script string) (string, error) {
+	s.scripts = append(s.scripts, script)
+	if strings.Contains(script, "get id of every window") {
+		return s.windowIDs, nil
+	}
+	return s.capture(script), nil
+}
+
+func runCapture(t *testing.T, storeDir string, executor *scriptedExecutor, args ...string) (string, string, error) {
+	t.Helper()
+	saveSendSession(t, storeDir)
+	cmd := NewCaptureCmd(storeDir, executor)
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+func captureByWindow(script string) string {
+	switch {
+	case strings.Contains(script, "window id 42"):
+		return "agent one\n"
+	case strings.Contains(script, "window id 43"):
+		return "agent two\n"
+	}
+	return ""
+}
+
+func TestCaptureAllWindows(t *testing.T) {
+	executor := &scriptedExecutor{windowIDs: "42, 43", capture: captureByWindow}
+	stdout, _, err := runCapture(t, t.TempDir(), executor, "sprint")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	want := "==> window 1 <==\nagent one\n\n==> window 2 <==\nagent two\n"
+	if stdout != want {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+}
+
+func TestCaptureOneWindowScrollback(t *testing.T) {
+	executor := &scriptedExecutor{windowIDs: "42, 43", capture: captureByWindow}
+	stdout, _, err := runCapture(t, t.TempDir(), executor, "sprint", "2", "--scrollback")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if stdout != "agent two\n" {
+		t.Errorf("stdout = %q, want only window 2 without a header", stdout)
+	}
+	last := executor.scripts[len(executor.scripts)-1]
+	if !strings.Contains(last, "get history of selected tab of window id 43") {
+		t.Errorf("script = %q, want history of window 43", last)
+	}
+}
+
+func TestCaptureOutputJSONReportsClosedWindows(t *testing.T) {
+	executor := &scriptedExecutor{windowIDs: "42", capture: captureByWindow}
+	stdout, _, err := runCapture(t, t.TempDir(), executor, "sprint", "-o", "json")
+	if err == nil {
+		t.Fatal("Execute() error = nil, want error for the closed window")
+	}
+
+	var got []windowCapture
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, stdout)
+	}
+	want := []windowCapture{
+		{Window: 1, Output: "agent one\n"},
+		{Window: 2, Error: "window is no longer open"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("captures = %+v, want %+v", got, want)
+	}
+}
+
+type fakeCapturer struct {
+	outputs []string
+	calls   int
+	cancel  context.CancelFunc
+}
+
+func (f *fakeCapturer) CaptureOutput(ctx context.Context, window session.WindowRef, scrollback bool) (string, error) {
+	out := f.outputs[f.calls]
+	f.calls++
+	if f.calls == len(f.outputs) {
+		f.cancel()
+	}
+	return out, nil
+}
+
+func TestFollowCapturesPrintsNewLines(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	capturer := &fakeCapturer{
+		outputs: []string{
+			"a\nb\n> ty",
+			"a\nb\n> typed\nc\n> ",
+			"b\n> typed\nc\nd\n> ",
+		},
+		cancel: cancel,
+	}
+
+	var stdout, stderr bytes.Buffer
+	windows := []session.WindowRef{{ID: "42", Index: 0}}
+	if err := followCaptures(ctx, capturer, windows, false, time.Millisecond, &stdout, &stderr); err != nil {
+		t.Fatalf("followCaptures() error = %v", err)
+	}
+
+	if want := "a\nb\n> typed\nc\nd\n"; stdout.String() != want {
+		t.Errorf("stdout = %q, want %q", stdout.String(), want)
+	}
+}
+
+func TestFollowCapturesSkipsFooter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	footer := "╭────────╮\n│ >      │\n╰────────╯\n? for shortcuts"
+	capturer := &fakeCapturer{
+		outputs: []string{
+			"a\nb\n" + footer,
+			"a\nb\nc\n" + footer,
+			"b\nc\nd\n" + footer,
+			"b\nc\nd\n" + footer,
+		},
+		cancel: cancel,
+	}
+
+	var stdout, stderr bytes.Buffer
+	windows := []session.WindowRef{{ID: "42", Index: 0}}
+	if err := followCaptures(ctx, capturer, windows, false, time.Millisecond, &stdout, &stderr); err != nil {
+		t.Fatalf("followCaptures() error = %v", err)
+	}
+
+	if want := "a\nb\n╭────────╮\n│ >      │\n╰────────╯\nc\nd\n"; stdout.String() != want {
+		t.Errorf("stdout = %q, want %q", stdout.String(), want)
+	}
+}
+
+func TestNewLines(t *testing.T) {
+	tests := []struct {
+		name string
+		prev []string
+		cur  []string
+		want []string
+	}{
+		{name: "first capture", cur: []string{"a", "b"}, want: []string{"a", "b"}},
+		{name: "appended", prev: []string{"a"}, cur: []string{"a", "b"}, want: []string{"b"}},
+		{name: "scrolled", prev: []string{"a", "b", "c"}, cur: []string{"c", "d"}, want: []string{"d"}},
+		{name: "unchanged", prev: []string{"a", "b"}, cur: []string{"a", "b"}, want: []string{}},
+		{name: "cleared", prev: []string{"a", "b"}, cur: []string{"x"}, want: []string{"x"}},
+		{name: "above footer", prev: []string{"a", "b", "f1", "f2"}, cur: []string{"a", "b", "c", "f1", "f2"}, want: []string{"c"}},
+		{name: "scrolled above footer", prev: []string{"a", "b", "f1", "f2"}, cur: []string{"b", "c", "f1", "f2"}, want: []string{"c"}},
+		{name: "status line changed", prev: []string{"a", "b", "1s", "f"}, cur: []string{"b", "c", "2s", "f"}, want: []string{"c", "2s"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newLines(tt.prev, tt.cur)
+			if len(got) != len(tt.want) || (len(got) > 0 && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("newLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
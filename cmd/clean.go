@@ -1,5 +1,3 @@
-//go:build darwin
-
 package cmd
 
 import (
@@ -9,22 +7,37 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/riricardoMa/claude-grid/internal/git"
+	"github.com/riricardoMa/claude-grid/internal/hooks"
 	"github.com/riricardoMa/claude-grid/internal/session"
 )
 
+// cleanResult is the machine-readable outcome of clean.
+type cleanResult struct {
+	Session          string   `json:"session" yaml:"session"`
+	WorktreesRemoved []string `json:"worktrees_removed" yaml:"worktrees_removed"`
+	WorktreesTotal   int      `json:"worktrees_total" yaml:"worktrees_total"`
+	Warnings         []string `json:"warnings" yaml:"warnings"`
+	Errors           []string `json:"errors" yaml:"errors"`
+}
+
 func NewCleanCmd(storePath string) *cobra.Command {
-	return &cobra.Command{
+	var output outputOptions
+
+	cmd := &cobra.Command{
 		Use:   "clean <session-name>",
 		Short: "Clean a session by removing worktrees",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			sessionName := args[0]
 
+			if err := output.validate(); err != nil {
+				return err
+			}
+
 			store := session.NewStore(storePath)
-			sess, err := store.LoadSession(sessionName)
+			sess, err := loadSession(store, sessionName, cmd.ErrOrStderr())
 			if err != nil {
-				fmt.Fprintf(cmd.ErrOrStderr(), "Session '%s' not found. Run 'claude-grid list' to see active sessions.\n", sessionName)
-				return fmt.Errorf("session '%s' not found", sessionName)
+				return err
 			}
 
 			if len(sess.Worktrees) == 0 {
@@ -36,47 +49,76 @@ func NewCleanCmd(storePath string) *cobra.Command {
 				return fmt.Errorf("failed to create git manager for %q: %w", sess.RepoPath, err)
 			}
 
-			var errs []error
-			var warnings []string
-			removed := 0
+			result := cleanResult{
+				Session:          sessionName,
+				WorktreesRemoved: []string{},
+				WorktreesTotal:   len(sess.Worktrees),
+				Warnings:         []string{},
+				Errors:           []string{},
+			}
 
 			for _, wt := range sess.Worktrees {
+				if sess.Hooks {
+					if err := hooks.Uninstall(wt.Path); err != nil {
+						result.Warnings = append(result.Warnings, fmt.Sprintf("failed to remove hooks from %q: %v", wt.Path, err))
+					}
+				}
+
 				checkCmd := exec.Command("git", "-C", wt.Path, "status", "--porcelain")
-				output, checkErr := checkCmd.CombinedOutput()
-				if checkErr == nil && strings.TrimSpace(string(output)) != "" {
-					warnings = append(warnings, fmt.Sprintf("worktree %q (%s) has uncommitted changes", wt.Path, wt.Branch))
+				status, checkErr := checkCmd.CombinedOutput()
+				if checkErr == nil && strings.TrimSpace(string(status)) != "" {
+					result.Warnings = append(result.Warnings, fmt.Sprintf("worktree %q (%s) has uncommitted changes", wt.Path, wt.Branch))
 				}
 
 				if err := manager.RemoveWorktree(wt.Path); err != nil {
-					errs = append(errs, fmt.Errorf("failed to remove worktree %q: %w", wt.Path, err))
+					result.Errors = append(result.Errors, fmt.Sprintf("failed to remove worktree %q: %v", wt.Path, err))
 				} else {
-					removed++
+					result.WorktreesRemoved = append(result.WorktreesRemoved, wt.Path)
 				}
 			}
 
 			if pruneErr := manager.Prune(); pruneErr != nil {
-				errs = append(errs, fmt.Errorf("failed to prune: %w", pruneErr))
+				result.Errors = append(result.Errors, fmt.Sprintf("failed to prune: %v", pruneErr))
 			}
 
 			if err := store.DeleteSession(sessionName); err != nil {
-				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: failed to delete session file: %v\n", err)
+				result.Warnings = append(result.Warnings, fmt.Sprintf("failed to delete session file: %v", err))
 			}
-
-			for _, w := range warnings {
-				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", w)
+			if err := store.DeleteOutputLogs(sessionName); err != nil {
+				result.Warnings = append(result.Warnings, err.Error())
+			}
+			if err := hooks.NewLog(storePath).Remove(sessionName); err != nil {
+				result.Warnings = append(result.Warnings, err.Error())
 			}
 
-			fmt.Fprintf(cmd.OutOrStdout(), "Session '%s' cleaned. %d/%d worktrees removed.\n", sessionName, removed, len(sess.Worktrees))
+			for _, e := range result.Errors {
+				logEvent(store, sessionName, session.LogEntry{Event: session.LogError, Message: e}, cmd.ErrOrStderr())
+			}
+			logEvent(store, sessionName, session.LogEntry{Event: session.LogClean, Message: fmt.Sprintf("%d/%d worktrees removed", len(result.WorktreesRemoved), result.WorktreesTotal)}, cmd.ErrOrStderr())
 
-			if len(errs) > 0 {
-				msgs := make([]string, len(errs))
-				for i, e := range errs {
-					msgs[i] = e.Error()
+			if output.structured() {
+				if err := output.printResult(cmd.OutOrStdout(), result); err != nil {
+					return err
+				}
+			} else {
+				for _, w := range result.Warnings {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", w)
+				}
+				for _, e := range result.Errors {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Error: %s\n", e)
 				}
-				return fmt.Errorf("clean completed with errors: %s", strings.Join(msgs, "; "))
+				fmt.Fprintf(cmd.OutOrStdout(), "Session '%s' cleaned. %d/%d worktrees removed.\n", sessionName, len(result.WorktreesRemoved), result.WorktreesTotal)
+			}
+
+			if len(result.Errors) > 0 {
+				return fmt.Errorf("clean completed with errors: %s", strings.Join(result.Errors, "; "))
 			}
 
 			return nil
 		},
 	}
+
+	output.addFlags(cmd)
+
+	return cmd
 }
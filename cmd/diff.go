@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/riricardoMa/claude-grid/internal/git"
+	"github.com/riricardoMa/claude-grid/internal/session"
+	"github.com/spf13/cobra"
+)
+
+// worktreeDiff is the work in one window's worktree. Window is numbered
+// from 1.
+type worktreeDiff struct {
+	Window       int          `json:"window" yaml:"window"`
+	Branch       string       `json:"branch" yaml:"branch"`
+	Path         string       `json:"path" yaml:"path"`
+	Base         string       `json:"base" yaml:"base"`
+	MergeBase    string       `json:"merge_base,omitempty" yaml:"merge_base,omitempty"`
+	Ahead        int          `json:"ahead" yaml:"ahead"`
+	Commits      []diffCommit `json:"commits" yaml:"commits"`
+	FilesChanged int          `json:"files_changed" yaml:"files_changed"`
+	Insertions   int          `json:"insertions" yaml:"insertions"`
+	Deletions    int          `json:"deletions" yaml:"deletions"`
+	Files        []diffFile   `json:"files" yaml:"files"`
+	Uncommitted  []diffFile   `json:"uncommitted" yaml:"uncommitted"`
+	// Patch and UncommittedPatch are only filled in with --patch.
+	Patch            string `json:"patch,omitempty" yaml:"patch,omitempty"`
+	UncommittedPatch string `json:"uncommitted_patch,omitempty" yaml:"uncommitted_patch,omitempty"`
+	Error            string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// diffCommit is one commit of a worktree's branch.
+type diffCommit struct {
+	Hash    string `json:"hash" yaml:"hash"`
+	Subject string `json:"subject" yaml:"subject"`
+}
+
+// diffFile is a file changed by a branch's commits, with its line counts, or
+// an uncommitted file, with its git status code such as M or ??.
+type diffFile struct {
+	Path       string `json:"path" yaml:"path"`
+	Status     string `json:"status,omitempty" yaml:"status,omitempty"`
+	Insertions int    `json:"insertions,omitempty" yaml:"insertions,omitempty"`
+	Deletions  int    `json:"deletions,omitempty" yaml:"deletions,omitempty"`
+	Binary     bool   `json:"binary,omitempty" yaml:"binary,omitempty"`
+}
+
+func NewDiffCmd(storePath string) *cobra.Command {
+	var output outputOptions
+	var stat bool
+	var patch bool
+
+	cmd := &cobra.Command{
+		Use:   "diff <session-name> [window...]",
+		Short: "Summarize the changes in a session's worktrees",
+		Long: `Show what the agent in each of a session's worktrees has done: the commits its
+branch is ahead of the base it was created from, the files they change with
+their insertions and deletions, and the worktree's uncommitted changes.
+Branches continued with --branch, and sessions from before bases were
+recorded, are compared with the branch checked out in the session's
+repository.
+
+Without flags, one row is printed per window. --stat lists each window's
+commits and changed files, and --patch prints its committed and uncommitted
+patches.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionName := args[0]
+
+			if err := output.validate(); err != nil {
+				return err
+			}
+
+			store := session.NewStore(storePath)
+			sess, err := loadSession(store, sessionName, cmd.ErrOrStderr())
+			if err != nil {
+				return err
+			}
+			if len(sess.Worktrees) == 0 {
+				return fmt.Errorf("session '%s' has no worktrees to diff", sessionName)
+			}
+
+			windows, err := worktreeWindows(sess, args[1:])
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				return err
+			}
+
+			diffs := make([]worktreeDiff, 0, len(windows))
+			failed := 0
+			for _, n := range windows {
+				d := diffWorktree(sess, n, patch)
+				if d.Error != "" {
+					failed++
+				}
+				diffs = append(diffs, d)
+			}
+
+			if output.structured() {
+				if err := printResults(&output, cmd.OutOrStdout(), diffs); err != nil {
+					return err
+				}
+			} else {
+				for _, d := range diffs {
+					if d.Error != "" {
+						fmt.Fprintf(cmd.ErrOrStderr(), "Warning: window %d: %s\n", d.Window, d.Error)
+					}
+				}
+				if stat || patch {
+					printDiffDetails(cmd.OutOrStdout(), diffs, stat, patch)
+				} else {
+					printDiffTable(cmd.OutOrStdout(), diffs)
+				}
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("failed to diff %d windows", failed)
+			}
+			return nil
+		},
+	}
+
+	output.addFlags(cmd)
+	cmd.Flags().BoolVar(&stat, "stat", false, "List each window's commits and changed files")
+	cmd.Flags().BoolVar(&patch, "patch", false, "Print each window's committed and uncommitted patches")
+
+	return cmd
+}
+
+// diffWorktree summarizes the worktree of window n, including its patches if
+// withPatch is set. Failures are reported in the result's Error.
+func diffWorktree(sess session.Session, n int, withPatch bool) worktreeDiff {
+	wt := sess.Worktrees[n-1]
+	d := worktreeDiff{
+		Window:      n,
+		Branch:      wt.Branch,
+		Path:        wt.Path,
+		Commits:     []diffCommit{},
+		Files:       []diffFile{},
+		Uncommitted: []diffFile{},
+	}
+
+	base, baseCommit := wt.Base, wt.BaseCommit
+	if baseCommit == "" {
+		base, baseCommit = repoBase(sess.RepoPath)
+	}
+	d.Base = base
+	if baseCommit == "" {
+		d.Error = fmt.Sprintf("cannot determine the base of branch %s", wt.Branch)
+		return d
+	}
+
+	summary, err := git.Diff(wt.Path, baseCommit)
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+	d.MergeBase = summary.MergeBase
+	d.Ahead = len(summary.Commits)
+	for _, c := range summary.Commits {
+		d.Commits = append(d.Commits, diffCommit{Hash: c.Hash, Subject: c.Subject})
+	}
+	d.FilesChanged = len(summary.Files)
+	d.Insertions, d.Deletions = summary.Insertions, summary.Deletions
+	for _, f := range summary.Files {
+		d.Files = append(d.Files, diffFile{Path: f.Path, Insertions: f.Insertions, Deletions: f.Deletions, Binary: f.Binary})
+	}
+	for _, f := range summary.Uncommitted {
+		d.Uncommitted = append(d.Uncommitted, diffFile{Path: f.Path, Status: f.Status})
+	}
+
+	if withPatch {
+		if d.Patch, err = git.Patch(wt.Path, summary.MergeBase, "HEAD"); err != nil {
+			d.Error = err.Error()
+			return d
+		}
+		if d.UncommittedPatch, err = git.Patch(wt.Path, "HEAD", ""); err != nil {
+			d.Error = err.Error()
+		}
+	}
+	return d
+}
+
+// repoBase returns the branch checked out in repoPath, or "HEAD" when it is
+// detached, and the commit it points to. The commit is empty when repoPath
+// cannot be read.
+func repoBase(repoPath string) (string, string) {
+	manager, err := git.NewManager(repoPath)
+	if err != nil {
+		return "HEAD", ""
+	}
+	commit, err := manager.ResolveRef("HEAD")
+	if err != nil {
+		return "HEAD", ""
+	}
+	if branch, err := manager.CurrentBranch(); err == nil {
+		return branch, commit
+	}
+	return "HEAD", commit
+}
+
+// printDiffTable writes one row per window.
+func printDiffTable(w io.Writer, diffs []worktreeDiff) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "WINDOW\tBRANCH\tBASE\tAHEAD\tFILES\tLINES\tUNCOMMITTED")
+	for _, d := range diffs {
+		if d.Error != "" {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t-\t-\t-\t-\n", d.Window, d.Branch, d.Base)
+			continue
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%d\t+%d -%d\t%d\n", d.Window, d.Branch, d.Base, d.Ahead, d.FilesChanged, d.Insertions, d.Deletions, len(d.Uncommitted))
+	}
+	tw.Flush()
+}
+
+// printDiffDetails writes a section per window with its commits and changed
+// files if stat is set, and its patches if patch is set.
+func printDiffDetails(w io.Writer, diffs []worktreeDiff, stat, patch bool) {
+	first := true
+	for _, d := range diffs {
+		if d.Error != "" {
+			continue
+		}
+		if !first {
+			fmt.Fprintln(w)
+		}
+		first = false
+
+		fmt.Fprintf(w, "==> window %d: %s <==\n", d.Window, d.Branch)
+		fmt.Fprintf(w, "%d %s ahead of %s (%s)\n", d.Ahead, plural(d.Ahead, "commit"), d.Base, shortCommit(d.MergeBase))
+
+		if stat {
+			for _, c := range d.Commits {
+				fmt.Fprintf(w, "  %s %s\n", c.Hash, c.Subject)
+			}
+			if len(d.Files) > 0 {
+				fmt.Fprintln(w)
+				tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+				for _, f := range d.Files {
+					if f.Binary {
+						fmt.Fprintf(tw, " %s\t| binary\n", f.Path)
+					} else {
+						fmt.Fprintf(tw, " %s\t| +%d -%d\n", f.Path, f.Insertions, f.Deletions)
+					}
+				}
+				tw.Flush()
+				fmt.Fprintf(w, " %d %s changed, %d %s(+), %d %s(-)\n", d.FilesChanged, plural(d.FilesChanged, "file"), d.Insertions, plural(d.Insertions, "insertion"), d.Deletions, plural(d.Deletions, "deletion"))
+			}
+			if len(d.Uncommitted) > 0 {
+				fmt.Fprintln(w, "\nUncommitted:")
+				for _, f := range d.Uncommitted {
+					fmt.Fprintf(w, "  %-2s %s\n", f.Status, f.Path)
+				}
+			}
+		} else if len(d.Uncommitted) > 0 {
+			fmt.Fprintf(w, "%d uncommitted %s\n", len(d.Uncommitted), plural(len(d.Uncommitted), "file"))
+		}
+
+		if patch {
+			if d.Patch != "" {
+				fmt.Fprintln(w)
+				fmt.Fprint(w, d.Patch)
+			}
+			if d.UncommittedPatch != "" {
+				fmt.Fprintln(w, "\n# Uncommitted changes")
+				fmt.Fprint(w, d.UncommittedPatch)
+			}
+		}
+	}
+}
+
+// plural returns noun, with an s appended unless n is 1.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return noun
+	}
+	return noun + "s"
+}
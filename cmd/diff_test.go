@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/riricardoMa/claude-grid/internal/session"
+)
+
+func runDiff(t *testing.T, storeDir string, args ...string) (string, string, error) {
+	t.Helper()
+	cmd := NewDiffCmd(storeDir)
+	cmd.SilenceUsage = true
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+func TestDiffTable(t *testing.T) {
+	storeDir := t.TempDir()
+	saveMergeSession(t, storeDir, map[string]string{"a.txt": "", "b.txt": ""}, "a.txt", "b.txt")
+
+	sess, err := session.NewStore(storeDir).LoadSession("sprint")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sess.Worktrees[1].Path, "wip.txt"), []byte("wip\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	stdout, stderr, err := runDiff(t, storeDir, "sprint")
+	if err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, stderr)
+	}
+	for _, want := range []string{
+		"WINDOW  BRANCH    BASE  AHEAD  FILES  LINES  UNCOMMITTED",
+		"1       sprint-1  main  1      1      +1 -0  0",
+		"2       sprint-2  main  1      1      +1 -0  1",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("stdout = %q, want line %q", stdout, want)
+		}
+	}
+}
+
+func TestDiffStatAndPatch(t *testing.T) {
+	storeDir := t.TempDir()
+	saveMergeSession(t, storeDir, map[string]string{"a.txt": "alpha "}, "a.txt")
+
+	stdout, stderr, err := runDiff(t, storeDir, "sprint", "1", "--stat", "--patch")
+	if err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, stderr)
+	}
+	for _, want := range []string{
+		"==> window 1: sprint-1 <==\n1 commit ahead of main",
+		"add a.txt",
+		" a.txt | +1 -0\n 1 file changed, 1 insertion(+), 0 deletions(-)",
+		"+alpha sprint-1",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("stdout = %q, want %q", stdout, want)
+		}
+	}
+}
+
+func TestDiffOutputJSON(t *testing.T) {
+	storeDir := t.TempDir()
+	repo := saveMergeSession(t, storeDir, map[string]string{"a.txt": ""}, "a.txt")
+
+	// A recorded base is used instead of the repository's branch.
+	store := session.NewStore(storeDir)
+	sess, err := store.LoadSession("sprint")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	sess.Worktrees[0].Base = "release"
+	sess.Worktrees[0].BaseCommit = gitOutput(t, repo, "rev-parse", "main")
+	sess.Worktrees = append(sess.Worktrees, session.WorktreeRef{Path: filepath.Join(t.TempDir(), "gone"), Branch: "sprint-2"})
+	if err := store.SaveSession(sess); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	stdout, _, err := runDiff(t, storeDir, "sprint", "-o", "json")
+	if err == nil || !strings.Contains(err.Error(), "failed to diff 1 windows") {
+		t.Errorf("Execute() error = %v, want one failed window", err)
+	}
+
+	var got []worktreeDiff
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, stdout)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d results, want 2", len(got))
+	}
+	if d := got[0]; d.Base != "release" || d.Ahead != 1 || d.Commits[0].Subject != "add a.txt" || d.Files[0].Path != "a.txt" || d.Patch != "" {
+		t.Errorf("window 1 = %+v", d)
+	}
+	if d := got[1]; d.Error == "" || d.Commits == nil {
+		t.Errorf("window 2 = %+v, want an error with empty lists", d)
+	}
+	if !strings.Contains(stdout, `"files_changed": 1`) {
+		t.Errorf("stdout = %s, want snake_case keys", stdout)
+	}
+}
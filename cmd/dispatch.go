@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/riricardoMa/claude-grid/internal/agentstate"
+	"github.com/riricardoMa/claude-grid/internal/hooks"
+	"github.com/riricardoMa/claude-grid/internal/script"
+	"github.com/riricardoMa/claude-grid/internal/session"
+	"github.com/riricardoMa/claude-grid/internal/terminal"
+	"github.com/spf13/cobra"
+)
+
+// taskStartGrace is how long an agent may sit idle after a task was sent to it
+// before the task counts as finished without the agent having been seen
+// working. It covers agents that finished while no dispatcher was running.
+const taskStartGrace = 30 * time.Second
+
+func NewDispatchCmd(storePath string, executor script.ScriptExecutor) *cobra.Command {
+	var interval time.Duration
+	var once bool
+	var keepContext bool
+
+	cmd := &cobra.Command{
+		Use:   "dispatch <session-name>",
+		Short: "Hand out a session's queued tasks as its windows finish",
+		Long: `Run the task queue of a session spawned with --queue. Whenever the agent in a
+window finishes its task and goes idle, the task is marked done and the next
+pending task is sent to that window. Tasks whose window closes are marked
+failed. Runs until every task has finished, or Ctrl-C.
+
+Agent states come from the hooks installed with --hooks, or else from the text
+the windows show, so sessions on the warp backend need --hooks. Claude Code
+windows get /clear before each new task unless --keep-context is given.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionName := args[0]
+			stderr := cmd.ErrOrStderr()
+
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+
+			store := session.NewStore(storePath)
+			sess, err := loadSession(store, sessionName, stderr)
+			if err != nil {
+				return err
+			}
+			if len(sess.Tasks) == 0 {
+				fmt.Fprintf(stderr, "Session '%s' has no task queue. Spawn it with --queue.\n", sessionName)
+				return fmt.Errorf("session '%s' has no task queue", sessionName)
+			}
+			if _, err := outputCapturerForSession(sess, executor); err != nil && !sess.Hooks {
+				fmt.Fprintf(stderr, "Error: %v; spawn the session with --hooks to dispatch tasks\n", err)
+				return err
+			}
+
+			d := &taskDispatcher{
+				store:       store,
+				events:      hooks.NewLog(storePath),
+				executor:    executor,
+				name:        sessionName,
+				keepContext: keepContext,
+				engaged:     make(map[int]bool),
+				stdout:      cmd.OutOrStdout(),
+				stderr:      stderr,
+			}
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				counts, err := d.dispatch(cmd.Context(), time.Now())
+				if cmd.Context().Err() != nil {
+					return nil
+				}
+				if err != nil {
+					fmt.Fprintf(stderr, "Error: %v\n", err)
+					return err
+				}
+
+				if counts[session.TaskPending]+counts[session.TaskRunning] == 0 {
+					fmt.Fprintf(d.stdout, "All %d tasks of session '%s' finished: %d done, %d failed.\n", len(sess.Tasks), sessionName, counts[session.TaskDone], counts[session.TaskFailed])
+					return nil
+				}
+				if once {
+					fmt.Fprintf(d.stdout, "Session '%s': %d pending, %d running, %d done, %d failed.\n", sessionName, counts[session.TaskPending], counts[session.TaskRunning], counts[session.TaskDone], counts[session.TaskFailed])
+					return nil
+				}
+
+				select {
+				case <-cmd.Context().Done():
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+
+	cmd.Flags().DurationVar(&interval, "interval", 5*time.Second, "How often windows are checked")
+	cmd.Flags().BoolVar(&once, "once", false, "Check the windows and dispatch once, then exit")
+	cmd.Flags().BoolVar(&keepContext, "keep-context", false, "Do not send /clear to Claude Code windows before a new task")
+
+	return cmd
+}
+
+// taskDispatcher hands out the queued tasks of one session.
+type taskDispatcher struct {
+	store       *session.Store
+	events      *hooks.Log
+	executor    script.ScriptExecutor
+	name        string
+	keepContext bool
+	// engaged records, by window number, the windows whose agent was seen
+	// working or waiting since their task was sent.
+	engaged map[int]bool
+	stdout  io.Writer
+	stderr  io.Writer
+}
+
+// dispatch checks every window of the session once: running tasks of idle
+// agents are marked done, those of closed windows failed, and free idle
+// windows get the next pending task. It returns how many tasks are in each
+// state afterwards.
+func (d *taskDispatcher) dispatch(ctx context.Context, now time.Time) (map[string]int, error) {
+	sess, err := d.store.LoadSession(d.name)
+	if err != nil {
+		return nil, fmt.Errorf("session '%s' is no longer available: %w", d.name, err)
+	}
+	if sess.Status == "stopped" {
+		return nil, fmt.Errorf("session '%s' is stopped; run 'claude-grid resume %s' and dispatch again", d.name, d.name)
+	}
+
+	// Backends that cannot read window text rely on hook events alone.
+	capturer, _ := outputCapturerForSession(sess, d.executor)
+	sender, err := inputSenderForSession(sess, d.executor)
+	if err != nil {
+		return nil, err
+	}
+	var latest map[int]hooks.Event
+	if sess.Hooks {
+		latest, _ = d.events.Latest(sess.Name)
+	}
+	live, known := liveWindowIDs(ctx, d.executor, sess)
+
+	windows := append([]session.WindowRef(nil), sess.Windows...)
+	sort.SliceStable(windows, func(i, j int) bool { return windows[i].Index < windows[j].Index })
+
+	updates := make(map[int]session.Task)
+	for _, win := range windows {
+		number := win.Index + 1
+		state := windowAgentState(ctx, capturer, latest, win, !known || live[win.ID])
+
+		if running := sess.RunningTask(number); running >= 0 {
+			task := sess.Tasks[running]
+			switch state {
+			case agentstate.Exited:
+				task.State = session.TaskFailed
+				task.Error = "window closed"
+				task.FinishedAt = now
+				updates[running] = task
+				d.report(number, fmt.Sprintf("task %d failed in window %d: window closed", running+1, number))
+				continue
+			case agentstate.Working, agentstate.Waiting:
+				d.engaged[number] = true
+				continue
+			case agentstate.Idle:
+				event, hasEvent := latest[number]
+				if !d.finished(task, number, event, hasEvent, now) {
+					continue
+				}
+				task.State = session.TaskDone
+				task.FinishedAt = now
+				updates[running] = task
+				d.report(number, fmt.Sprintf("task %d done in window %d", running+1, number))
+			default:
+				continue
+			}
+		}
+
+		if state != agentstate.Idle {
+			continue
+		}
+		next := sess.NextPendingTask()
+		if next < 0 {
+			continue
+		}
+		task := sess.Tasks[next]
+		if err := d.send(ctx, sender, sess, win, task.Prompt); err != nil {
+			fmt.Fprintf(d.stderr, "Warning: window %d: %v\n", number, err)
+			continue
+		}
+		task.State = session.TaskRunning
+		task.Window = number
+		task.StartedAt = now
+		// Marked running here too, so the next free window gets the task after.
+		sess.Tasks[next] = task
+		updates[next] = task
+		d.engaged[number] = false
+		d.report(number, fmt.Sprintf("task %d started in window %d: %s", next+1, number, truncate(task.Prompt, 60)))
+	}
+
+	var counts map[string]int
+	err = d.store.ModifySession(d.name, func(s *session.Session) error {
+		for i, t := range updates {
+			if i < len(s.Tasks) {
+				s.Tasks[i] = t
+			}
+		}
+		counts = s.TaskCounts()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("save task states: %w", err)
+	}
+	return counts, nil
+}
+
+// finished reports whether the idle agent of window has finished task, rather
+// than not having started on it yet: it was seen working since the task was
+// sent, its hooks reported stopping after the task started, or, without
+// hooks, it has been idle longer than taskStartGrace.
+func (d *taskDispatcher) finished(task session.Task, window int, event hooks.Event, hasEvent bool, now time.Time) bool {
+	if d.engaged[window] {
+		return true
+	}
+	if hasEvent {
+		return event.Time.After(task.StartedAt)
+	}
+	return now.Sub(task.StartedAt) >= taskStartGrace
+}
+
+// send types prompt into win, clearing the conversation of Claude Code
+// windows first so each task starts with a fresh context.
+func (d *taskDispatcher) send(ctx context.Context, sender terminal.InputSender, sess session.Session, win session.WindowRef, prompt string) error {
+	if !d.keepContext && sessionAgent(sess).Name == "claude" {
+		if err := sender.SendInput(ctx, win, "/clear"); err != nil {
+			return err
+		}
+	}
+	return sender.SendInput(ctx, win, prompt)
+}
+
+// report prints a task transition and records it in the session log.
+func (d *taskDispatcher) report(window int, message string) {
+	fmt.Fprintf(d.stdout, "%s  %s\n", time.Now().Format("15:04:05"), message)
+	logEvent(d.store, d.name, session.LogEntry{Event: session.LogTask, Window: window, Message: message}, d.stderr)
+}
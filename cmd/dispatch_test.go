@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/riricardoMa/claude-grid/internal/hooks"
+	"github.com/riricardoMa/claude-grid/internal/session"
+)
+
+func runDispatch(t *testing.T, storeDir string, executor *scriptedExecutor, args ...string) (string, string, error) {
+	t.Helper()
+	cmd := NewDispatchCmd(storeDir, executor)
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+func saveQueueSession(t *testing.T, storeDir string, hooks bool, tasks []session.Task) *session.Store {
+	t.Helper()
+	store := session.NewStore(storeDir)
+	if err := store.SaveSession(session.Session{
+		Name:    "queue",
+		Backend: "terminal",
+		Count:   2,
+		Status:  "active",
+		Hooks:   hooks,
+		Windows: []session.WindowRef{{ID: "42", Index: 0}, {ID: "43", Index: 1}},
+		Tasks:   tasks,
+	}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+	return store
+}
+
+// agentScreens answers capture scripts with an idle prompt for window 42 and
+// a busy agent for window 43.
+func agentScreens(script string) string {
+	switch {
+	case strings.Contains(script, "contents of selected tab of window id 42"):
+		return "> \n"
+	case strings.Contains(script, "contents of selected tab of window id 43"):
+		return "✻ Thinking… (esc to interrupt)\n"
+	}
+	return ""
+}
+
+func TestDispatchSendsNextTaskToFinishedWindow(t *testing.T) {
+	storeDir := t.TempDir()
+	started := time.Now().Add(-time.Hour)
+	store := saveQueueSession(t, storeDir, false, []session.Task{
+		{Prompt: "fix the login bug", State: session.TaskRunning, Window: 1, StartedAt: started},
+		{Prompt: "add rate limiting", State: session.TaskRunning, Window: 2, StartedAt: started},
+		{Prompt: "write API docs", State: session.TaskPending},
+	})
+
+	executor := &scriptedExecutor{windowIDs: "42, 43", capture: agentScreens}
+	stdout, _, err := runDispatch(t, storeDir, executor, "queue", "--once")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var sent []string
+	for _, s := range executor.scripts {
+		if strings.Contains(s, "do script") {
+			sent = append(sent, s)
+		}
+	}
+	if len(sent) != 2 || !strings.Contains(sent[0], `do script "/clear" in window id 42`) || !strings.Contains(sent[1], `do script "write API docs" in window id 42`) {
+		t.Errorf("sent = %q, want /clear then the next task to window 42", sent)
+	}
+
+	loaded, err := store.LoadSession("queue")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if got := loaded.Tasks[0]; got.State != session.TaskDone || got.FinishedAt.IsZero() {
+		t.Errorf("task 1 = %+v, want done", got)
+	}
+	if got := loaded.Tasks[1]; got.State != session.TaskRunning {
+		t.Errorf("task 2 = %+v, want still running", got)
+	}
+	if got := loaded.Tasks[2]; got.State != session.TaskRunning || got.Window != 1 || got.StartedAt.IsZero() {
+		t.Errorf("task 3 = %+v, want running in window 1", got)
+	}
+
+	for _, want := range []string{"task 1 done in window 1", "task 3 started in window 1: write API docs", "0 pending, 2 running, 1 done, 0 failed"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("stdout = %q, want it to contain %q", stdout, want)
+		}
+	}
+
+	entries, err := store.ReadLog("queue")
+	if err != nil {
+		t.Fatalf("ReadLog() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Event != session.LogTask || entries[1].Window != 1 {
+		t.Errorf("log = %+v, want the two task transitions", entries)
+	}
+}
+
+func TestDispatchGivesFreeWindowsDifferentTasks(t *testing.T) {
+	storeDir := t.TempDir()
+	started := time.Now().Add(-time.Hour)
+	store := saveQueueSession(t, storeDir, false, []session.Task{
+		{Prompt: "fix the login bug", State: session.TaskRunning, Window: 1, StartedAt: started},
+		{Prompt: "add rate limiting", State: session.TaskRunning, Window: 2, StartedAt: started},
+		{Prompt: "write API docs", State: session.TaskPending},
+		{Prompt: "bump deps", State: session.TaskPending},
+	})
+
+	idle := func(string) string { return "> \n" }
+	executor := &scriptedExecutor{windowIDs: "42, 43", capture: idle}
+	if _, _, err := runDispatch(t, storeDir, executor, "queue", "--once"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	loaded, err := store.LoadSession("queue")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	for i, window := range []int{1, 2} {
+		if got := loaded.Tasks[2+i]; got.State != session.TaskRunning || got.Window != window {
+			t.Errorf("task %d = %+v, want running in window %d", 3+i, got, window)
+		}
+	}
+}
+
+func TestDispatchFailsTasksOfClosedWindows(t *testing.T) {
+	storeDir := t.TempDir()
+	store := saveQueueSession(t, storeDir, false, []session.Task{
+		{Prompt: "fix the login bug", State: session.TaskRunning, Window: 1, StartedAt: time.Now()},
+		{Prompt: "add rate limiting", State: session.TaskDone, Window: 2},
+	})
+
+	executor := &scriptedExecutor{windowIDs: "43", capture: agentScreens}
+	stdout, _, err := runDispatch(t, storeDir, executor, "queue")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(stdout, "All 2 tasks of session 'queue' finished: 1 done, 1 failed.") {
+		t.Errorf("stdout = %q, want final summary", stdout)
+	}
+
+	loaded, err := store.LoadSession("queue")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if got := loaded.Tasks[0]; got.State != session.TaskFailed || got.Error != "window closed" {
+		t.Errorf("task 1 = %+v, want failed because its window closed", got)
+	}
+}
+
+func TestDispatchWaitsForAgentsToStart(t *testing.T) {
+	storeDir := t.TempDir()
+	now := time.Now()
+	store := saveQueueSession(t, storeDir, true, []session.Task{
+		{Prompt: "fix the login bug", State: session.TaskRunning, Window: 1, StartedAt: now.Add(-time.Minute)},
+		{Prompt: "add rate limiting", State: session.TaskRunning, Window: 2, StartedAt: now},
+		{Prompt: "write API docs", State: session.TaskPending},
+	})
+	events := hooks.NewLog(storeDir)
+	for _, e := range []hooks.Event{
+		// Window 1 stopped before its task was sent; window 2 after.
+		{Time: now.Add(-2 * time.Minute), Window: 1, Hook: "Stop"},
+		{Time: now.Add(time.Second), Window: 2, Hook: "Stop"},
+	} {
+		if err := events.Append("queue", e); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	executor := &scriptedExecutor{windowIDs: "42, 43", capture: agentScreens}
+	if _, _, err := runDispatch(t, storeDir, executor, "queue", "--once", "--keep-context"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	loaded, err := store.LoadSession("queue")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if got := loaded.Tasks[0]; got.State != session.TaskRunning {
+		t.Errorf("task 1 = %+v, want still running", got)
+	}
+	if got := loaded.Tasks[1]; got.State != session.TaskDone {
+		t.Errorf("task 2 = %+v, want done", got)
+	}
+	if got := loaded.Tasks[2]; got.Window != 2 {
+		t.Errorf("task 3 = %+v, want dispatched to window 2", got)
+	}
+	for _, s := range executor.scripts {
+		if strings.Contains(s, "/clear") {
+			t.Errorf("sent /clear despite --keep-context: %q", s)
+		}
+	}
+}
+
+func TestDispatchWithoutQueue(t *testing.T) {
+	storeDir := t.TempDir()
+	saveQueueSession(t, storeDir, false, nil)
+
+	_, stderr, err := runDispatch(t, storeDir, &scriptedExecutor{capture: agentScreens}, "queue")
+	if err == nil {
+		t.Fatal("Execute() error = nil, want error")
+	}
+	if !strings.Contains(stderr, "has no task queue") {
+		t.Errorf("stderr = %q", stderr)
+	}
+}
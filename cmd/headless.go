@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/riricardoMa/claude-grid/internal/headless"
+	"github.com/riricardoMa/claude-grid/internal/session"
+)
+
+// headlessBackend is the backend name of sessions whose agents run as child
+// processes of claude-grid rather than in terminal windows.
+const headlessBackend = "headless"
+
+// headlessTailLines is how many of the last lines of a run log stand in for
+// the visible screen of a window.
+const headlessTailLines = 50
+
+// runHeadless starts the agents of sess, one per directory in dirs, waits for
+// them all and records their exit codes, then runs the session's verification
+// command, if any, in its worktrees. The deferred cleanup of the root
+// command runs unless started is called, which happens once every agent is
+// running. Interrupting claude-grid, or closing its terminal, stops the
+// agents.
+func runHeadless(ctx context.Context, store *session.Store, sess session.Session, dirs []string, started func(), stdout, stderr io.Writer) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer stop()
+
+	instances := make([]headless.Instance, len(dirs))
+	for i, dir := range dirs {
+		var prompt string
+		if i < len(sess.Prompts) {
+			prompt = sess.Prompts[i]
+		}
+		instances[i] = headless.Instance{
+			Dir:         dir,
+			CommandLine: sess.Agent.CommandLine(prompt, ""),
+			LogPath:     store.RunLogPath(sess.Name, i+1),
+		}
+	}
+
+	processes, err := headless.Start(ctx, instances)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to start agents: %v\n", err)
+		logEvent(store, sess.Name, session.LogEntry{Event: session.LogError, Message: fmt.Sprintf("start agents: %v", err)}, stderr)
+		return fmt.Errorf("start agents: %w", err)
+	}
+
+	for i, p := range processes {
+		sess.Windows = append(sess.Windows, session.WindowRef{ID: strconv.Itoa(p.PID), Index: i, LogPath: p.LogPath, ProcessStart: p.Started})
+	}
+	if err := store.SaveSession(sess); err != nil {
+		for _, p := range processes {
+			_ = headless.Stop(p.PID)
+			_, _ = p.Wait()
+		}
+		fmt.Fprintf(stderr, "failed to save session: %v\n", err)
+		logEvent(store, sess.Name, session.LogEntry{Event: session.LogError, Message: fmt.Sprintf("save session: %v", err)}, stderr)
+		return fmt.Errorf("save session: %w", err)
+	}
+	started()
+
+	logEvent(store, sess.Name, session.LogEntry{Event: session.LogSpawn, Message: fmt.Sprintf("%d %s agents headless", len(processes), sess.Agent.Name)}, stderr)
+	for i, p := range processes {
+		logEvent(store, sess.Name, session.LogEntry{Event: session.LogWindowStart, Window: i + 1, Message: fmt.Sprintf("process %d in %s, output in %s", p.PID, dirs[i], p.LogPath)}, stderr)
+	}
+
+	fmt.Fprintf(stdout, "Session %q running headless. Follow with `claude-grid capture %s --follow`; Ctrl-C stops all agents.\n", sess.Name, sess.Name)
+
+	err = waitHeadless(store, sess.Name, processes, stdout, stderr)
+	if sess.Verify == "" || len(sess.Worktrees) == 0 || ctx.Err() != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "Verifying %d worktrees with %q...\n", len(sess.Worktrees), sess.Verify)
+	verifications, verifyErr := verifyWorktrees(ctx, store, sess, sess.Verify, 0, stdout)
+	if verifyErr == nil {
+		fmt.Fprintln(stdout)
+		printVerifications(stdout, sess, verifications)
+		verifyErr = verificationError(verifications)
+	}
+	if err == nil {
+		err = verifyErr
+	}
+	return err
+}
+
+// waitHeadless waits for every process, recording each exit code in the
+// session as it comes in, then marks the session stopped. It fails if any
+// agent did not exit with code 0, so batch runs can be checked by exit status.
+func waitHeadless(store *session.Store, name string, processes []*headless.Process, stdout, stderr io.Writer) error {
+	type exit struct {
+		window int
+		code   int
+		err    error
+	}
+	exits := make(chan exit)
+	for i, p := range processes {
+		go func() {
+			code, err := p.Wait()
+			exits <- exit{window: i + 1, code: code, err: err}
+		}()
+	}
+
+	failed := 0
+	for range processes {
+		e := <-exits
+		message := fmt.Sprintf("exited with code %d", e.code)
+		switch {
+		case e.err != nil:
+			message = fmt.Sprintf("could not be waited for: %v", e.err)
+		case e.code < 0:
+			message = "killed by a signal"
+		}
+		if e.code != 0 {
+			failed++
+		}
+
+		err := store.ModifySession(name, func(s *session.Session) error {
+			for i := range s.Windows {
+				if s.Windows[i].Index == e.window-1 {
+					s.Windows[i].ExitCode = &e.code
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(stderr, "warning: failed to record exit code of window %d: %v\n", e.window, err)
+		}
+		logEvent(store, name, session.LogEntry{Event: session.LogExit, Window: e.window, Message: message}, stderr)
+		fmt.Fprintf(stdout, "%s  window %d %s (%s)\n", time.Now().Format("15:04:05"), e.window, message, displayPath(processes[e.window-1].LogPath))
+	}
+
+	err := store.ModifySession(name, func(s *session.Session) error {
+		s.Status = "stopped"
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "warning: failed to update session: %v\n", err)
+	}
+
+	fmt.Fprintf(stdout, "Session %q finished: %d/%d agents succeeded.\n", name, len(processes)-failed, len(processes))
+	if failed > 0 {
+		return fmt.Errorf("%d of %d agents failed", failed, len(processes))
+	}
+	return nil
+}
+
+// stopHeadless stops the agents of a headless session that are still running.
+func stopHeadless(sess session.Session) error {
+	var errs []string
+	for _, w := range liveHeadlessProcesses(sess) {
+		pid, _ := strconv.Atoi(w)
+		if err := headless.Stop(pid); err != nil {
+			errs = append(errs, fmt.Sprintf("process %d: %v", pid, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to stop %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// liveHeadlessProcesses returns the window IDs, which are process IDs, of the
+// agents of a headless session that have not exited. A process ID now used by
+// a process started at another time is not the agent's and is left out.
+func liveHeadlessProcesses(sess session.Session) []string {
+	var live []string
+	for _, w := range sess.Windows {
+		if w.ExitCode != nil {
+			continue
+		}
+		if pid, err := strconv.Atoi(w.ID); err == nil && headless.Running(pid, w.ProcessStart) {
+			live = append(live, w.ID)
+		}
+	}
+	return live
+}
+
+// runLogCapturer reads the output of headless agents back from their run logs.
+type runLogCapturer struct{}
+
+// CaptureOutput returns the run log of window, or only its last lines unless
+// scrollback is set.
+func (runLogCapturer) CaptureOutput(ctx context.Context, window session.WindowRef, scrollback bool) (string, error) {
+	if window.LogPath == "" {
+		return "", fmt.Errorf("window %d has no run log", window.Index+1)
+	}
+	data, err := os.ReadFile(window.LogPath)
+	if err != nil {
+		return "", err
+	}
+	text := string(data)
+	if scrollback {
+		return text, nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines) > headlessTailLines {
+		lines = lines[len(lines)-headlessTailLines:]
+	}
+	return strings.Join(lines, ""), nil
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/riricardoMa/claude-grid/internal/agent"
+	"github.com/riricardoMa/claude-grid/internal/headless"
+	"github.com/riricardoMa/claude-grid/internal/session"
+)
+
+func TestRunHeadless(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	storeDir := t.TempDir()
+	store := session.NewStore(storeDir)
+	dir := t.TempDir()
+
+	sess := session.Session{
+		Name:    "batch",
+		Backend: headlessBackend,
+		Count:   2,
+		Dir:     dir,
+		Prompts: []string{"echo hello", "echo broken >&2; exit 3"},
+		Status:  "active",
+		Agent:   agent.Profile{Name: agent.CustomName, Command: "sh", Args: []string{"-c", "{prompt}"}, PromptMode: agent.PromptPositional},
+	}
+
+	started := false
+	var stdout, stderr bytes.Buffer
+	err := runHeadless(context.Background(), store, sess, []string{dir, dir}, func() { started = true }, &stdout, &stderr)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 agents failed") {
+		t.Errorf("runHeadless() error = %v, want 1 of 2 agents failed", err)
+	}
+	if !started {
+		t.Error("started was not called")
+	}
+	if !strings.Contains(stdout.String(), "1/2 agents succeeded") {
+		t.Errorf("stdout = %q", stdout.String())
+	}
+
+	loaded, err := store.LoadSession("batch")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if loaded.Status != "stopped" || len(loaded.Windows) != 2 {
+		t.Fatalf("session = %+v, want stopped with 2 windows", loaded)
+	}
+	for i, want := range []int{0, 3} {
+		w := loaded.Windows[i]
+		if w.ExitCode == nil || *w.ExitCode != want {
+			t.Errorf("window %d exit code = %v, want %d", i+1, w.ExitCode, want)
+		}
+		if w.LogPath != store.RunLogPath("batch", i+1) {
+			t.Errorf("window %d log path = %q", i+1, w.LogPath)
+		}
+	}
+
+	text, err := runLogCapturer{}.CaptureOutput(context.Background(), loaded.Windows[1], false)
+	if err != nil || !strings.Contains(text, "broken") {
+		t.Errorf("CaptureOutput() = %q, %v; want the agent's stderr", text, err)
+	}
+
+	entries, err := store.ReadLog("batch")
+	if err != nil {
+		t.Fatalf("ReadLog() error = %v", err)
+	}
+	exits := 0
+	for _, e := range entries {
+		if e.Event == session.LogExit {
+			exits++
+		}
+	}
+	if exits != 2 {
+		t.Errorf("log has %d exit entries, want 2: %+v", exits, entries)
+	}
+
+	live, known := liveWindowIDs(context.Background(), nil, loaded)
+	if !known || len(live) != 0 {
+		t.Errorf("liveWindowIDs() = %v, %v; want none, known", live, known)
+	}
+}
+
+func TestRunHeadlessVerifies(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	storeDir := t.TempDir()
+	saveMergeSession(t, storeDir, map[string]string{"a.txt": ""}, "a.txt", "a.txt")
+	store := session.NewStore(storeDir)
+	sess, err := store.LoadSession("sprint")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	sess.Backend = headlessBackend
+	sess.Status = "active"
+	sess.Prompts = []string{"echo done > result.txt", "true"}
+	sess.Agent = agent.Profile{Name: agent.CustomName, Command: "sh", Args: []string{"-c", "{prompt}"}, PromptMode: agent.PromptPositional}
+	sess.Verify = "test -f result.txt"
+
+	var stdout, stderr bytes.Buffer
+	err = runHeadless(context.Background(), store, sess, []string{sess.Worktrees[0].Path, sess.Worktrees[1].Path}, func() {}, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("runHeadless() error = %v\n%s", err, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "1     1       sprint-1  passed") || !strings.Contains(stdout.String(), "claude-grid pick sprint 1") {
+		t.Errorf("stdout = %q, want window 1 ranked first", stdout.String())
+	}
+
+	loaded, err := store.LoadSession("sprint")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if len(loaded.Verifications) != 2 || !loaded.Verifications[0].Passed || loaded.Verifications[1].Passed {
+		t.Errorf("verifications = %+v, want window 1 passing", loaded.Verifications)
+	}
+}
+
+func TestLiveHeadlessProcessesChecksStartTime(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+	started, err := headless.StartTime(os.Getpid())
+	if err != nil {
+		t.Skipf("StartTime() error = %v", err)
+	}
+	sess := session.Session{Windows: []session.WindowRef{
+		{ID: pid, Index: 0, ProcessStart: started},
+		{ID: pid, Index: 1, ProcessStart: "Thu Jan  1 00:00:00 1970"},
+	}}
+	// The second agent exited and its pid now belongs to another process.
+	if live := liveHeadlessProcesses(sess); len(live) != 1 {
+		t.Errorf("liveHeadlessProcesses() = %v, want only the first window", live)
+	}
+}
+
+func TestRunLogCapturerTail(t *testing.T) {
+	path := t.TempDir() + "/1.log"
+	var b strings.Builder
+	for i := 0; i < headlessTailLines*2; i++ {
+		b.WriteString("line\n")
+	}
+	b.WriteString("last\n")
+	os.WriteFile(path, []byte(b.String()), 0644)
+
+	w := session.WindowRef{ID: "1", LogPath: path}
+	tail, err := runLogCapturer{}.CaptureOutput(context.Background(), w, false)
+	if err != nil {
+		t.Fatalf("CaptureOutput() error = %v", err)
+	}
+	if n := strings.Count(tail, "\n"); n > headlessTailLines || !strings.HasSuffix(tail, "last\n") {
+		t.Errorf("tail has %d lines, ends %q", n, tail[len(tail)-10:])
+	}
+	full, _ := runLogCapturer{}.CaptureOutput(context.Background(), w, true)
+	if full != b.String() {
+		t.Error("scrollback should return the whole log")
+	}
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/riricardoMa/claude-grid/internal/config"
+)
+
+// formatAge renders a duration in its largest whole unit, e.g. "42s", "5m",
+// "3h" or "2d".
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", max(int(d.Seconds()), 0))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// warnIgnoredSettings tells the user about the settings of the project
+// config file that only the global config file may set.
+func warnIgnoredSettings(w io.Writer, cfg config.Config) {
+	for _, key := range cfg.Ignored {
+		fmt.Fprintf(w, "warning: ignoring %s in %s; command settings are only read from %s\n", key, displayPath(cfg.Project), displayPath(config.GlobalPath()))
+	}
+}
+
+// displayPath abbreviates the home directory in path to ~.
+func displayPath(path string) string {
+	home := os.ExpandEnv("$HOME")
+	if home != "" && strings.HasPrefix(path, home) {
+		return "~" + strings.TrimPrefix(path, home)
+	}
+	return path
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/riricardoMa/claude-grid/internal/hooks"
+	"github.com/spf13/cobra"
+)
+
+// hookInput is the part of the JSON Claude Code passes to hooks on stdin
+// that claude-grid records.
+type hookInput struct {
+	SessionID     string `json:"session_id"`
+	HookEventName string `json:"hook_event_name"`
+	ToolName      string `json:"tool_name"`
+	Message       string `json:"message"`
+}
+
+func NewHookCmd(storePath string) *cobra.Command {
+	var sessionName string
+	var window int
+
+	cmd := &cobra.Command{
+		Use:   "hook",
+		Short: "Record a Claude Code hook event (run by the hooks --hooks installs)",
+		Long: `Record a Claude Code hook event for one window of a session. Claude Code runs
+this command from the hooks installed with --hooks, passing the event as JSON
+on stdin; it is not meant to be run by hand.`,
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if sessionName == "" || window < 1 {
+				return fmt.Errorf("--session and --window are required")
+			}
+
+			data, err := io.ReadAll(cmd.InOrStdin())
+			if err != nil {
+				return fmt.Errorf("read hook input: %w", err)
+			}
+			var input hookInput
+			if err := json.Unmarshal(data, &input); err != nil {
+				return fmt.Errorf("parse hook input: %w", err)
+			}
+
+			return hooks.NewLog(storePath).Append(sessionName, hooks.Event{
+				Time:           time.Now(),
+				Window:         window,
+				Hook:           input.HookEventName,
+				Tool:           input.ToolName,
+				Message:        input.Message,
+				ConversationID: input.SessionID,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&sessionName, "session", "", "Session the event belongs to")
+	cmd.Flags().IntVar(&window, "window", 0, "Window the event belongs to, numbered from 1")
+
+	return cmd
+}
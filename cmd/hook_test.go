@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/riricardoMa/claude-grid/internal/hooks"
+)
+
+func TestHookRecordsEvent(t *testing.T) {
+	storeDir := t.TempDir()
+	cmd := NewHookCmd(storeDir)
+	cmd.SetIn(strings.NewReader(`{"session_id":"abc","hook_event_name":"Notification","message":"Claude needs your permission to use Bash","cwd":"/wt/1"}`))
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--session", "sprint", "--window", "2"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	events, err := hooks.NewLog(storeDir).Read("sprint")
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("events = %+v, want one", events)
+	}
+	e := events[0]
+	if e.Window != 2 || e.Hook != "Notification" || e.ConversationID != "abc" || !strings.Contains(e.Message, "permission") || e.Time.IsZero() {
+		t.Errorf("event = %+v", e)
+	}
+}
+
+func TestHookRequiresSessionAndWindow(t *testing.T) {
+	cmd := NewHookCmd(t.TempDir())
+	cmd.SetIn(strings.NewReader(`{}`))
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--session", "sprint"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("Execute() error = nil, want error")
+	}
+}
@@ -1,58 +1,107 @@
-//go:build darwin
-
 package cmd
 
 import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/riricardoMa/claude-grid/internal/claude"
 	"github.com/riricardoMa/claude-grid/internal/script"
 	"github.com/riricardoMa/claude-grid/internal/session"
-	"github.com/riricardoMa/claude-grid/internal/terminal"
 )
 
+// killResult is the machine-readable outcome of kill. Failures are reported
+// in Errors without failing the command, matching the table output.
+type killResult struct {
+	Session            string   `json:"session" yaml:"session"`
+	Status             string   `json:"status" yaml:"status"`
+	WindowsClosed      int      `json:"windows_closed" yaml:"windows_closed"`
+	WorktreesPreserved int      `json:"worktrees_preserved" yaml:"worktrees_preserved"`
+	Errors             []string `json:"errors" yaml:"errors"`
+}
+
 func NewKillCmd(storePath string, executor script.ScriptExecutor) *cobra.Command {
-	return &cobra.Command{
+	var output outputOptions
+
+	cmd := &cobra.Command{
 		Use:   "kill <session-name>",
 		Short: "Kill a session and close all its windows",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			sessionName := args[0]
 
+			if err := output.validate(); err != nil {
+				return err
+			}
+
 			store := session.NewStore(storePath)
-			sess, err := store.LoadSession(sessionName)
+			sess, err := loadSession(store, sessionName, cmd.ErrOrStderr())
 			if err != nil {
-				fmt.Fprintf(cmd.ErrOrStderr(), "Session '%s' not found. Run 'claude-grid list' to see active sessions.\n", sessionName)
-				return fmt.Errorf("session '%s' not found", sessionName)
+				return err
 			}
 
-			var backend terminal.TerminalBackend
-			switch sess.Backend {
-			case "terminal":
-				backend = terminal.NewTerminalAppBackend(executor)
-			case "warp":
-				backend = terminal.NewWarpBackend(executor)
-			default:
-				return fmt.Errorf("unknown backend: %s", sess.Backend)
+			closeSession := stopHeadless
+			if sess.Backend != headlessBackend {
+				backend, err := backendForSession(sess.Backend, executor)
+				if err != nil {
+					return err
+				}
+				closeSession = func(s session.Session) error { return backend.CloseSession(s.Name) }
 			}
 
-			if err := backend.CloseSession(sessionName); err != nil {
-				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: failed to close windows: %v\n", err)
+			result := killResult{Session: sessionName, Errors: []string{}}
+
+			if err := closeSession(sess); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("failed to close windows: %v", err))
+			} else {
+				result.WindowsClosed = len(sess.Windows)
 			}
 
 			if len(sess.Worktrees) > 0 {
-				sess.Status = "stopped"
-				if err := store.UpdateSession(sess); err != nil {
-					fmt.Fprintf(cmd.ErrOrStderr(), "Warning: failed to update session: %v\n", err)
+				result.Status = "stopped"
+				result.WorktreesPreserved = len(sess.Worktrees)
+				err := store.ModifySession(sessionName, func(s *session.Session) error {
+					if sessionAgent(*s).CanResume() {
+						recordConversations(claude.NewLocator(""), s)
+					}
+					s.Status = "stopped"
+					return nil
+				})
+				if err != nil {
+					result.Errors = append(result.Errors, fmt.Sprintf("failed to update session: %v", err))
 				}
-				fmt.Fprintf(cmd.OutOrStdout(), "Session '%s' stopped. %d windows closed. Worktrees preserved.\nRun 'claude-grid clean %s' to remove worktrees.\n", sessionName, len(sess.Windows), sessionName)
 			} else {
+				result.Status = "killed"
 				if err := store.DeleteSession(sessionName); err != nil {
-					fmt.Fprintf(cmd.ErrOrStderr(), "Warning: failed to delete session file: %v\n", err)
+					result.Errors = append(result.Errors, fmt.Sprintf("failed to delete session file: %v", err))
 				}
+				if err := store.DeleteOutputLogs(sessionName); err != nil {
+					result.Errors = append(result.Errors, err.Error())
+				}
+			}
+
+			for _, e := range result.Errors {
+				logEvent(store, sessionName, session.LogEntry{Event: session.LogError, Message: e}, cmd.ErrOrStderr())
+			}
+			logEvent(store, sessionName, session.LogEntry{Event: session.LogKill, Message: fmt.Sprintf("%s, %d windows closed", result.Status, result.WindowsClosed)}, cmd.ErrOrStderr())
+
+			if output.structured() {
+				return output.printResult(cmd.OutOrStdout(), result)
+			}
+
+			for _, e := range result.Errors {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", e)
+			}
+
+			if result.Status == "stopped" {
+				fmt.Fprintf(cmd.OutOrStdout(), "Session '%s' stopped. %d windows closed. Worktrees preserved.\nRun 'claude-grid resume %s' to reopen, or 'claude-grid clean %s' to remove worktrees.\n", sessionName, len(sess.Windows), sessionName, sessionName)
+			} else {
 				fmt.Fprintf(cmd.OutOrStdout(), "Session '%s' killed. %d windows closed.\n", sessionName, len(sess.Windows))
 			}
 			return nil
 		},
 	}
+
+	output.addFlags(cmd)
+
+	return cmd
 }
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/riricardoMa/claude-grid/internal/session"
+)
+
+func TestKillOutputJSON(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("CLAUDE_CONFIG_DIR", t.TempDir())
+	storeDir := t.TempDir()
+	store := session.NewStore(storeDir)
+	if err := store.SaveSession(session.Session{
+		Name:      "sprint",
+		Backend:   "terminal",
+		Count:     1,
+		Status:    "active",
+		Windows:   []session.WindowRef{{ID: "42", Index: 0}},
+		Worktrees: []session.WorktreeRef{{Path: t.TempDir(), Branch: "b-1"}},
+	}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	cmd := NewKillCmd(storeDir, &stubExecutor{})
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"sprint", "-o", "json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var got killResult
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, stdout.String())
+	}
+	if got.Session != "sprint" || got.Status != "stopped" || got.WorktreesPreserved != 1 {
+		t.Errorf("result = %+v", got)
+	}
+	if got.Errors == nil {
+		t.Error("errors should be an empty list, not null")
+	}
+	if strings.Contains(stdout.String(), "Worktrees preserved.") {
+		t.Errorf("structured output should not include prose: %s", stdout.String())
+	}
+
+	loaded, err := store.LoadSession("sprint")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if loaded.Status != "stopped" {
+		t.Errorf("Status = %q, want %q", loaded.Status, "stopped")
+	}
+
+	entries, err := store.ReadLog("sprint")
+	if err != nil {
+		t.Fatalf("ReadLog() error = %v", err)
+	}
+	last := entries[len(entries)-1]
+	if last.Event != session.LogKill || last.Command == "" {
+		t.Errorf("log = %+v, want a kill entry with the command last", entries)
+	}
+}
+
+func TestCleanOutputJSONReportsErrors(t *testing.T) {
+	storeDir := t.TempDir()
+	repo := t.TempDir()
+	runGit(t, repo, "init", "-q")
+	notWorktree := t.TempDir()
+
+	store := session.NewStore(storeDir)
+	if err := store.SaveSession(session.Session{
+		Name:      "sprint",
+		Backend:   "tmux",
+		Count:     1,
+		Status:    "stopped",
+		RepoPath:  repo,
+		Worktrees: []session.WorktreeRef{{Path: notWorktree, Branch: "b-1"}},
+	}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	runLog := store.RunLogPath("sprint", 1)
+	if err := os.MkdirAll(filepath.Dir(runLog), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(runLog, []byte("output\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewCleanCmd(storeDir)
+	cmd.SilenceUsage = true
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"sprint", "--output", "json"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error for failed removal, got nil")
+	}
+
+	var got cleanResult
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, stdout.String())
+	}
+	if got.Session != "sprint" || got.WorktreesTotal != 1 || len(got.WorktreesRemoved) != 0 {
+		t.Errorf("result = %+v", got)
+	}
+	if len(got.Errors) == 0 || !strings.Contains(got.Errors[0], notWorktree) {
+		t.Errorf("errors = %v, want removal failure", got.Errors)
+	}
+	if _, err := os.Stat(filepath.Dir(runLog)); !os.IsNotExist(err) {
+		t.Errorf("output logs not removed: %v", err)
+	}
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s failed: %v (output: %s)", strings.Join(args, " "), err, output)
+	}
+}
@@ -3,27 +3,91 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"os"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/riricardoMa/claude-grid/internal/agentstate"
+	"github.com/riricardoMa/claude-grid/internal/hooks"
 	"github.com/riricardoMa/claude-grid/internal/script"
 	"github.com/riricardoMa/claude-grid/internal/session"
+	"github.com/riricardoMa/claude-grid/internal/terminal"
 )
 
+// sessionSummary is the machine-readable form of a session printed by list.
+type sessionSummary struct {
+	Name      string            `json:"name" yaml:"name"`
+	Status    string            `json:"status" yaml:"status"`
+	Live      bool              `json:"live" yaml:"live"`
+	Backend   string            `json:"backend" yaml:"backend"`
+	Windows   int               `json:"windows" yaml:"windows"`
+	Dir       string            `json:"dir" yaml:"dir"`
+	Layout    string            `json:"layout,omitempty" yaml:"layout,omitempty"`
+	CreatedAt time.Time         `json:"created_at" yaml:"created_at"`
+	Worktrees []worktreeSummary `json:"worktrees,omitempty" yaml:"worktrees,omitempty"`
+	// AgentStates counts windows per agent state, for sessions with hooks.
+	AgentStates map[string]int `json:"agent_states,omitempty" yaml:"agent_states,omitempty"`
+}
+
+type worktreeSummary struct {
+	Path   string `json:"path" yaml:"path"`
+	Branch string `json:"branch" yaml:"branch"`
+}
+
+func summarizeSession(sess session.Session, live bool) sessionSummary {
+	summary := sessionSummary{
+		Name:      sess.Name,
+		Status:    sess.Status,
+		Live:      live,
+		Backend:   sess.Backend,
+		Windows:   len(sess.Windows),
+		Dir:       sess.Dir,
+		Layout:    sess.Layout,
+		CreatedAt: sess.CreatedAt,
+	}
+	for _, wt := range sess.Worktrees {
+		summary.Worktrees = append(summary.Worktrees, worktreeSummary{Path: wt.Path, Branch: wt.Branch})
+	}
+	return summary
+}
+
 func NewListCmd(storePath string, executor script.ScriptExecutor) *cobra.Command {
-	return &cobra.Command{
+	var output outputOptions
+
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all active sessions",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := output.validate(); err != nil {
+				return err
+			}
+
 			store := session.NewStore(storePath)
-			sessions, err := store.ListSessions()
+			sessions, corrupt, err := store.ScanSessions()
 			if err != nil {
 				return fmt.Errorf("failed to list sessions: %w", err)
 			}
 
-			if len(sessions) == 0 {
+			for _, c := range corrupt {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: session '%s' is unreadable: %v\n", c.Name, c.Err)
+			}
+
+			events := hooks.NewLog(storePath)
+			summaries := make([]sessionSummary, 0, len(sessions))
+			for _, sess := range sessions {
+				summary := summarizeSession(sess, checkSessionLiveness(cmd.Context(), executor, sess))
+				if sess.Hooks && summary.Live && sess.Status != "stopped" {
+					summary.AgentStates = hookAgentStates(events, sess)
+				}
+				summaries = append(summaries, summary)
+			}
+
+			if output.structured() {
+				return printResults(&output, cmd.OutOrStdout(), summaries)
+			}
+
+			if len(summaries) == 0 {
 				fmt.Fprintln(cmd.OutOrStdout(), "No active sessions.")
 				return nil
 			}
@@ -31,53 +95,99 @@ func NewListCmd(storePath string, executor script.ScriptExecutor) *cobra.Command
 			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
 			fmt.Fprintln(w, "SESSION\tSTATUS\tBACKEND\tWINDOWS\tDIR\tCREATED")
 
-			for _, sess := range sessions {
-				isLive := checkSessionLiveness(cmd.Context(), executor, sess)
+			for _, sess := range summaries {
 				statusCol := sess.Status
-				if statusCol == "" {
-					statusCol = "active"
-				}
-				if !isLive {
+				if !sess.Live {
 					statusCol = statusCol + " (stale)"
-				}
-
-				displayDir := sess.Dir
-				if strings.HasPrefix(displayDir, os.ExpandEnv("$HOME")) {
-					displayDir = "~" + strings.TrimPrefix(displayDir, os.ExpandEnv("$HOME"))
+				} else if n := sess.AgentStates[string(agentstate.Waiting)]; n > 0 {
+					statusCol = fmt.Sprintf("%s (%d waiting)", statusCol, n)
 				}
 
 				createdStr := sess.CreatedAt.Format("2006-01-02 15:04")
 				fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n",
-					sess.Name, statusCol, sess.Backend, len(sess.Windows),
-					displayDir, createdStr)
+					sess.Name, statusCol, sess.Backend, sess.Windows,
+					displayPath(sess.Dir), createdStr)
 			}
 
 			w.Flush()
 			return nil
 		},
 	}
+
+	output.addFlags(cmd)
+
+	return cmd
+}
+
+// hookAgentStates counts the windows of sess by the state their latest hook
+// event reports. Windows without events are not counted.
+func hookAgentStates(events *hooks.Log, sess session.Session) map[string]int {
+	latest, err := events.Latest(sess.Name)
+	if err != nil || len(latest) == 0 {
+		return nil
+	}
+	states := make(map[string]int)
+	for _, e := range latest {
+		if state := e.State(); state != agentstate.Unknown {
+			states[string(state)]++
+		}
+	}
+	return states
 }
 
 func checkSessionLiveness(ctx context.Context, executor script.ScriptExecutor, sess session.Session) bool {
-	if executor == nil {
+	return sessionLive(liveWindowIDs(ctx, executor, sess))
+}
+
+// sessionLive reports whether any window is open, given the result of
+// liveWindowIDs.
+func sessionLive(live map[string]bool, known bool) bool {
+	return !known || len(live) > 0
+}
+
+// checkWindowLiveness reports whether one window of sess is still open.
+func checkWindowLiveness(ctx context.Context, executor script.ScriptExecutor, sess session.Session, w session.WindowRef) bool {
+	live, known := liveWindowIDs(ctx, executor, sess)
+	if !known {
 		return true
 	}
+	return live[w.ID]
+}
+
+// liveWindowIDs returns the IDs of the session's windows that are still open.
+// known is false when liveness cannot be checked for the session's backend,
+// in which case callers should assume the windows are open.
+func liveWindowIDs(ctx context.Context, executor script.ScriptExecutor, sess session.Session) (live map[string]bool, known bool) {
+	if sess.Backend == headlessBackend {
+		live = make(map[string]bool)
+		for _, id := range liveHeadlessProcesses(sess) {
+			live[id] = true
+		}
+		return live, true
+	}
+	if executor == nil {
+		return nil, false
+	}
 
 	switch sess.Backend {
 	case "terminal":
-		return checkTerminalLiveness(ctx, executor, sess)
+		return liveTerminalWindows(ctx, executor, sess), true
 	case "warp":
-		return checkWarpLiveness(ctx, executor)
+		return liveWarpWindows(ctx, executor, sess), true
+	case "tmux":
+		return liveTmuxWindows(ctx, sess), true
 	default:
-		return true
+		return nil, false
 	}
 }
 
-func checkTerminalLiveness(ctx context.Context, executor script.ScriptExecutor, sess session.Session) bool {
+func liveTerminalWindows(ctx context.Context, executor script.ScriptExecutor, sess session.Session) map[string]bool {
+	live := make(map[string]bool)
+
 	script := `tell application "Terminal" to get id of every window`
 	output, err := executor.RunAppleScript(ctx, script)
 	if err != nil {
-		return false
+		return live
 	}
 
 	windowIDsStr := strings.Split(output, ", ")
@@ -88,20 +198,65 @@ func checkTerminalLiveness(ctx context.Context, executor script.ScriptExecutor,
 
 	for _, winRef := range sess.Windows {
 		if windowIDsMap[winRef.ID] {
-			return true
+			live[winRef.ID] = true
 		}
 	}
 
-	return false
+	return live
 }
 
-func checkWarpLiveness(ctx context.Context, executor script.ScriptExecutor) bool {
-	script := `tell application "System Events" to tell process "Warp" to count windows`
+func liveWarpWindows(ctx context.Context, executor script.ScriptExecutor, sess session.Session) map[string]bool {
+	live := make(map[string]bool)
+
+	script := `tell application "System Events" to tell process "Warp" to get name of every window`
 	output, err := executor.RunAppleScript(ctx, script)
 	if err != nil {
-		return false
+		return live
+	}
+
+	for _, winRef := range sess.Windows {
+		if winRef.ID != "" && containsWindowTitle(output, winRef.ID) {
+			live[winRef.ID] = true
+		}
+	}
+
+	return live
+}
+
+// containsWindowTitle reports whether names contains title not followed by
+// another digit, so window 1's marker does not match window 10.
+func containsWindowTitle(names, title string) bool {
+	for offset := 0; ; {
+		i := strings.Index(names[offset:], title)
+		if i < 0 {
+			return false
+		}
+		end := offset + i + len(title)
+		if end == len(names) || names[end] < '0' || names[end] > '9' {
+			return true
+		}
+		offset = end
+	}
+}
+
+func liveTmuxWindows(ctx context.Context, sess session.Session) map[string]bool {
+	live := make(map[string]bool)
+
+	paneIDs, err := terminal.NewTmuxBackend().PaneIDs(ctx, sess.Name)
+	if err != nil {
+		return live
+	}
+
+	paneIDsMap := make(map[string]bool)
+	for _, id := range paneIDs {
+		paneIDsMap[id] = true
+	}
+
+	for _, winRef := range sess.Windows {
+		if paneIDsMap[winRef.ID] {
+			live[winRef.ID] = true
+		}
 	}
 
-	output = strings.TrimSpace(output)
-	return output != "0" && output != ""
+	return live
 }
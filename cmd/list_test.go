@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/riricardoMa/claude-grid/internal/hooks"
+	"github.com/riricardoMa/claude-grid/internal/session"
+	"gopkg.in/yaml.v3"
+)
+
+func runList(t *testing.T, storeDir string, args ...string) (string, string, error) {
+	t.Helper()
+	cmd := NewListCmd(storeDir, nil)
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+func saveListSessions(t *testing.T, storeDir string) {
+	t.Helper()
+	store := session.NewStore(storeDir)
+	sessions := []session.Session{
+		{Name: "alpha", Backend: "tmux", Count: 2, Dir: "/work/a", Status: "active", Layout: "1x2",
+			CreatedAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+			Windows:   []session.WindowRef{{ID: "%1", Index: 0}, {ID: "%2", Index: 1}}},
+		{Name: "beta", Backend: "terminal", Count: 1, Dir: "/work/b", Status: "stopped",
+			CreatedAt: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+			Windows:   []session.WindowRef{{ID: "7", Index: 0}},
+			Worktrees: []session.WorktreeRef{{Path: "/wt/b-1", Branch: "b-1"}}},
+	}
+	for _, sess := range sessions {
+		if err := store.SaveSession(sess); err != nil {
+			t.Fatalf("SaveSession() error = %v", err)
+		}
+	}
+}
+
+func TestListReportsCorruptSessions(t *testing.T) {
+	storeDir := t.TempDir()
+	sessionDir := filepath.Join(storeDir, "sessions")
+	if err := os.MkdirAll(sessionDir, 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sessionDir, "grid-bad.json"), []byte(`{"name":`), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cmd := NewListCmd(storeDir, nil)
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if !strings.Contains(stderr.String(), "grid-bad") {
+		t.Errorf("stderr = %q, want warning about grid-bad", stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "No active sessions.") {
+		t.Errorf("stdout = %q, want 'No active sessions.'", stdout.String())
+	}
+}
+
+func TestListOutputJSON(t *testing.T) {
+	storeDir := t.TempDir()
+	saveListSessions(t, storeDir)
+
+	stdout, _, err := runList(t, storeDir, "--output", "json")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var got []sessionSummary
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, stdout)
+	}
+	if len(got) != 2 {
+		t.Fatalf("len(sessions) = %d, want 2", len(got))
+	}
+
+	byName := map[string]sessionSummary{got[0].Name: got[0], got[1].Name: got[1]}
+	alpha := byName["alpha"]
+	if alpha.Status != "active" || !alpha.Live || alpha.Windows != 2 || alpha.Layout != "1x2" || alpha.Backend != "tmux" {
+		t.Errorf("alpha = %+v", alpha)
+	}
+	beta := byName["beta"]
+	if beta.Status != "stopped" || len(beta.Worktrees) != 1 || beta.Worktrees[0].Branch != "b-1" {
+		t.Errorf("beta = %+v", beta)
+	}
+}
+
+func TestListOutputJSONEmpty(t *testing.T) {
+	stdout, _, err := runList(t, t.TempDir(), "-o", "json")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if strings.TrimSpace(stdout) != "[]" {
+		t.Errorf("stdout = %q, want empty JSON array", stdout)
+	}
+}
+
+func TestListOutputYAML(t *testing.T) {
+	storeDir := t.TempDir()
+	saveListSessions(t, storeDir)
+
+	stdout, _, err := runList(t, storeDir, "-o", "yaml")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var got []map[string]any
+	if err := yaml.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatalf("output is not YAML: %v\n%s", err, stdout)
+	}
+	if len(got) != 2 || got[0]["created_at"] == nil || got[0]["windows"] == nil {
+		t.Errorf("YAML sessions = %v", got)
+	}
+}
+
+func TestListOutputTemplate(t *testing.T) {
+	storeDir := t.TempDir()
+	saveListSessions(t, storeDir)
+
+	stdout, _, err := runList(t, storeDir, "--template", "{{.Name}}:{{.Status}}:{{.Windows}}")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("lines = %q, want one per session", lines)
+	}
+	for _, want := range []string{"alpha:active:2", "beta:stopped:1"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("stdout = %q, want line %q", stdout, want)
+		}
+	}
+}
+
+func TestListOutputInvalid(t *testing.T) {
+	if _, _, err := runList(t, t.TempDir(), "-o", "xml"); err == nil || !strings.Contains(err.Error(), "unsupported output format") {
+		t.Errorf("Execute() error = %v, want unsupported output format", err)
+	}
+	if _, _, err := runList(t, t.TempDir(), "--template", "{{.Name"); err == nil || !strings.Contains(err.Error(), "invalid --template") {
+		t.Errorf("Execute() error = %v, want invalid template", err)
+	}
+}
+
+func TestListShowsWaitingAgentsFromHooks(t *testing.T) {
+	storeDir := t.TempDir()
+	if err := session.NewStore(storeDir).SaveSession(session.Session{
+		Name: "sprint", Backend: "custom", Count: 2, Dir: "/work", Status: "active", Hooks: true,
+		Windows: []session.WindowRef{{ID: "1", Index: 0}, {ID: "2", Index: 1}},
+	}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+	events := hooks.NewLog(storeDir)
+	now := time.Now()
+	for _, e := range []hooks.Event{
+		{Time: now, Window: 1, Hook: "Notification"},
+		{Time: now, Window: 2, Hook: "PostToolUse"},
+	} {
+		if err := events.Append("sprint", e); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	stdout, _, err := runList(t, storeDir)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(stdout, "active (1 waiting)") {
+		t.Errorf("stdout = %q, want waiting count in status", stdout)
+	}
+
+	stdout, _, err = runList(t, storeDir, "-o", "json")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	var summaries []sessionSummary
+	if err := json.Unmarshal([]byte(stdout), &summaries); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got := summaries[0].AgentStates; got["waiting"] != 1 || got["working"] != 1 {
+		t.Errorf("agent_states = %v, want 1 waiting and 1 working", got)
+	}
+}
+
+func TestContainsWindowTitle(t *testing.T) {
+	tests := []struct {
+		names string
+		title string
+		want  bool
+	}{
+		{names: "claude-grid:s:1, other", title: "claude-grid:s:1", want: true},
+		{names: "claude-grid:s:10", title: "claude-grid:s:1", want: false},
+		{names: "claude-grid:s:10, claude-grid:s:1 — zsh", title: "claude-grid:s:1", want: true},
+		{names: "", title: "claude-grid:s:1", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := containsWindowTitle(tt.names, tt.title); got != tt.want {
+			t.Errorf("containsWindowTitle(%q, %q) = %v, want %v", tt.names, tt.title, got, tt.want)
+		}
+	}
+}
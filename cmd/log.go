@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/riricardoMa/claude-grid/internal/session"
+	"github.com/riricardoMa/claude-grid/internal/terminal"
+	"github.com/spf13/cobra"
+)
+
+func NewLogCmd(storePath string) *cobra.Command {
+	var output outputOptions
+
+	cmd := &cobra.Command{
+		Use:   "log <session-name>",
+		Short: "Show the lifecycle event log of a session",
+		Long: `Show the lifecycle events recorded for a session: spawn, each window start,
+branch checkouts and worktree creation, kill, clean, resume, and errors, with
+the claude-grid command that caused them. The log is kept after the session
+is killed or cleaned.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionName := args[0]
+
+			if err := output.validate(); err != nil {
+				return err
+			}
+
+			store := session.NewStore(storePath)
+			entries, err := store.ReadLog(sessionName)
+			if errors.Is(err, os.ErrNotExist) {
+				fmt.Fprintf(cmd.ErrOrStderr(), "No log for session '%s'.\n", sessionName)
+				return fmt.Errorf("no log for session '%s'", sessionName)
+			}
+			if err != nil {
+				return err
+			}
+
+			if output.structured() {
+				return printResults(&output, cmd.OutOrStdout(), entries)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TIME\tEVENT\tWINDOW\tMESSAGE\tCOMMAND")
+			for _, e := range entries {
+				window := "-"
+				if e.Window > 0 {
+					window = fmt.Sprintf("%d", e.Window)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Time.Local().Format("2006-01-02 15:04:05"), e.Event, window, e.Message, e.Command)
+			}
+			return w.Flush()
+		},
+	}
+
+	output.addFlags(cmd)
+
+	return cmd
+}
+
+// logEvent appends entry, stamped with the invoking command line, to the log
+// of session name. Logging is best effort: a failure is only a warning.
+func logEvent(store *session.Store, name string, entry session.LogEntry, stderr io.Writer) {
+	entry.Command = invocation()
+	if err := store.AppendLog(name, entry); err != nil {
+		fmt.Fprintf(stderr, "warning: failed to write session log: %v\n", err)
+	}
+}
+
+// logWindowStarts records a window_start entry for each spawned window.
+func logWindowStarts(store *session.Store, name string, windows []terminal.WindowInfo, dirs []string, stderr io.Writer) {
+	for _, w := range windows {
+		message := "window " + w.ID
+		if w.Index >= 0 && w.Index < len(dirs) {
+			message += " in " + dirs[w.Index]
+		}
+		logEvent(store, name, session.LogEntry{Event: session.LogWindowStart, Window: w.Index + 1, Message: message}, stderr)
+	}
+}
+
+// invocation returns the command line claude-grid was started with.
+func invocation() string {
+	if len(os.Args) == 0 {
+		return ""
+	}
+	args := append([]string{filepath.Base(os.Args[0])}, os.Args[1:]...)
+	return strings.Join(args, " ")
+}
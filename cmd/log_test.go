@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/riricardoMa/claude-grid/internal/session"
+)
+
+func runLog(t *testing.T, storeDir string, args ...string) (string, string, error) {
+	t.Helper()
+	cmd := NewLogCmd(storeDir)
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+func saveLogEntries(t *testing.T, storeDir string) {
+	t.Helper()
+	store := session.NewStore(storeDir)
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	for _, e := range []session.LogEntry{
+		{Time: base, Event: session.LogSpawn, Command: "claude-grid 2 --worktrees -n sprint", Message: "2 claude windows on tmux, layout 1x2"},
+		{Time: base, Event: session.LogWindowStart, Window: 2, Message: "window %2 in /wt/sprint-2"},
+		{Time: base.Add(time.Hour), Event: session.LogError, Command: "claude-grid resume sprint", Message: "resume: directory does not exist: /wt/sprint-2"},
+	} {
+		if err := store.AppendLog("sprint", e); err != nil {
+			t.Fatalf("AppendLog() error = %v", err)
+		}
+	}
+}
+
+func TestLogTable(t *testing.T) {
+	storeDir := t.TempDir()
+	saveLogEntries(t, storeDir)
+
+	stdout, _, err := runLog(t, storeDir, "sprint")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "TIME") {
+		t.Fatalf("stdout = %q, want header and 3 entries", stdout)
+	}
+	if !strings.Contains(lines[2], "window_start") || !strings.Contains(lines[2], "/wt/sprint-2") {
+		t.Errorf("line = %q, want window start", lines[2])
+	}
+	if !strings.Contains(lines[3], "directory does not exist") || !strings.Contains(lines[3], "claude-grid resume sprint") {
+		t.Errorf("line = %q, want error with command", lines[3])
+	}
+}
+
+func TestLogOutputJSON(t *testing.T) {
+	storeDir := t.TempDir()
+	saveLogEntries(t, storeDir)
+
+	stdout, _, err := runLog(t, storeDir, "sprint", "-o", "json")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	var entries []session.LogEntry
+	if err := json.Unmarshal([]byte(stdout), &entries); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, stdout)
+	}
+	if len(entries) != 3 || entries[0].Event != session.LogSpawn || entries[1].Window != 2 {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestLogMissing(t *testing.T) {
+	_, stderr, err := runLog(t, t.TempDir(), "none")
+	if err == nil {
+		t.Fatal("Execute() error = nil, want error")
+	}
+	if !strings.Contains(stderr, "No log for session 'none'") {
+		t.Errorf("stderr = %q", stderr)
+	}
+}
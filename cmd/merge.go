@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/riricardoMa/claude-grid/internal/git"
+	"github.com/riricardoMa/claude-grid/internal/session"
+	"github.com/spf13/cobra"
+)
+
+// mergeResult is the machine-readable outcome of merge.
+type mergeResult struct {
+	Session  string              `json:"session" yaml:"session"`
+	Target   string              `json:"target" yaml:"target"`
+	Strategy string              `json:"strategy" yaml:"strategy"`
+	DryRun   bool                `json:"dry_run" yaml:"dry_run"`
+	Before   string              `json:"before" yaml:"before"`
+	After    string              `json:"after" yaml:"after"`
+	Branches []mergeBranchResult `json:"branches" yaml:"branches"`
+}
+
+// mergeBranchResult is the outcome of one window's branch. Window is
+// numbered from 1.
+type mergeBranchResult struct {
+	Window    int      `json:"window" yaml:"window"`
+	Branch    string   `json:"branch" yaml:"branch"`
+	Result    string   `json:"result" yaml:"result"`
+	Commit    string   `json:"commit,omitempty" yaml:"commit,omitempty"`
+	Conflicts []string `json:"conflicts,omitempty" yaml:"conflicts,omitempty"`
+}
+
+func NewMergeCmd(storePath string) *cobra.Command {
+	var output outputOptions
+	var into string
+	var squash bool
+	var rebase bool
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "merge <session-name> [window...]",
+		Short: "Merge a session's worktree branches into a target branch",
+		Long: `Merge the branches of a session's worktrees into a target branch, one after
+another in window order, or in the order the windows are given. The target is
+the branch checked out in the session's repository unless --into names
+another. Only committed work is merged.
+
+Branches are combined in a temporary worktree, so neither the repository's
+checkout nor the agents' worktrees are touched. Merging stops at the first
+branch that conflicts, reporting its conflicting files; the target is advanced
+over the branches merged before it. With --dry-run the target is left as it
+was, which shows whether the branches would merge cleanly.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionName := args[0]
+
+			if err := output.validate(); err != nil {
+				return err
+			}
+			if squash && rebase {
+				return fmt.Errorf("--squash cannot be combined with --rebase")
+			}
+			strategy := git.MergeCommit
+			if squash {
+				strategy = git.MergeSquash
+			} else if rebase {
+				strategy = git.MergeRebase
+			}
+
+			store := session.NewStore(storePath)
+			sess, err := loadSession(store, sessionName, cmd.ErrOrStderr())
+			if err != nil {
+				return err
+			}
+			if len(sess.Worktrees) == 0 {
+				return fmt.Errorf("session '%s' has no worktrees to merge", sessionName)
+			}
+
+			windows, err := worktreeWindows(sess, args[1:])
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				return err
+			}
+
+			manager, err := git.NewManager(sess.RepoPath)
+			if err != nil {
+				return fmt.Errorf("failed to create git manager for %q: %w", sess.RepoPath, err)
+			}
+			if into == "" {
+				if into, err = manager.CurrentBranch(); err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v. Use --into to choose the target branch.\n", err)
+					return err
+				}
+			}
+
+			branches := make([]string, len(windows))
+			for i, n := range windows {
+				wt := sess.Worktrees[n-1]
+				branches[i] = wt.Branch
+				if status, err := git.Status(wt.Path); err == nil && status.Uncommitted > 0 {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Warning: window %d (%s) has %d uncommitted files, which will not be merged\n", n, wt.Branch, status.Uncommitted)
+				}
+			}
+
+			report, err := manager.MergeBranches(into, branches, git.MergeOptions{Strategy: strategy, DryRun: dryRun})
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				logEvent(store, sessionName, session.LogEntry{Event: session.LogError, Message: err.Error()}, cmd.ErrOrStderr())
+				return err
+			}
+
+			result := mergeResult{
+				Session:  sessionName,
+				Target:   into,
+				Strategy: string(strategy),
+				DryRun:   dryRun,
+				Before:   report.Before,
+				After:    report.After,
+				Branches: make([]mergeBranchResult, len(report.Branches)),
+			}
+			for i, b := range report.Branches {
+				result.Branches[i] = mergeBranchResult{Window: windows[i], Branch: b.Branch, Result: b.Result, Commit: b.Commit, Conflicts: b.Conflicts}
+			}
+
+			merged := 0
+			for _, b := range report.Branches {
+				if b.Result == git.MergeMerged {
+					merged++
+				}
+			}
+			if !dryRun {
+				logEvent(store, sessionName, session.LogEntry{Event: session.LogMerge, Message: fmt.Sprintf("%s %d/%d branches into %s (%s..%s)", strategy, merged, len(branches), into, shortCommit(report.Before), shortCommit(report.After))}, cmd.ErrOrStderr())
+			}
+
+			conflict, conflicted := report.Conflict()
+			if output.structured() {
+				if err := output.printResult(cmd.OutOrStdout(), result); err != nil {
+					return err
+				}
+			} else {
+				printMergeResult(cmd.OutOrStdout(), result)
+			}
+
+			if conflicted {
+				// Each worktree has its own branch, so the branch names the window.
+				window := windows[slices.Index(branches, conflict.Branch)]
+				logEvent(store, sessionName, session.LogEntry{Event: session.LogError, Window: window, Message: fmt.Sprintf("merge of %s into %s conflicted in %s", conflict.Branch, into, strings.Join(conflict.Conflicts, ", "))}, cmd.ErrOrStderr())
+				if !output.structured() {
+					fmt.Fprintf(cmd.ErrOrStderr(), "\nBranch %s (window %d) conflicts with %s in:\n", conflict.Branch, window, into)
+					for _, file := range conflict.Conflicts {
+						fmt.Fprintf(cmd.ErrOrStderr(), "  %s\n", file)
+					}
+					fmt.Fprintf(cmd.ErrOrStderr(), "Resolve the conflict on %s, or merge it into %s by hand, then rerun the merge for the remaining windows.\n", conflict.Branch, into)
+				}
+				return fmt.Errorf("merge of branch %s conflicted", conflict.Branch)
+			}
+			return nil
+		},
+	}
+
+	output.addFlags(cmd)
+	cmd.Flags().StringVar(&into, "into", "", "Branch to merge into (default: the branch checked out in the session's repository)")
+	cmd.Flags().BoolVar(&squash, "squash", false, "Add each branch as a single commit")
+	cmd.Flags().BoolVar(&rebase, "rebase", false, "Replay each branch's commits on top of the target instead of merging")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Test the merge without updating the target branch")
+
+	return cmd
+}
+
+// worktreeWindows returns the window numbers named by args, or every window
+// with a worktree when there are none.
+func worktreeWindows(sess session.Session, args []string) ([]int, error) {
+	if len(args) == 0 {
+		windows := make([]int, len(sess.Worktrees))
+		for i := range windows {
+			windows[i] = i + 1
+		}
+		return windows, nil
+	}
+
+	windows := make([]int, 0, len(args))
+	seen := make(map[int]bool, len(args))
+	for _, arg := range args {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 || n > len(sess.Worktrees) {
+			return nil, fmt.Errorf("invalid window %q: must be a number from 1 to %d", arg, len(sess.Worktrees))
+		}
+		if seen[n] {
+			return nil, fmt.Errorf("window %d given more than once", n)
+		}
+		seen[n] = true
+		windows = append(windows, n)
+	}
+	return windows, nil
+}
+
+// printMergeResult writes one row per branch and a summary line.
+func printMergeResult(w io.Writer, result mergeResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "WINDOW\tBRANCH\tRESULT\tCOMMIT")
+	for _, b := range result.Branches {
+		commit := "-"
+		if b.Result == git.MergeMerged {
+			commit = shortCommit(b.Commit)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", b.Window, b.Branch, b.Result, commit)
+	}
+	tw.Flush()
+
+	switch {
+	case result.DryRun:
+		fmt.Fprintf(w, "\nDry run: %s was not changed.\n", result.Target)
+	case result.After == result.Before:
+		fmt.Fprintf(w, "\n%s is unchanged at %s.\n", result.Target, shortCommit(result.After))
+	default:
+		fmt.Fprintf(w, "\n%s advanced from %s to %s.\n", result.Target, shortCommit(result.Before), shortCommit(result.After))
+	}
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/riricardoMa/claude-grid/internal/session"
+)
+
+// saveMergeSession creates a repository on branch main with one worktree per
+// file, each committing that file with the given content, and saves a
+// session over them.
+func saveMergeSession(t *testing.T, storeDir string, files map[string]string, order ...string) string {
+	t.Helper()
+	repo := t.TempDir()
+	runGit(t, repo, "init", "-q", "-b", "main")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test User")
+	runGit(t, repo, "commit", "-q", "--allow-empty", "-m", "init")
+
+	var worktrees []session.WorktreeRef
+	for i, file := range order {
+		branch := "sprint-" + string(rune('1'+i))
+		path := filepath.Join(t.TempDir(), branch)
+		runGit(t, repo, "worktree", "add", "-q", "-b", branch, path)
+		if err := os.WriteFile(filepath.Join(path, file), []byte(files[file]+branch+"\n"), 0644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		runGit(t, path, "add", file)
+		runGit(t, path, "commit", "-q", "-m", "add "+file)
+		worktrees = append(worktrees, session.WorktreeRef{Path: path, Branch: branch})
+	}
+
+	if err := session.NewStore(storeDir).SaveSession(session.Session{
+		Name:      "sprint",
+		Backend:   "tmux",
+		Count:     len(worktrees),
+		Status:    "stopped",
+		RepoPath:  repo,
+		Worktrees: worktrees,
+	}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+	return repo
+}
+
+func runMerge(t *testing.T, storeDir string, args ...string) (string, string, error) {
+	t.Helper()
+	cmd := NewMergeCmd(storeDir)
+	cmd.SilenceUsage = true
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		t.Fatalf("git %s failed: %v", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestMergeIntoCurrentBranch(t *testing.T) {
+	storeDir := t.TempDir()
+	repo := saveMergeSession(t, storeDir, map[string]string{"a.txt": "", "b.txt": ""}, "a.txt", "b.txt")
+
+	stdout, stderr, err := runMerge(t, storeDir, "sprint", "--squash")
+	if err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, "sprint-1  merged") || !strings.Contains(stdout, "main advanced from") {
+		t.Errorf("stdout = %q, want both branches merged into main", stdout)
+	}
+	if commits, merges := gitOutput(t, repo, "rev-list", "--count", "main"), gitOutput(t, repo, "rev-list", "--merges", "--count", "main"); commits != "3" || merges != "0" {
+		t.Errorf("main has %s commits and %s merges, want one squashed commit per branch", commits, merges)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "b.txt")); err != nil {
+		t.Errorf("b.txt not in the checkout of main: %v", err)
+	}
+
+	entries, err := session.NewStore(storeDir).ReadLog("sprint")
+	if err != nil || len(entries) == 0 || entries[len(entries)-1].Event != session.LogMerge {
+		t.Errorf("log = %+v, %v; want a merge entry", entries, err)
+	}
+}
+
+func TestMergeReportsConflict(t *testing.T) {
+	storeDir := t.TempDir()
+	repo := saveMergeSession(t, storeDir, map[string]string{"a.txt": "", "b.txt": ""}, "a.txt", "b.txt", "a.txt")
+	before := gitOutput(t, repo, "rev-parse", "main")
+
+	stdout, stderr, err := runMerge(t, storeDir, "sprint", "3", "1", "2", "--dry-run", "-o", "json")
+	if err == nil || !strings.Contains(err.Error(), "sprint-1 conflicted") {
+		t.Fatalf("Execute() error = %v, want conflict on sprint-1", err)
+	}
+
+	var got mergeResult
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, stdout)
+	}
+	if len(got.Branches) != 3 || got.Branches[0].Result != "merged" || got.Branches[2].Result != "skipped" {
+		t.Fatalf("branches = %+v, want merged, conflict, skipped", got.Branches)
+	}
+	if c := got.Branches[1]; c.Window != 1 || c.Result != "conflict" || len(c.Conflicts) != 1 || c.Conflicts[0] != "a.txt" {
+		t.Errorf("conflict = %+v, want window 1 conflicting in a.txt", c)
+	}
+	if strings.Contains(stderr, "conflicts with") {
+		t.Errorf("structured output should not print the conflict report: %q", stderr)
+	}
+	if after := gitOutput(t, repo, "rev-parse", "main"); after != before || got.After != before {
+		t.Errorf("dry run moved main from %s to %s", before, after)
+	}
+
+	_, stderr, _ = runMerge(t, storeDir, "sprint", "3", "1")
+	if !strings.Contains(stderr, "Branch sprint-1 (window 1) conflicts with main in:\n  a.txt") {
+		t.Errorf("stderr = %q, want conflict report", stderr)
+	}
+	if after := gitOutput(t, repo, "rev-parse", "main"); after == before {
+		t.Error("main not advanced over the branch merged before the conflict")
+	}
+}
+
+func TestMergeErrors(t *testing.T) {
+	storeDir := t.TempDir()
+	saveMergeSession(t, storeDir, map[string]string{"a.txt": ""}, "a.txt")
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "squash and rebase", args: []string{"sprint", "--squash", "--rebase"}, wantErr: "--squash cannot be combined with --rebase"},
+		{name: "window out of range", args: []string{"sprint", "2"}, wantErr: `invalid window "2"`},
+		{name: "repeated window", args: []string{"sprint", "1", "1"}, wantErr: "window 1 given more than once"},
+		{name: "missing target", args: []string{"sprint", "--into", "release"}, wantErr: `target branch "release" does not exist`},
+		{name: "missing session", args: []string{"nope"}, wantErr: "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := runMerge(t, storeDir, tt.args...); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Execute() error = %v, want to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
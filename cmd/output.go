@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// outputOptions holds the --output and --template flags of commands that can
+// print machine-readable results instead of prose.
+type outputOptions struct {
+	format   string
+	template string
+}
+
+func (o *outputOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.format, "output", "o", outputTable, "Output format: table, json, yaml")
+	cmd.Flags().StringVar(&o.template, "template", "", "Go template applied to each result, e.g. '{{.Name}} {{.Status}}' (overrides --output)")
+}
+
+func (o *outputOptions) validate() error {
+	switch o.format {
+	case outputTable, outputJSON, outputYAML:
+	default:
+		return fmt.Errorf("unsupported output format %q (supported: table, json, yaml)", o.format)
+	}
+	if o.template != "" {
+		if _, err := o.parseTemplate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// structured reports whether results should be printed as data rather than
+// the human-readable table or message.
+func (o *outputOptions) structured() bool {
+	return o.template != "" || o.format != outputTable
+}
+
+func (o *outputOptions) parseTemplate() (*template.Template, error) {
+	tmpl, err := template.New("output").Parse(o.template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// printResult writes a single result in the selected structured format.
+func (o *outputOptions) printResult(w io.Writer, v any) error {
+	if o.template != "" {
+		tmpl, err := o.parseTemplate()
+		if err != nil {
+			return err
+		}
+		if err := tmpl.Execute(w, v); err != nil {
+			return fmt.Errorf("failed to execute template: %w", err)
+		}
+		_, err = fmt.Fprintln(w)
+		return err
+	}
+	return o.encode(w, v)
+}
+
+// printResults writes a list of results. JSON and YAML encode the whole list;
+// a template is applied to each item on its own line.
+func printResults[T any](o *outputOptions, w io.Writer, items []T) error {
+	if items == nil {
+		items = []T{}
+	}
+	if o.template == "" {
+		return o.encode(w, items)
+	}
+	for _, item := range items {
+		if err := o.printResult(w, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *outputOptions) encode(w io.Writer, v any) error {
+	switch o.format {
+	case outputYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("failed to encode yaml: %w", err)
+		}
+		return enc.Close()
+	default:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("failed to encode json: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/riricardoMa/claude-grid/internal/git"
+	"github.com/riricardoMa/claude-grid/internal/hooks"
+	"github.com/riricardoMa/claude-grid/internal/session"
+	"github.com/spf13/cobra"
+)
+
+// pickResult is the machine-readable outcome of pick.
+type pickResult struct {
+	Session          string   `json:"session" yaml:"session"`
+	Window           int      `json:"window" yaml:"window"`
+	Branch           string   `json:"branch" yaml:"branch"`
+	Path             string   `json:"path" yaml:"path"`
+	WorktreesRemoved []string `json:"worktrees_removed" yaml:"worktrees_removed"`
+	BranchesDeleted  []string `json:"branches_deleted" yaml:"branches_deleted"`
+	Warnings         []string `json:"warnings" yaml:"warnings"`
+	Errors           []string `json:"errors" yaml:"errors"`
+}
+
+func NewPickCmd(storePath string) *cobra.Command {
+	var output outputOptions
+	var keepBranches bool
+
+	cmd := &cobra.Command{
+		Use:   "pick <session-name> <window>",
+		Short: "Keep one worktree of a session and discard the others",
+		Long: `Keep the worktree and branch of one window of a stopped session, typically the
+best ranked by 'claude-grid verify', and discard the others: their worktrees
+are removed and the branches the session created for them are deleted, unless
+--keep-branches is given. Branches continued with --branch are never deleted.
+
+The session is left with the kept worktree as its only window, numbered 1, so
+it can be resumed, diffed, merged or cleaned like any other.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionName := args[0]
+
+			if err := output.validate(); err != nil {
+				return err
+			}
+
+			store := session.NewStore(storePath)
+			sess, err := loadSession(store, sessionName, cmd.ErrOrStderr())
+			if err != nil {
+				return err
+			}
+			if len(sess.Worktrees) == 0 {
+				return fmt.Errorf("session '%s' has no worktrees to pick from", sessionName)
+			}
+			if sess.Status != "stopped" {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Session '%s' is still active. Run 'claude-grid kill %s' first.\n", sessionName, sessionName)
+				return fmt.Errorf("session '%s' is active", sessionName)
+			}
+
+			windows, err := worktreeWindows(sess, args[1:])
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				return err
+			}
+			picked := windows[0]
+			kept := sess.Worktrees[picked-1]
+
+			manager, err := git.NewManager(sess.RepoPath)
+			if err != nil {
+				return fmt.Errorf("failed to create git manager for %q: %w", sess.RepoPath, err)
+			}
+
+			result := pickResult{
+				Session:          sessionName,
+				Window:           picked,
+				Branch:           kept.Branch,
+				Path:             kept.Path,
+				WorktreesRemoved: []string{},
+				BranchesDeleted:  []string{},
+				Warnings:         []string{},
+				Errors:           []string{},
+			}
+
+			for i, wt := range sess.Worktrees {
+				if i == picked-1 {
+					continue
+				}
+				// A pick that failed part-way leaves the session listing
+				// worktrees it already removed; their branches are still
+				// deleted on the retry.
+				if _, err := os.Stat(wt.Path); !errors.Is(err, os.ErrNotExist) {
+					if sess.Hooks {
+						if err := hooks.Uninstall(wt.Path); err != nil {
+							result.Warnings = append(result.Warnings, fmt.Sprintf("failed to remove hooks from %q: %v", wt.Path, err))
+						}
+					}
+					if status, err := git.Status(wt.Path); err == nil && status.Uncommitted > 0 {
+						result.Warnings = append(result.Warnings, fmt.Sprintf("worktree %q (%s) had %d uncommitted files", wt.Path, wt.Branch, status.Uncommitted))
+					}
+
+					if err := manager.RemoveWorktree(wt.Path); err != nil {
+						result.Errors = append(result.Errors, err.Error())
+						continue
+					}
+					result.WorktreesRemoved = append(result.WorktreesRemoved, wt.Path)
+				}
+
+				if wt.Existing || keepBranches || !manager.BranchExists(wt.Branch) {
+					continue
+				}
+				if err := manager.DeleteBranch(wt.Branch); err != nil {
+					result.Errors = append(result.Errors, err.Error())
+				} else {
+					result.BranchesDeleted = append(result.BranchesDeleted, wt.Branch)
+				}
+			}
+
+			if len(result.Errors) == 0 {
+				if err := keepWindow(store, sessionName, picked); err != nil {
+					result.Errors = append(result.Errors, fmt.Sprintf("failed to update session: %v", err))
+				}
+			}
+			if len(result.Errors) == 0 && sess.Hooks {
+				// Hooks report window numbers, and the kept window is now 1.
+				if exe, err := os.Executable(); err != nil {
+					result.Warnings = append(result.Warnings, fmt.Sprintf("failed to reinstall hooks: %v", err))
+				} else if err := hooks.Install(kept.Path, hooks.Command(exe, sessionName, 1)); err != nil {
+					result.Warnings = append(result.Warnings, fmt.Sprintf("failed to reinstall hooks: %v", err))
+				}
+				if err := hooks.NewLog(storePath).Remove(sessionName); err != nil {
+					result.Warnings = append(result.Warnings, err.Error())
+				}
+			}
+
+			for _, e := range result.Errors {
+				logEvent(store, sessionName, session.LogEntry{Event: session.LogError, Message: e}, cmd.ErrOrStderr())
+			}
+			if len(result.Errors) == 0 {
+				logEvent(store, sessionName, session.LogEntry{Event: session.LogPick, Window: picked, Message: fmt.Sprintf("kept branch %s as window 1, removed %d worktrees", kept.Branch, len(result.WorktreesRemoved))}, cmd.ErrOrStderr())
+			}
+
+			if output.structured() {
+				if err := output.printResult(cmd.OutOrStdout(), result); err != nil {
+					return err
+				}
+			} else {
+				for _, w := range result.Warnings {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", w)
+				}
+				for _, e := range result.Errors {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Error: %s\n", e)
+				}
+				if len(result.Errors) == 0 {
+					fmt.Fprintf(cmd.OutOrStdout(), "Kept window %d (%s) in %s. %d worktrees removed, %d branches deleted.\n", picked, kept.Branch, displayPath(kept.Path), len(result.WorktreesRemoved), len(result.BranchesDeleted))
+				}
+			}
+
+			if len(result.Errors) > 0 {
+				return fmt.Errorf("pick completed with errors; the session still lists every window, so rerun it to finish removing the others: %s", strings.Join(result.Errors, "; "))
+			}
+			return nil
+		},
+	}
+
+	output.addFlags(cmd)
+	cmd.Flags().BoolVar(&keepBranches, "keep-branches", false, "Keep the branches of the discarded worktrees")
+
+	return cmd
+}
+
+// keepWindow reduces session name to window picked, numbered from 1, which
+// becomes its window 1. Tasks dispatched to the discarded windows are dropped;
+// pending tasks stay queued for the kept window.
+func keepWindow(store *session.Store, name string, picked int) error {
+	return store.ModifySession(name, func(s *session.Session) error {
+		i := picked - 1
+		if i >= len(s.Worktrees) {
+			return fmt.Errorf("session has no window %d", picked)
+		}
+		s.Worktrees = []session.WorktreeRef{s.Worktrees[i]}
+		s.Count = 1
+
+		windows := []session.WindowRef{}
+		for _, w := range s.Windows {
+			if w.Index == i {
+				w.Index = 0
+				windows = append(windows, w)
+			}
+		}
+		s.Windows = windows
+		if i < len(s.Dirs) {
+			s.Dirs = []string{s.Dirs[i]}
+		}
+		if i < len(s.Prompts) {
+			s.Prompts = []string{s.Prompts[i]}
+		}
+
+		var verifications []session.Verification
+		for _, v := range s.Verifications {
+			if v.Window == picked {
+				v.Window, v.Rank = 1, 1
+				verifications = append(verifications, v)
+			}
+		}
+		s.Verifications = verifications
+
+		var tasks []session.Task
+		for _, t := range s.Tasks {
+			if t.Window == picked {
+				t.Window = 1
+			} else if t.Window != 0 {
+				continue
+			}
+			tasks = append(tasks, t)
+		}
+		s.Tasks = tasks
+		return nil
+	})
+}
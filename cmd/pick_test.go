@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/riricardoMa/claude-grid/internal/session"
+)
+
+func runPick(t *testing.T, storeDir string, args ...string) (string, string, error) {
+	t.Helper()
+	cmd := NewPickCmd(storeDir)
+	cmd.SilenceUsage = true
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+func TestPickKeepsOneWorktree(t *testing.T) {
+	storeDir := t.TempDir()
+	repo := saveMergeSession(t, storeDir, map[string]string{"a.txt": ""}, "a.txt", "a.txt", "a.txt")
+	runGit(t, repo, "branch", "sprint-continued", "sprint-3")
+
+	store := session.NewStore(storeDir)
+	err := store.ModifySession("sprint", func(s *session.Session) error {
+		s.Windows = []session.WindowRef{{ID: "1", Index: 0}, {ID: "2", Index: 1, ConversationID: "conv-2"}, {ID: "3", Index: 2}}
+		s.Prompts = []string{"fix it", "fix it", "fix it"}
+		s.Worktrees[2].Existing = true
+		s.Verifications = []session.Verification{{Window: 1, Rank: 2}, {Window: 2, Rank: 1, Passed: true, FinishedAt: time.Now()}, {Window: 3, Rank: 3}}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ModifySession() error = %v", err)
+	}
+	before, _ := store.LoadSession("sprint")
+
+	stdout, stderr, err := runPick(t, storeDir, "sprint", "2", "-o", "json")
+	if err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, stderr)
+	}
+	var got pickResult
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, stdout)
+	}
+	if got.Branch != "sprint-2" || len(got.WorktreesRemoved) != 2 || len(got.BranchesDeleted) != 1 || got.BranchesDeleted[0] != "sprint-1" {
+		t.Errorf("result = %+v, want sprint-2 kept and only the new branch sprint-1 deleted", got)
+	}
+	for _, i := range []int{0, 2} {
+		if _, err := os.Stat(before.Worktrees[i].Path); !os.IsNotExist(err) {
+			t.Errorf("worktree %s still exists", before.Worktrees[i].Path)
+		}
+	}
+	if branches := gitOutput(t, repo, "branch", "--list", "sprint-*"); strings.Contains(branches, "sprint-1") || !strings.Contains(branches, "sprint-3") {
+		t.Errorf("branches = %q, want sprint-1 deleted and the existing sprint-3 kept", branches)
+	}
+
+	sess, err := store.LoadSession("sprint")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if sess.Count != 1 || len(sess.Worktrees) != 1 || sess.Worktrees[0].Branch != "sprint-2" || len(sess.Prompts) != 1 {
+		t.Errorf("session = %+v, want only window 2 left", sess)
+	}
+	if len(sess.Windows) != 1 || sess.Windows[0].Index != 0 || sess.Windows[0].ConversationID != "conv-2" {
+		t.Errorf("windows = %+v, want window 2 renumbered 1", sess.Windows)
+	}
+	if len(sess.Verifications) != 1 || sess.Verifications[0].Window != 1 || !sess.Verifications[0].Passed {
+		t.Errorf("verifications = %+v, want the kept window's", sess.Verifications)
+	}
+}
+
+func TestPickRenumbersTasks(t *testing.T) {
+	storeDir := t.TempDir()
+	saveMergeSession(t, storeDir, map[string]string{"a.txt": ""}, "a.txt", "a.txt")
+
+	store := session.NewStore(storeDir)
+	err := store.ModifySession("sprint", func(s *session.Session) error {
+		s.Tasks = []session.Task{
+			{Prompt: "one", State: session.TaskDone, Window: 1},
+			{Prompt: "two", State: session.TaskRunning, Window: 2},
+			{Prompt: "three", State: session.TaskRunning, Window: 1},
+			{Prompt: "four", State: session.TaskPending},
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ModifySession() error = %v", err)
+	}
+
+	if _, stderr, err := runPick(t, storeDir, "sprint", "2"); err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, stderr)
+	}
+
+	sess, err := store.LoadSession("sprint")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if len(sess.Tasks) != 2 || sess.Tasks[0].Prompt != "two" || sess.Tasks[1].Prompt != "four" {
+		t.Fatalf("tasks = %+v, want window 2's task and the pending one", sess.Tasks)
+	}
+	if i := sess.RunningTask(1); i != 0 {
+		t.Errorf("RunningTask(1) = %d, want window 2's task renumbered 1", i)
+	}
+	if sess.Tasks[1].Window != 0 || sess.NextPendingTask() != 1 {
+		t.Errorf("pending task = %+v, want it still queued", sess.Tasks[1])
+	}
+}
+
+func TestPickKeepBranches(t *testing.T) {
+	storeDir := t.TempDir()
+	repo := saveMergeSession(t, storeDir, map[string]string{"a.txt": ""}, "a.txt", "a.txt")
+
+	stdout, stderr, err := runPick(t, storeDir, "sprint", "1", "--keep-branches")
+	if err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, "Kept window 1 (sprint-1)") || !strings.Contains(stdout, "1 worktrees removed, 0 branches deleted") {
+		t.Errorf("stdout = %q", stdout)
+	}
+	if branches := gitOutput(t, repo, "branch", "--list", "sprint-2"); branches == "" {
+		t.Error("sprint-2 deleted despite --keep-branches")
+	}
+}
+
+func TestPickRetriesAfterPartialRemoval(t *testing.T) {
+	storeDir := t.TempDir()
+	repo := saveMergeSession(t, storeDir, map[string]string{"a.txt": ""}, "a.txt", "a.txt", "a.txt")
+	sess, err := session.NewStore(storeDir).LoadSession("sprint")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	// An earlier pick of window 2 removed window 1 before failing.
+	runGit(t, repo, "worktree", "remove", "--force", sess.Worktrees[0].Path)
+
+	stdout, stderr, err := runPick(t, storeDir, "sprint", "2")
+	if err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stdout, "1 worktrees removed, 2 branches deleted") {
+		t.Errorf("stdout = %q, want the remaining worktree removed and both branches deleted", stdout)
+	}
+	if _, err := os.Stat(sess.Worktrees[2].Path); !os.IsNotExist(err) {
+		t.Errorf("worktree %s still exists", sess.Worktrees[2].Path)
+	}
+}
+
+func TestPickErrors(t *testing.T) {
+	storeDir := t.TempDir()
+	saveMergeSession(t, storeDir, map[string]string{"a.txt": ""}, "a.txt", "a.txt")
+
+	if _, _, err := runPick(t, storeDir, "sprint", "3"); err == nil || !strings.Contains(err.Error(), `invalid window "3"`) {
+		t.Errorf("Execute() error = %v, want invalid window", err)
+	}
+
+	store := session.NewStore(storeDir)
+	if err := store.ModifySession("sprint", func(s *session.Session) error { s.Status = "active"; return nil }); err != nil {
+		t.Fatalf("ModifySession() error = %v", err)
+	}
+	if _, stderr, err := runPick(t, storeDir, "sprint", "1"); err == nil || !strings.Contains(stderr, "Run 'claude-grid kill sprint' first") {
+		t.Errorf("Execute() error = %v, stderr = %q; want active session refused", err, stderr)
+	}
+}
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/riricardoMa/claude-grid/internal/agent"
+	"github.com/riricardoMa/claude-grid/internal/claude"
+	"github.com/riricardoMa/claude-grid/internal/grid"
+	"github.com/riricardoMa/claude-grid/internal/script"
+	"github.com/riricardoMa/claude-grid/internal/session"
+	"github.com/riricardoMa/claude-grid/internal/terminal"
+	"github.com/spf13/cobra"
+)
+
+func NewResumeCmd(storePath string, executor script.ScriptExecutor) *cobra.Command {
+	var freshFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "resume <session-name>",
+		Short: "Re-spawn windows for a stopped session",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionName := args[0]
+			stderr := cmd.ErrOrStderr()
+
+			store := session.NewStore(storePath)
+			sess, err := loadSession(store, sessionName, stderr)
+			if err != nil {
+				return err
+			}
+
+			if sess.Backend == headlessBackend {
+				fmt.Fprintf(stderr, "Session '%s' ran headless and has no windows to reopen. Spawn it again with --headless.\n", sessionName)
+				return fmt.Errorf("session '%s' ran headless", sessionName)
+			}
+
+			if sess.Status != "stopped" && checkSessionLiveness(cmd.Context(), executor, sess) {
+				fmt.Fprintf(stderr, "Session '%s' is still active. Run 'claude-grid kill %s' first.\n", sessionName, sessionName)
+				return fmt.Errorf("session '%s' is still active", sessionName)
+			}
+
+			dirs := sessionDirs(sess)
+			for _, d := range dirs {
+				if _, err := os.Stat(d); err != nil {
+					fmt.Fprintf(stderr, "directory does not exist: %s\n", d)
+					logEvent(store, sessionName, session.LogEntry{Event: session.LogError, Message: fmt.Sprintf("resume: directory does not exist: %s", d)}, stderr)
+					return fmt.Errorf("directory does not exist: %s", d)
+				}
+			}
+
+			gridLayout := grid.CalculateGrid(sess.Count)
+			if strings.TrimSpace(sess.Layout) != "" {
+				gridLayout, err = grid.ParseLayout(sess.Layout)
+				if err != nil {
+					return fmt.Errorf("parse stored layout: %w", err)
+				}
+			}
+
+			backend, err := backendForSession(sess.Backend, executor)
+			if err != nil {
+				return err
+			}
+
+			profile := sessionAgent(sess)
+			if _, err := profile.Validate(lookPath); err != nil {
+				fmt.Fprintln(stderr, err)
+				return fmt.Errorf("validate agent: %w", err)
+			}
+
+			// Windows that continue a conversation skip their initial prompt,
+			// which was already sent when the conversation started.
+			conversationIDs := make([]string, sess.Count)
+			prompts := make([]string, sess.Count)
+			copy(prompts, sess.Prompts)
+			if len(sess.Tasks) > 0 {
+				// Queued sessions re-send the task each window was running,
+				// not the one it started with.
+				for i := range prompts {
+					prompts[i] = ""
+					if t := sess.RunningTask(i + 1); t >= 0 {
+						prompts[i] = sess.Tasks[t].Prompt
+					}
+				}
+			}
+			if !freshFlag && profile.CanResume() {
+				recordConversations(claude.NewLocator(""), &sess)
+				for _, w := range sess.Windows {
+					if w.Index >= 0 && w.Index < sess.Count && w.ConversationID != "" {
+						conversationIDs[w.Index] = w.ConversationID
+						prompts[w.Index] = ""
+					}
+				}
+			}
+
+			screenInfo := detectScreenInfo(executor, stderr)
+			windows, err := backend.SpawnWindows(cmd.Context(), terminal.SpawnOptions{
+				Count:           sess.Count,
+				Agent:           profile,
+				Dir:             sess.Dir,
+				Dirs:            dirs,
+				Prompts:         prompts,
+				ConversationIDs: conversationIDs,
+				Grid:            gridLayout,
+				Screen:          screenInfo,
+				Bounds:          calculateBounds(gridLayout, screenInfo, sess.Count),
+				SessionID:       sessionName,
+			})
+			if err != nil {
+				fmt.Fprintf(stderr, "failed to spawn windows: %v\n", err)
+				logEvent(store, sessionName, session.LogEntry{Event: session.LogError, Message: fmt.Sprintf("resume: spawn windows: %v", err)}, stderr)
+				return fmt.Errorf("spawn windows: %w", err)
+			}
+
+			newWindows := make([]session.WindowRef, 0, len(windows))
+			for _, window := range windows {
+				ref := session.WindowRef{ID: window.ID, Index: window.Index}
+				if window.Index >= 0 && window.Index < len(conversationIDs) {
+					ref.ConversationID = conversationIDs[window.Index]
+				}
+				newWindows = append(newWindows, ref)
+			}
+
+			err = store.ModifySession(sessionName, func(s *session.Session) error {
+				s.Windows = newWindows
+				s.Status = "active"
+				return nil
+			})
+			if err != nil {
+				_ = backend.CloseSession(sessionName)
+				fmt.Fprintf(stderr, "failed to save session: %v\n", err)
+				logEvent(store, sessionName, session.LogEntry{Event: session.LogError, Message: fmt.Sprintf("resume: save session: %v", err)}, stderr)
+				return fmt.Errorf("save session: %w", err)
+			}
+
+			logEvent(store, sessionName, session.LogEntry{Event: session.LogResume, Message: fmt.Sprintf("%d windows reopened", len(windows))}, stderr)
+			logWindowStarts(store, sessionName, windows, dirs, stderr)
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Session '%s' resumed. %d windows opened.\n", sessionName, len(windows))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&freshFlag, "fresh", false, "Start new conversations instead of continuing previous ones")
+
+	return cmd
+}
+
+// recordConversations stores on each window the Claude Code conversation most
+// recently written in its directory since the session was created. Windows
+// sharing a directory keep IDs they already have and take the remaining
+// conversations newest first, so the mapping is only exact per directory.
+func recordConversations(locator *claude.Locator, sess *session.Session) {
+	dirs := sessionDirs(*sess)
+
+	var order []string
+	windowsByDir := make(map[string][]int)
+	for i, w := range sess.Windows {
+		if w.Index < 0 || w.Index >= len(dirs) {
+			continue
+		}
+		dir := dirs[w.Index]
+		if _, ok := windowsByDir[dir]; !ok {
+			order = append(order, dir)
+		}
+		windowsByDir[dir] = append(windowsByDir[dir], i)
+	}
+
+	for _, dir := range order {
+		conversations, err := locator.RecentConversations(dir, sess.CreatedAt)
+		if err != nil || len(conversations) == 0 {
+			continue
+		}
+
+		windows := windowsByDir[dir]
+		if len(windows) == 1 {
+			sess.Windows[windows[0]].ConversationID = conversations[0].ID
+			continue
+		}
+
+		claimed := make(map[string]bool)
+		for _, i := range windows {
+			if id := sess.Windows[i].ConversationID; id != "" {
+				claimed[id] = true
+			}
+		}
+
+		next := 0
+		for _, i := range windows {
+			if sess.Windows[i].ConversationID != "" {
+				continue
+			}
+			for next < len(conversations) && claimed[conversations[next].ID] {
+				next++
+			}
+			if next == len(conversations) {
+				break
+			}
+			sess.Windows[i].ConversationID = conversations[next].ID
+			claimed[conversations[next].ID] = true
+		}
+	}
+}
+
+// sessionAgent returns the agent a session was spawned with. Sessions saved
+// without one ran claude.
+func sessionAgent(sess session.Session) agent.Profile {
+	if sess.Agent.Command == "" {
+		return agent.Default()
+	}
+	return sess.Agent
+}
+
+// sessionDirs returns the per-window directories a session was spawned into,
+// preferring worktree paths over the original directories.
+func sessionDirs(sess session.Session) []string {
+	if len(sess.Worktrees) > 0 {
+		dirs := make([]string, len(sess.Worktrees))
+		for i, wt := range sess.Worktrees {
+			dirs[i] = wt.Path
+		}
+		return dirs
+	}
+
+	if len(sess.Dirs) > 0 {
+		return sess.Dirs
+	}
+
+	dirs := make([]string, sess.Count)
+	for i := range dirs {
+		dirs[i] = sess.Dir
+	}
+	return dirs
+}
@@ -0,0 +1,329 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/riricardoMa/claude-grid/internal/agent"
+	"github.com/riricardoMa/claude-grid/internal/claude"
+	"github.com/riricardoMa/claude-grid/internal/session"
+)
+
+type stubExecutor struct {
+	output  string
+	scripts []string
+}
+
+func (s *stubExecutor) RunAppleScript(ctx context.Context, script string) (string, error) {
+	s.scripts = append(s.scripts, script)
+	return s.output, nil
+}
+
+// stubLookPath makes every agent executable resolvable for the test.
+func stubLookPath(t *testing.T) {
+	t.Helper()
+	original := lookPath
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	t.Cleanup(func() { lookPath = original })
+}
+
+func runResume(t *testing.T, storeDir string, executor *stubExecutor, name string) (string, string, error) {
+	t.Helper()
+	stubLookPath(t)
+	cmd := NewResumeCmd(storeDir, executor)
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{name})
+	err := cmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+func TestResumeSessionNotFound(t *testing.T) {
+	_, stderr, err := runResume(t, t.TempDir(), &stubExecutor{}, "missing")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(stderr, "not found") {
+		t.Errorf("stderr = %q, want to contain 'not found'", stderr)
+	}
+}
+
+func TestResumeActiveSessionRejected(t *testing.T) {
+	storeDir := t.TempDir()
+	store := session.NewStore(storeDir)
+	if err := store.SaveSession(session.Session{
+		Name:    "live",
+		Backend: "terminal",
+		Count:   1,
+		Dir:     t.TempDir(),
+		Windows: []session.WindowRef{{ID: "42", Index: 0}},
+	}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	_, stderr, err := runResume(t, storeDir, &stubExecutor{output: "42, 43"}, "live")
+	if err == nil {
+		t.Fatal("expected error for live session, got nil")
+	}
+	if !strings.Contains(stderr, "still active") {
+		t.Errorf("stderr = %q, want to contain 'still active'", stderr)
+	}
+}
+
+func TestResumeMissingWorktree(t *testing.T) {
+	storeDir := t.TempDir()
+	store := session.NewStore(storeDir)
+	if err := store.SaveSession(session.Session{
+		Name:      "gone",
+		Backend:   "terminal",
+		Count:     1,
+		Status:    "stopped",
+		Worktrees: []session.WorktreeRef{{Path: "/nonexistent/worktree/xyz", Branch: "b-1"}},
+	}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	_, stderr, err := runResume(t, storeDir, &stubExecutor{}, "gone")
+	if err == nil {
+		t.Fatal("expected error for missing worktree, got nil")
+	}
+	if !strings.Contains(stderr, "directory does not exist") {
+		t.Errorf("stderr = %q, want to contain 'directory does not exist'", stderr)
+	}
+}
+
+func TestResumeStoppedSession(t *testing.T) {
+	t.Setenv("CLAUDE_CONFIG_DIR", t.TempDir())
+	storeDir := t.TempDir()
+	wt1, wt2 := t.TempDir(), t.TempDir()
+	store := session.NewStore(storeDir)
+	if err := store.SaveSession(session.Session{
+		Name:      "sprint",
+		Backend:   "terminal",
+		Count:     2,
+		Dir:       "/repo",
+		Layout:    "2x1",
+		CreatedAt: time.Now(),
+		Status:    "stopped",
+		Prompts:   []string{"fix login", ""},
+		Windows:   []session.WindowRef{{ID: "1", Index: 0}, {ID: "2", Index: 1}},
+		Worktrees: []session.WorktreeRef{{Path: wt1, Branch: "b-1"}, {Path: wt2, Branch: "b-2"}},
+	}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	executor := &stubExecutor{output: "201,202"}
+	stdout, stderr, err := runResume(t, storeDir, executor, "sprint")
+	if err != nil {
+		t.Fatalf("Execute() error = %v, stderr = %q", err, stderr)
+	}
+	if !strings.Contains(stdout, "resumed") {
+		t.Errorf("stdout = %q, want to contain 'resumed'", stdout)
+	}
+
+	spawnScript := executor.scripts[len(executor.scripts)-1]
+	for _, want := range []string{wt1, wt2, "fix login"} {
+		if !strings.Contains(spawnScript, want) {
+			t.Errorf("spawn script missing %q:\n%s", want, spawnScript)
+		}
+	}
+
+	got, err := store.LoadSession("sprint")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if got.Status != "active" {
+		t.Errorf("Status = %q, want %q", got.Status, "active")
+	}
+	if len(got.Windows) != 2 || got.Windows[0].ID != "201" || got.Windows[1].ID != "202" {
+		t.Errorf("Windows = %+v, want IDs 201, 202", got.Windows)
+	}
+}
+
+func TestResumeUsesStoredAgent(t *testing.T) {
+	t.Setenv("CLAUDE_CONFIG_DIR", t.TempDir())
+	storeDir := t.TempDir()
+	dir := t.TempDir()
+	writeTranscript(t, dir, "1205b5d1-2d9e-4429-8617-4c7087ce0bf6")
+
+	store := session.NewStore(storeDir)
+	if err := store.SaveSession(session.Session{
+		Name:      "compare",
+		Backend:   "terminal",
+		Count:     1,
+		Dir:       dir,
+		CreatedAt: time.Now().Add(-time.Hour),
+		Status:    "stopped",
+		Prompts:   []string{"fix login"},
+		Windows:   []session.WindowRef{{ID: "1", Index: 0}},
+		Agent:     agent.Profile{Name: "aider", Command: "aider", PromptMode: agent.PromptFlag, PromptFlag: "--message"},
+	}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	executor := &stubExecutor{output: "201"}
+	if _, stderr, err := runResume(t, storeDir, executor, "compare"); err != nil {
+		t.Fatalf("Execute() error = %v, stderr = %q", err, stderr)
+	}
+
+	// aider cannot continue Claude conversations, so the prompt is re-sent.
+	spawnScript := executor.scripts[len(executor.scripts)-1]
+	if !strings.Contains(spawnScript, "aider --message 'fix login'") || strings.Contains(spawnScript, "--resume") {
+		t.Errorf("spawn script should re-run aider with its prompt:\n%s", spawnScript)
+	}
+}
+
+func writeTranscript(t *testing.T, dir, id string) {
+	t.Helper()
+	projectDir := claude.NewLocator("").ProjectDir(dir)
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, id+".jsonl"), []byte("{}\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+func TestResumeContinuesConversations(t *testing.T) {
+	t.Setenv("CLAUDE_CONFIG_DIR", t.TempDir())
+	storeDir := t.TempDir()
+	wt1, wt2 := t.TempDir(), t.TempDir()
+	conversationID := "1205b5d1-2d9e-4429-8617-4c7087ce0bf6"
+	writeTranscript(t, wt1, conversationID)
+
+	store := session.NewStore(storeDir)
+	saved := session.Session{
+		Name:      "sprint",
+		Backend:   "terminal",
+		Count:     2,
+		CreatedAt: time.Now().Add(-time.Hour),
+		Status:    "stopped",
+		Prompts:   []string{"fix login", "add tests"},
+		Windows:   []session.WindowRef{{ID: "1", Index: 0}, {ID: "2", Index: 1}},
+		Worktrees: []session.WorktreeRef{{Path: wt1, Branch: "b-1"}, {Path: wt2, Branch: "b-2"}},
+	}
+	if err := store.SaveSession(saved); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	t.Run("continues recorded conversation", func(t *testing.T) {
+		executor := &stubExecutor{output: "201,202"}
+		if _, stderr, err := runResume(t, storeDir, executor, "sprint"); err != nil {
+			t.Fatalf("Execute() error = %v, stderr = %q", err, stderr)
+		}
+
+		spawnScript := executor.scripts[len(executor.scripts)-1]
+		if !strings.Contains(spawnScript, "claude --resume "+conversationID) {
+			t.Errorf("spawn script missing resume flag:\n%s", spawnScript)
+		}
+		if strings.Contains(spawnScript, "fix login") {
+			t.Errorf("resumed window should not resend its prompt:\n%s", spawnScript)
+		}
+		if !strings.Contains(spawnScript, "add tests") {
+			t.Errorf("window without conversation should keep its prompt:\n%s", spawnScript)
+		}
+
+		got, err := store.LoadSession("sprint")
+		if err != nil {
+			t.Fatalf("LoadSession() error = %v", err)
+		}
+		if got.Windows[0].ConversationID != conversationID || got.Windows[1].ConversationID != "" {
+			t.Errorf("conversation IDs = [%q, %q], want [%q, \"\"]", got.Windows[0].ConversationID, got.Windows[1].ConversationID, conversationID)
+		}
+	})
+
+	t.Run("fresh starts new conversations", func(t *testing.T) {
+		if err := store.UpdateSession(saved); err != nil {
+			t.Fatalf("UpdateSession() error = %v", err)
+		}
+		executor := &stubExecutor{output: "301,302"}
+		stubLookPath(t)
+		cmd := NewResumeCmd(storeDir, executor)
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"sprint", "--fresh"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+
+		spawnScript := executor.scripts[len(executor.scripts)-1]
+		if strings.Contains(spawnScript, "--resume") {
+			t.Errorf("fresh resume should not pass the resume flag:\n%s", spawnScript)
+		}
+		if !strings.Contains(spawnScript, "fix login") {
+			t.Errorf("fresh resume should resend prompts:\n%s", spawnScript)
+		}
+	})
+}
+
+func TestRecordConversationsSharedDir(t *testing.T) {
+	t.Setenv("CLAUDE_CONFIG_DIR", t.TempDir())
+	dir := t.TempDir()
+	existing := "11111111-1111-1111-1111-111111111111"
+	other := "22222222-2222-2222-2222-222222222222"
+	writeTranscript(t, dir, existing)
+	writeTranscript(t, dir, other)
+
+	sess := session.Session{
+		Count:   2,
+		Dir:     dir,
+		Windows: []session.WindowRef{{ID: "1", Index: 0}, {ID: "2", Index: 1, ConversationID: existing}},
+	}
+	recordConversations(claude.NewLocator(""), &sess)
+
+	if sess.Windows[1].ConversationID != existing {
+		t.Errorf("window 1 conversation = %q, want it kept as %q", sess.Windows[1].ConversationID, existing)
+	}
+	if sess.Windows[0].ConversationID != other {
+		t.Errorf("window 0 conversation = %q, want unclaimed %q", sess.Windows[0].ConversationID, other)
+	}
+}
+
+func TestResumeNewerSchemaSession(t *testing.T) {
+	storeDir := t.TempDir()
+	sessionsDir := filepath.Join(storeDir, "sessions")
+	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	data := []byte(`{"schema_version": 99, "name": "future"}`)
+	if err := os.WriteFile(filepath.Join(sessionsDir, "future.json"), data, 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	_, stderr, err := runResume(t, storeDir, &stubExecutor{}, "future")
+	if err == nil {
+		t.Fatal("expected error for newer schema, got nil")
+	}
+	if !strings.Contains(stderr, "newer claude-grid") || strings.Contains(stderr, "not found") {
+		t.Errorf("stderr = %q, want newer-version message", stderr)
+	}
+}
+
+func TestResumeCorruptSession(t *testing.T) {
+	storeDir := t.TempDir()
+	sessionsDir := filepath.Join(storeDir, "sessions")
+	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sessionsDir, "broken.json"), []byte(`{"name": `), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	_, stderr, err := runResume(t, storeDir, &stubExecutor{}, "broken")
+	if err == nil || !strings.Contains(err.Error(), "unmarshal") {
+		t.Errorf("Execute() error = %v, want the unmarshal error", err)
+	}
+	if !strings.Contains(stderr, "could not be loaded") || strings.Contains(stderr, "not found") {
+		t.Errorf("stderr = %q, want load failure message", stderr)
+	}
+
+	if _, stderr, err := runResume(t, storeDir, &stubExecutor{}, "missing"); err == nil || !strings.Contains(stderr, "not found") {
+		t.Errorf("Execute() error = %v, stderr = %q; want missing session", err, stderr)
+	}
+}
@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -10,28 +13,51 @@ import (
 	"strings"
 	"time"
 
+	"github.com/riricardoMa/claude-grid/internal/agent"
+	"github.com/riricardoMa/claude-grid/internal/bootstrap"
+	"github.com/riricardoMa/claude-grid/internal/config"
 	"github.com/riricardoMa/claude-grid/internal/git"
 	"github.com/riricardoMa/claude-grid/internal/grid"
+	"github.com/riricardoMa/claude-grid/internal/hooks"
 	"github.com/riricardoMa/claude-grid/internal/manifest"
 	"github.com/riricardoMa/claude-grid/internal/pathutil"
+	"github.com/riricardoMa/claude-grid/internal/platform"
 	"github.com/riricardoMa/claude-grid/internal/screen"
 	"github.com/riricardoMa/claude-grid/internal/script"
 	"github.com/riricardoMa/claude-grid/internal/session"
 	"github.com/riricardoMa/claude-grid/internal/terminal"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
+// lookPath resolves agent executables; tests replace it so they do not depend
+// on which agents are installed.
+var lookPath = exec.LookPath
+
 // NewRootCommand creates and returns the root cobra command.
 func NewRootCommand(version, commit, date string) *cobra.Command {
 	var (
 		terminalFlag     string
 		dirFlags         []string
 		promptFlags      []string
+		queueFlag        string
 		manifestFlag     string
 		nameFlag         string
 		layoutFlag       string
 		worktreesFlag    bool
+		hooksFlag        bool
+		noBootstrapFlag  bool
+		headlessFlag     bool
+		bestOfFlag       int
+		verifyFlag       string
 		branchPrefixFlag string
+		baseFlag         string
+		branchFlags      []string
+		agentFlag        string
+		commandFlag      string
+		promptModeFlag   string
+		promptFlagFlag   string
+		profileFlag      string
 	)
 
 	cmd := &cobra.Command{
@@ -53,10 +79,89 @@ func NewRootCommand(version, commit, date string) *cobra.Command {
 
 			// Manifest conflict detection
 			if manifestFlag != "" {
-				if len(dirFlags) > 0 || len(promptFlags) > 0 || worktreesFlag || len(args) > 0 {
-					fmt.Fprintln(stderr, "--manifest cannot be combined with --dir, --prompt, --worktrees, or count argument")
+				if len(dirFlags) > 0 || len(promptFlags) > 0 || queueFlag != "" || worktreesFlag || len(args) > 0 {
+					fmt.Fprintln(stderr, "--manifest cannot be combined with --dir, --prompt, --queue, --worktrees, or count argument")
+					return fmt.Errorf("conflicting flags")
+				}
+			}
+			if queueFlag != "" && len(promptFlags) > 0 {
+				fmt.Fprintln(stderr, "--queue cannot be combined with --prompt")
+				return fmt.Errorf("conflicting flags")
+			}
+			if bestOfFlag != 0 {
+				if len(args) > 0 || len(dirFlags) > 0 || manifestFlag != "" || queueFlag != "" || len(branchFlags) > 0 {
+					fmt.Fprintln(stderr, "--best-of cannot be combined with --dir, --manifest, --queue, --branch, or count argument")
 					return fmt.Errorf("conflicting flags")
 				}
+				if bestOfFlag < 2 || bestOfFlag > 16 {
+					fmt.Fprintf(stderr, "invalid --best-of %d: must be between 2 and 16\n", bestOfFlag)
+					return fmt.Errorf("invalid count")
+				}
+				if len(promptFlags) != 1 {
+					fmt.Fprintln(stderr, "--best-of needs exactly one --prompt, which every instance is given")
+					return fmt.Errorf("invalid arguments")
+				}
+			}
+			if headlessFlag && (queueFlag != "" || cmd.Flags().Changed("terminal") || cmd.Flags().Changed("layout")) {
+				fmt.Fprintln(stderr, "--headless cannot be combined with --queue, --terminal, or --layout")
+				return fmt.Errorf("conflicting flags")
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				fmt.Fprintf(stderr, "failed to determine current directory: %v\n", err)
+				return fmt.Errorf("get working directory: %w", err)
+			}
+
+			// Config files, profile and environment fill in flags not given
+			// on the command line.
+			cfg, err := config.Load(config.GlobalPath(), cwd)
+			if err != nil {
+				fmt.Fprintf(stderr, "failed to load config: %v\n", err)
+				return fmt.Errorf("load config: %w", err)
+			}
+			warnIgnoredSettings(stderr, cfg)
+			settings, err := cfg.Resolve(profileFlag, os.Getenv)
+			if err != nil {
+				fmt.Fprintf(stderr, "invalid configuration: %v\n", err)
+				return fmt.Errorf("resolve config: %w", err)
+			}
+			applySettings(cmd.Flags(), []settingDefault{
+				{"terminal", &terminalFlag, settings.Terminal},
+				{"layout", &layoutFlag, settings.Layout},
+				{"branch-prefix", &branchPrefixFlag, settings.BranchPrefix},
+				{"base", &baseFlag, settings.Base},
+			})
+			// The agent and the command line running it are one setting: an
+			// --agent or --command flag replaces all of the configured one.
+			if !cmd.Flags().Changed("agent") && !cmd.Flags().Changed("command") {
+				applySettings(cmd.Flags(), []settingDefault{
+					{"agent", &agentFlag, settings.Agent},
+					{"command", &commandFlag, settings.Command},
+					{"prompt-mode", &promptModeFlag, settings.PromptMode},
+					{"prompt-flag", &promptFlagFlag, settings.PromptFlag},
+				})
+			}
+			if manifestFlag == "" && !cmd.Flags().Changed("worktrees") && settings.Worktrees != nil {
+				worktreesFlag = *settings.Worktrees
+			}
+			if bestOfFlag != 0 {
+				worktreesFlag = true
+			}
+			if !cmd.Flags().Changed("hooks") && settings.Hooks != nil {
+				hooksFlag = *settings.Hooks && worktreesFlag
+			}
+			if hooksFlag && !worktreesFlag {
+				fmt.Fprintln(stderr, "--hooks requires --worktrees")
+				return fmt.Errorf("conflicting flags")
+			}
+			if (cmd.Flags().Changed("base") || len(branchFlags) > 0) && !worktreesFlag {
+				fmt.Fprintln(stderr, "--base and --branch require --worktrees")
+				return fmt.Errorf("conflicting flags")
+			}
+			if strings.TrimSpace(verifyFlag) != "" && !worktreesFlag {
+				fmt.Fprintln(stderr, "--verify requires --worktrees or --best-of")
+				return fmt.Errorf("conflicting flags")
 			}
 
 			// Count determination
@@ -81,6 +186,8 @@ func NewRootCommand(version, commit, date string) *cobra.Command {
 				}
 				parsedManifest = m
 				count = len(parsedManifest.Instances)
+			} else if bestOfFlag != 0 {
+				count = bestOfFlag
 			} else if len(args) == 1 {
 				c, err := strconv.Atoi(strings.TrimSpace(args[0]))
 				if err != nil {
@@ -94,6 +201,10 @@ func NewRootCommand(version, commit, date string) *cobra.Command {
 				count = c
 			} else if len(args) == 0 && len(dirFlags) > 0 {
 				count = len(dirFlags)
+			} else if len(args) == 0 && len(branchFlags) > 0 {
+				count = len(branchFlags)
+			} else if len(args) == 0 && settings.Count > 0 {
+				count = settings.Count
 			} else {
 				fmt.Fprintln(stderr, "count argument is required: claude-grid <count>")
 				return fmt.Errorf("invalid arguments")
@@ -105,12 +216,6 @@ func NewRootCommand(version, commit, date string) *cobra.Command {
 				return fmt.Errorf("invalid count")
 			}
 
-			cwd, err := os.Getwd()
-			if err != nil {
-				fmt.Fprintf(stderr, "failed to determine current directory: %v\n", err)
-				return fmt.Errorf("get working directory: %w", err)
-			}
-
 			// Dir resolution
 			var resolvedDirs []string
 			var resolvedPrompts []string
@@ -156,13 +261,34 @@ func NewRootCommand(version, commit, date string) *cobra.Command {
 			}
 
 			// Prompt resolution
-			if manifestFlag == "" {
+			var queuedTasks []string
+			if queueFlag != "" {
+				expandedQueuePath, err := pathutil.ExpandTilde(queueFlag)
+				if err != nil {
+					fmt.Fprintf(stderr, "invalid queue path %q: %v\n", queueFlag, err)
+					return fmt.Errorf("invalid queue path: %w", err)
+				}
+				queuedTasks, err = readTaskFile(expandedQueuePath)
+				if err != nil {
+					fmt.Fprintf(stderr, "failed to read task queue %q: %v\n", queueFlag, err)
+					return fmt.Errorf("read task queue: %w", err)
+				}
+				// The first tasks start with the windows; dispatch sends the rest.
+				resolvedPrompts = make([]string, count)
+				copy(resolvedPrompts, queuedTasks)
+			} else if manifestFlag == "" {
 				resolvedPrompts = make([]string, count)
 				if len(promptFlags) > count {
 					fmt.Fprintf(stderr, "more --prompt flags (%d) than instances (%d)\n", len(promptFlags), count)
 					return fmt.Errorf("too many prompts")
 				}
 				copy(resolvedPrompts, promptFlags)
+				if bestOfFlag != 0 {
+					// Best-of-N gives every instance the same prompt.
+					for i := range resolvedPrompts {
+						resolvedPrompts[i] = promptFlags[0]
+					}
+				}
 			}
 
 			// Directory existence validation
@@ -175,25 +301,51 @@ func NewRootCommand(version, commit, date string) *cobra.Command {
 
 			resolvedDir := resolvedDirs[0]
 
+			store := session.NewStore("")
+			sessionName := strings.TrimSpace(nameFlag)
+			if sessionName == "" {
+				sessionName = store.GenerateSessionName()
+				defer store.ReleaseSessionName(sessionName)
+			}
+
 			// Branch checkout for manifest instances
 			if manifestFlag != "" {
 				for i, inst := range parsedManifest.Instances {
 					if inst.Branch == "" {
 						continue
 					}
-					checkoutCmd := exec.CommandContext(cmd.Context(), "git", "-C", resolvedDirs[i], "checkout", inst.Branch)
-					if out, err := checkoutCmd.CombinedOutput(); err != nil {
-						fmt.Fprintf(stderr, "failed to checkout branch %q in %s: %v\n%s", inst.Branch, resolvedDirs[i], err, string(out))
+					message, err := checkoutManifestBranch(cmd.Context(), resolvedDirs[i], inst)
+					if err != nil {
+						fmt.Fprintf(stderr, "failed to checkout branch %q in %s: %v\n", inst.Branch, resolvedDirs[i], err)
+						logEvent(store, sessionName, session.LogEntry{Event: session.LogError, Window: i + 1, Message: fmt.Sprintf("checkout branch %q in %s: %v", inst.Branch, resolvedDirs[i], err)}, stderr)
 						return fmt.Errorf("checkout branch %q in %s: %w", inst.Branch, resolvedDirs[i], err)
 					}
+					logEvent(store, sessionName, session.LogEntry{Event: session.LogCheckout, Window: i + 1, Message: message}, stderr)
 				}
 			}
 
-			claudePath, err := exec.LookPath("claude")
+			profile, err := resolveAgent(agentFlag, commandFlag, promptModeFlag, promptFlagFlag)
+			if err != nil {
+				fmt.Fprintf(stderr, "invalid agent: %v\n", err)
+				return fmt.Errorf("resolve agent: %w", err)
+			}
+			agentPath, err := profile.Validate(lookPath)
 			if err != nil {
-				fmt.Fprintln(stderr, "'claude' not found in PATH. Install: npm install -g @anthropic-ai/claude-code")
-				fmt.Fprintln(stderr, "Or specify a different location (v0.2).")
-				return fmt.Errorf("claude not found")
+				fmt.Fprintln(stderr, err)
+				return fmt.Errorf("validate agent: %w", err)
+			}
+			if headlessFlag {
+				profile = profile.Headless()
+				if profile.AcceptsPrompt() && !hasEveryPrompt(resolvedPrompts) {
+					fmt.Fprintln(stderr, "warning: some instances have no prompt; headless agents without one may exit immediately")
+				}
+			}
+			if queueFlag != "" && !profile.AcceptsPrompt() {
+				fmt.Fprintf(stderr, "agent %q does not accept prompts and cannot run a task queue\n", profile.Name)
+				return fmt.Errorf("agent %q does not accept prompts", profile.Name)
+			}
+			if !profile.AcceptsPrompt() && hasAnyPrompt(resolvedPrompts) {
+				fmt.Fprintf(stderr, "warning: agent %q does not accept prompts; prompts are ignored\n", profile.Name)
 			}
 
 			var worktreeDirs []string
@@ -226,6 +378,27 @@ func NewRootCommand(version, commit, date string) *cobra.Command {
 					return fmt.Errorf("validate branch prefix: %w", err)
 				}
 
+				if len(branchFlags) > count {
+					fmt.Fprintf(stderr, "more --branch flags (%d) than instances (%d)\n", len(branchFlags), count)
+					return fmt.Errorf("too many branches")
+				}
+				for _, branch := range branchFlags {
+					if !manager.BranchExists(branch) {
+						fmt.Fprintf(stderr, "branch %q does not exist; --branch continues an existing branch\n", branch)
+						return fmt.Errorf("branch %q does not exist", branch)
+					}
+				}
+
+				base := strings.TrimSpace(baseFlag)
+				if base == "" {
+					base = "HEAD"
+				}
+				baseCommit, err := manager.ResolveRef(base)
+				if err != nil {
+					fmt.Fprintf(stderr, "invalid --base: %v\n", err)
+					return fmt.Errorf("resolve base: %w", err)
+				}
+
 				repoPath = manager.RepoPath()
 				worktreeDirs = make([]string, 0, count)
 				worktreeRefs = make([]session.WorktreeRef, 0, count)
@@ -239,25 +412,85 @@ func NewRootCommand(version, commit, date string) *cobra.Command {
 				}
 
 				for i := 0; i < count; i++ {
-					branch := fmt.Sprintf("%s-%d", prefix, i+1)
-					path, err := manager.CreateWorktree(branch)
+					// Windows given a --branch continue it; the others get a
+					// new branch from the base.
+					ref := session.WorktreeRef{Branch: fmt.Sprintf("%s-%d", prefix, i+1), Base: base, BaseCommit: baseCommit}
+					if i < len(branchFlags) {
+						ref = session.WorktreeRef{Branch: branchFlags[i], Existing: true}
+					}
+
+					var path string
+					if ref.Existing {
+						path, err = manager.CheckoutWorktree(ref.Branch)
+					} else {
+						path, err = manager.CreateWorktreeFrom(ref.Branch, baseCommit)
+					}
 					if err != nil {
-						fmt.Fprintf(stderr, "failed to create worktree for branch %q: %v\n", branch, err)
+						fmt.Fprintf(stderr, "failed to create worktree for branch %q: %v\n", ref.Branch, err)
+						logEvent(store, sessionName, session.LogEntry{Event: session.LogError, Window: i + 1, Message: fmt.Sprintf("create worktree for branch %q: %v", ref.Branch, err)}, stderr)
 						return fmt.Errorf("create worktree: %w", err)
 					}
+					message := fmt.Sprintf("created worktree %s on existing branch %s", path, ref.Branch)
+					if !ref.Existing {
+						message = fmt.Sprintf("created worktree %s on new branch %s from %s (%s)", path, ref.Branch, base, shortCommit(baseCommit))
+					}
+					logEvent(store, sessionName, session.LogEntry{Event: session.LogCheckout, Window: i + 1, Message: message}, stderr)
 
+					ref.Path = path
 					worktreeDirs = append(worktreeDirs, path)
-					worktreeRefs = append(worktreeRefs, session.WorktreeRef{Path: path, Branch: branch})
+					worktreeRefs = append(worktreeRefs, ref)
 				}
 			}
 
-			executor := script.NewOSAExecutor()
-			screenInfo, err := screen.DetectScreen(executor)
-			if err != nil {
-				fmt.Fprintf(stderr, "warning: failed to detect screen, using fallback 1920x1080: %v\n", err)
-				screenInfo = screen.ScreenInfo{X: 0, Y: 0, Width: 1920, Height: 1080}
+			if len(worktreeRefs) > 0 && cfg.Bootstrap.Enabled() && !noBootstrapFlag {
+				if steps := trustedBootstrap(cfg, config.TrustPath(), stderr); steps.Enabled() {
+					bootstrapWorktrees(cmd.Context(), store, sessionName, repoPath, steps, worktreeRefs, stdout, stderr)
+				}
 			}
 
+			installedHooks := false
+			if hooksFlag {
+				installedHooks, err = installHooks(profile, sessionName, worktreeRefs, stderr)
+				if err != nil {
+					fmt.Fprintf(stderr, "failed to install hooks: %v\n", err)
+					logEvent(store, sessionName, session.LogEntry{Event: session.LogError, Message: fmt.Sprintf("install hooks: %v", err)}, stderr)
+					return fmt.Errorf("install hooks: %w", err)
+				}
+			}
+
+			if headlessFlag {
+				sess := session.Session{
+					Name:      sessionName,
+					Backend:   headlessBackend,
+					Count:     count,
+					Dir:       resolvedDir,
+					Dirs:      resolvedDirs,
+					Prompts:   resolvedPrompts,
+					CreatedAt: time.Now(),
+					Status:    "active",
+					Agent:     profile,
+					Hooks:     installedHooks,
+					Verify:    strings.TrimSpace(verifyFlag),
+				}
+				if manifestFlag != "" {
+					sess.ManifestPath = manifestFlag
+				}
+				dirs := resolvedDirs
+				if len(worktreeRefs) > 0 {
+					sess.Worktrees = worktreeRefs
+					sess.RepoPath = repoPath
+					dirs = worktreeDirs
+				}
+
+				if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
+					fmt.Fprintf(stdout, "Verbose: agent=%s (%s) headless\n", profile.Name, agentPath)
+				}
+				fmt.Fprintf(stdout, "Running %d %s instances headless...\n", count, agentLabel(profile))
+				return runHeadless(cmd.Context(), store, sess, dirs, func() { spawnSucceeded = true }, stdout, stderr)
+			}
+
+			screenInfo := detectScreenInfo(script.NewOSAExecutor(), stderr)
+
 			var gridLayout grid.GridLayout
 			if strings.TrimSpace(layoutFlag) != "" {
 				gridLayout, err = grid.ParseLayout(layoutFlag)
@@ -269,12 +502,7 @@ func NewRootCommand(version, commit, date string) *cobra.Command {
 				gridLayout = grid.CalculateGrid(count)
 			}
 
-			bounds := grid.CalculateWindowBounds(gridLayout, grid.ScreenInfo{
-				X:      screenInfo.X,
-				Y:      screenInfo.Y,
-				Width:  screenInfo.Width,
-				Height: screenInfo.Height,
-			}, count)
+			bounds := calculateBounds(gridLayout, screenInfo, count)
 
 			minWidth := 0
 			minHeight := 0
@@ -293,18 +521,19 @@ func NewRootCommand(version, commit, date string) *cobra.Command {
 			backend, err := terminal.DetectBackend(terminalFlag)
 			if err != nil {
 				fmt.Fprintf(stderr, "failed to select terminal backend: %v\n", err)
-				fmt.Fprintln(stderr, "Try --terminal terminal or install Warp.")
+				if platform.HasAppleScript() {
+					fmt.Fprintln(stderr, "Try --terminal terminal or install Warp.")
+				} else {
+					fmt.Fprintln(stderr, "Try --terminal tmux.")
+				}
 				return fmt.Errorf("detect backend: %w", err)
 			}
 
-			store := session.NewStore("")
-			sessionName := strings.TrimSpace(nameFlag)
-			if sessionName == "" {
-				sessionName = store.GenerateSessionName()
-			}
-
 			if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
-				fmt.Fprintf(stdout, "Verbose: claude=%s backend=%s\n", claudePath, backend.Name())
+				if len(cfg.Sources) > 0 {
+					fmt.Fprintf(stdout, "Verbose: config=%s\n", strings.Join(cfg.Sources, ", "))
+				}
+				fmt.Fprintf(stdout, "Verbose: agent=%s (%s) backend=%s\n", profile.Name, agentPath, backend.Name())
 			}
 
 			fmt.Fprintf(stdout, "Detected: %s, terminal %s, screen %dx%d\n", runtime.GOOS, backend.Name(), screenInfo.Width, screenInfo.Height)
@@ -314,11 +543,11 @@ func NewRootCommand(version, commit, date string) *cobra.Command {
 			} else {
 				fmt.Fprintf(stdout, "Directories: %d different directories\n", len(resolvedDirs))
 			}
-			fmt.Fprintf(stdout, "Spawning %d Claude Code instances...\n", count)
+			fmt.Fprintf(stdout, "Spawning %d %s instances...\n", count, agentLabel(profile))
 
 			spawnOptions := terminal.SpawnOptions{
 				Count:     count,
-				Command:   "claude",
+				Agent:     profile,
 				Dir:       resolvedDir,
 				Dirs:      resolvedDirs,
 				Prompts:   resolvedPrompts,
@@ -339,6 +568,7 @@ func NewRootCommand(version, commit, date string) *cobra.Command {
 				}
 				_ = backend.CloseSession(sessionName)
 				fmt.Fprintf(stderr, "failed to spawn windows: %v\n", err)
+				logEvent(store, sessionName, session.LogEntry{Event: session.LogError, Message: fmt.Sprintf("spawn windows: %v", err)}, stderr)
 				return fmt.Errorf("spawn windows: %w", err)
 			}
 			spawnSucceeded = true
@@ -355,15 +585,22 @@ func NewRootCommand(version, commit, date string) *cobra.Command {
 				Dir:       resolvedDir,
 				Dirs:      resolvedDirs,
 				Prompts:   resolvedPrompts,
+				Layout:    gridLayout.String(),
 				CreatedAt: time.Now(),
 				Windows:   sessionWindows,
+				Status:    "active",
+				Agent:     profile,
+				Hooks:     installedHooks,
+				Verify:    strings.TrimSpace(verifyFlag),
+			}
+			if len(queuedTasks) > 0 {
+				sess.Tasks = session.NewTaskQueue(queuedTasks, count, sess.CreatedAt)
 			}
 			if manifestFlag != "" {
 				sess.ManifestPath = manifestFlag
 			}
 			if len(worktreeRefs) > 0 {
 				sess.Worktrees = worktreeRefs
-				sess.Status = "active"
 				sess.RepoPath = repoPath
 			}
 
@@ -371,24 +608,52 @@ func NewRootCommand(version, commit, date string) *cobra.Command {
 			if err != nil {
 				_ = backend.CloseSession(sessionName)
 				fmt.Fprintf(stderr, "failed to save session: %v\n", err)
+				logEvent(store, sessionName, session.LogEntry{Event: session.LogError, Message: fmt.Sprintf("save session: %v", err)}, stderr)
 				return fmt.Errorf("save session: %w", err)
 			}
 
+			logEvent(store, sessionName, session.LogEntry{Event: session.LogSpawn, Message: fmt.Sprintf("%d %s windows on %s, layout %s", count, profile.Name, backend.Name(), gridLayout.String())}, stderr)
+			logWindowStarts(store, sessionName, windows, spawnOptions.Dirs, stderr)
+
+			if backend.Name() == "tmux" {
+				fmt.Fprintf(stdout, "Attach with: tmux attach -t %s\n", terminal.TmuxSessionName(sessionName))
+			}
 			fmt.Fprintf(stdout, "Session %q created. Use `claude-grid kill %s` to close all.\n", sessionName, sessionName)
+			if pending := len(queuedTasks) - count; pending > 0 {
+				fmt.Fprintf(stdout, "%d of %d tasks queued. Run `claude-grid dispatch %s` to send them as windows finish.\n", pending, len(queuedTasks), sessionName)
+			}
+			if sess.Verify != "" {
+				fmt.Fprintf(stdout, "Once the agents finish, run `claude-grid verify %s` to rank the worktrees, then `claude-grid pick %s <window>` to keep the best.\n", sessionName, sessionName)
+			} else if bestOfFlag != 0 {
+				fmt.Fprintf(stdout, "Once the agents finish, compare them with `claude-grid diff %s`, then keep one with `claude-grid pick %s <window>`.\n", sessionName, sessionName)
+			}
 			return nil
 		},
 	}
 
 	cmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose output")
 	cmd.PersistentFlags().Bool("version", false, "Print version information")
-	cmd.Flags().StringVarP(&terminalFlag, "terminal", "t", "", "Terminal backend: terminal, warp (default: auto-detect)")
+	cmd.Flags().StringVarP(&terminalFlag, "terminal", "t", "", "Terminal backend: terminal, warp, tmux (default: auto-detect)")
 	cmd.Flags().StringArrayVarP(&dirFlags, "dir", "d", nil, "Working directory (repeatable); infers count")
 	cmd.Flags().StringArrayVar(&promptFlags, "prompt", nil, "Per-instance prompt (repeatable; paired with --dir by index)")
+	cmd.Flags().StringVar(&queueFlag, "queue", "", "File of tasks, one prompt per line, handed out to windows as they finish (see dispatch)")
 	cmd.Flags().StringVarP(&manifestFlag, "manifest", "M", "", "YAML manifest file defining instances")
 	cmd.Flags().StringVarP(&nameFlag, "name", "n", "", "Session name (default: auto-generated)")
 	cmd.Flags().StringVarP(&layoutFlag, "layout", "l", "", "Grid layout, e.g. 2x3 (default: auto)")
 	cmd.Flags().BoolVarP(&worktreesFlag, "worktrees", "w", false, "Create git worktrees for each window")
+	cmd.Flags().BoolVar(&hooksFlag, "hooks", false, "Install Claude Code hooks in each worktree that report agent state to claude-grid")
+	cmd.Flags().BoolVar(&noBootstrapFlag, "no-bootstrap", false, "Skip the bootstrap steps from the config files for new worktrees")
+	cmd.Flags().BoolVar(&headlessFlag, "headless", false, "Run each agent non-interactively as a child process, logging its output, instead of in a window")
+	cmd.Flags().IntVar(&bestOfFlag, "best-of", 0, "Give the one --prompt to N agents, each in its own worktree, to keep the best (see verify and pick)")
+	cmd.Flags().StringVar(&verifyFlag, "verify", "", "Command that checks each worktree's work, e.g. 'go test ./...', run by verify (and after headless runs)")
 	cmd.Flags().StringVarP(&branchPrefixFlag, "branch-prefix", "b", "", "Branch prefix for worktrees (default: auto-generated)")
+	cmd.Flags().StringVar(&baseFlag, "base", "", "Ref new worktree branches start from, e.g. origin/main or a tag (default: HEAD)")
+	cmd.Flags().StringArrayVar(&branchFlags, "branch", nil, "Existing branch to continue in a worktree (repeatable; paired with windows by index); infers count")
+	cmd.Flags().StringVar(&agentFlag, "agent", "", "Agent profile: "+strings.Join(agent.Names(), ", ")+" (default: claude, or custom with --command)")
+	cmd.Flags().StringVar(&commandFlag, "command", "", "Command line to run in each window, e.g. 'aider --model sonnet'; {prompt} marks where the prompt goes")
+	cmd.Flags().StringVar(&promptModeFlag, "prompt-mode", "", "How the prompt is passed: positional, flag, stdin, file, none (default: from agent)")
+	cmd.Flags().StringVar(&profileFlag, "profile", "", "Named profile from the config files (default: $CLAUDE_GRID_PROFILE)")
+	cmd.Flags().StringVar(&promptFlagFlag, "prompt-flag", "", "Flag preceding the prompt for --prompt-mode flag or file, e.g. --message")
 	cmd.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
 		if len(os.Args) >= 2 {
 			candidate := strings.TrimSpace(os.Args[1])
@@ -407,10 +672,306 @@ func NewRootCommand(version, commit, date string) *cobra.Command {
 	cmd.AddCommand(NewListCmd("", script.NewOSAExecutor()))
 	cmd.AddCommand(NewKillCmd("", script.NewOSAExecutor()))
 	cmd.AddCommand(NewCleanCmd(""))
+	cmd.AddCommand(NewMergeCmd(""))
+	cmd.AddCommand(NewDiffCmd(""))
+	cmd.AddCommand(NewVerifyCmd(""))
+	cmd.AddCommand(NewPickCmd(""))
+	cmd.AddCommand(NewResumeCmd("", script.NewOSAExecutor()))
+	cmd.AddCommand(NewBroadcastCmd("", script.NewOSAExecutor()))
+	cmd.AddCommand(NewSendCmd("", script.NewOSAExecutor()))
+	cmd.AddCommand(NewCaptureCmd("", script.NewOSAExecutor()))
+	cmd.AddCommand(NewWatchCmd("", script.NewOSAExecutor()))
+	cmd.AddCommand(NewHookCmd(""))
+	cmd.AddCommand(NewLogCmd(""))
+	cmd.AddCommand(NewTasksCmd(""))
+	cmd.AddCommand(NewDispatchCmd("", script.NewOSAExecutor()))
+	cmd.AddCommand(NewTrustCmd(""))
 
 	return cmd
 }
 
+// settingDefault pairs a string flag with the value configured for it.
+type settingDefault struct {
+	flag   string
+	target *string
+	value  string
+}
+
+// applySettings copies each configured value into its flag variable, unless
+// the flag was given on the command line.
+func applySettings(flags *pflag.FlagSet, defaults []settingDefault) {
+	for _, d := range defaults {
+		if d.value != "" && !flags.Changed(d.flag) {
+			*d.target = d.value
+		}
+	}
+}
+
+// resolveAgent builds the agent profile selected by --agent, --command,
+// --prompt-mode and --prompt-flag. --command alone runs a custom agent that
+// takes its prompt as the last argument.
+func resolveAgent(name, command, promptMode, promptFlag string) (agent.Profile, error) {
+	name = strings.TrimSpace(name)
+	command = strings.TrimSpace(command)
+
+	var profile agent.Profile
+	switch {
+	case strings.EqualFold(name, agent.CustomName) || (name == "" && command != ""):
+		if command == "" {
+			return agent.Profile{}, fmt.Errorf("--agent %s requires --command", agent.CustomName)
+		}
+		profile = agent.Profile{Name: agent.CustomName, PromptMode: agent.PromptPositional}
+	case name == "":
+		profile = agent.Default()
+	default:
+		p, err := agent.Lookup(name)
+		if err != nil {
+			return agent.Profile{}, err
+		}
+		profile = p
+	}
+
+	if command != "" {
+		p, err := profile.WithCommand(command)
+		if err != nil {
+			return agent.Profile{}, fmt.Errorf("--command: %w", err)
+		}
+		profile = p
+	}
+	if promptMode != "" {
+		profile.PromptMode = agent.PromptMode(strings.ToLower(strings.TrimSpace(promptMode)))
+	}
+	if promptFlag != "" {
+		profile.PromptFlag = promptFlag
+	}
+	return profile, nil
+}
+
+// trustedBootstrap returns the bootstrap steps of cfg that may run. Setup
+// commands from the project config file come with the repository, so they
+// are skipped with a warning until trusted with the trust command.
+func trustedBootstrap(cfg config.Config, trustPath string, stderr io.Writer) config.Bootstrap {
+	steps := cfg.Bootstrap
+	trusted, err := cfg.SetupTrusted(trustPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "warning: %v\n", err)
+	}
+	if !trusted {
+		fmt.Fprintf(stderr, "warning: skipping the setup commands of %s, which have not been trusted:\n", displayPath(cfg.SetupSource))
+		printSetupCommands(stderr, steps.Setup)
+		fmt.Fprintln(stderr, "Review them, then run `claude-grid trust` in the repository to allow them.")
+		steps.Setup = nil
+	}
+	return steps
+}
+
+// bootstrapWorktrees runs the configured bootstrap steps in every worktree,
+// copying files from the main checkout at repoPath. Setup output is written
+// to a log per window. Failures are reported per worktree but do not stop the
+// spawn, so the agent can still be pointed at what went wrong.
+func bootstrapWorktrees(ctx context.Context, store *session.Store, sessionName, repoPath string, cfg config.Bootstrap, worktrees []session.WorktreeRef, stdout, stderr io.Writer) {
+	paths := make([]string, len(worktrees))
+	for i, wt := range worktrees {
+		paths[i] = wt.Path
+	}
+
+	fmt.Fprintf(stdout, "Bootstrapping %d worktrees...\n", len(worktrees))
+	printSetupCommands(stdout, cfg.Setup)
+	start := time.Now()
+	results := bootstrap.RunAll(ctx, bootstrap.Options{
+		Source:  repoPath,
+		Copy:    cfg.Copy,
+		Mode:    cfg.CopyMode,
+		Setup:   cfg.Setup,
+		Timeout: cfg.Timeout,
+	}, paths)
+
+	for i, r := range results {
+		window := i + 1
+		var logPath string
+		if r.Output != "" {
+			logPath = store.BootstrapLogPath(sessionName, window)
+			err := os.MkdirAll(filepath.Dir(logPath), 0755)
+			if err == nil {
+				err = os.WriteFile(logPath, []byte(r.Output), 0644)
+			}
+			if err != nil {
+				fmt.Fprintf(stderr, "warning: failed to write bootstrap log of window %d: %v\n", window, err)
+				logPath = ""
+			}
+		}
+
+		if r.Err != nil {
+			message := fmt.Sprintf("bootstrap failed in window %d (%s): %v", window, worktrees[i].Branch, r.Err)
+			if logPath != "" {
+				message += "; output in " + displayPath(logPath)
+			}
+			fmt.Fprintf(stderr, "warning: %s\n", message)
+			logEvent(store, sessionName, session.LogEntry{Event: session.LogError, Window: window, Message: message}, stderr)
+			continue
+		}
+		logEvent(store, sessionName, session.LogEntry{Event: session.LogBootstrap, Window: window, Message: fmt.Sprintf("copied %d paths, ran %d setup commands", len(r.Copied), len(cfg.Setup))}, stderr)
+	}
+	fmt.Fprintf(stdout, "Bootstrap finished in %s\n", time.Since(start).Round(time.Second))
+}
+
+// checkoutManifestBranch checks out the branch of a manifest instance in dir,
+// first creating it from the instance's base if it does not exist yet, and
+// describes what was done for the session log. An existing branch must
+// already contain the base, since it is checked out as it is.
+func checkoutManifestBranch(ctx context.Context, dir string, inst manifest.Instance) (string, error) {
+	args := []string{"-C", dir, "checkout", inst.Branch}
+	message := fmt.Sprintf("checked out %s in %s", inst.Branch, dir)
+	if inst.Base != "" {
+		manager, err := git.NewManager(dir)
+		if err != nil {
+			return "", err
+		}
+		baseCommit, err := manager.ResolveRef(inst.Base)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case !manager.BranchExists(inst.Branch):
+			args = []string{"-C", dir, "checkout", "-b", inst.Branch, baseCommit}
+			message = fmt.Sprintf("created branch %s from %s (%s) in %s", inst.Branch, inst.Base, shortCommit(baseCommit), dir)
+		case !manager.BranchContains(inst.Branch, baseCommit):
+			return "", fmt.Errorf("branch %q already exists and does not contain base %s (%s); drop the base or update the branch", inst.Branch, inst.Base, shortCommit(baseCommit))
+		default:
+			message = fmt.Sprintf("checked out existing branch %s, which already contains %s (%s), in %s", inst.Branch, inst.Base, shortCommit(baseCommit), dir)
+		}
+	}
+
+	out, err := exec.CommandContext(ctx, "git", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%w (output: %s)", err, strings.TrimSpace(string(out)))
+	}
+	return message, nil
+}
+
+// shortCommit abbreviates a commit SHA for messages.
+func shortCommit(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// installHooks installs Claude Code hooks recording events for each worktree's
+// window. Other agents do not run Claude Code hooks, so nothing is installed
+// for them and false is returned, as it is when the repository tracks the
+// settings file hooks go into.
+func installHooks(profile agent.Profile, sessionName string, worktrees []session.WorktreeRef, stderr io.Writer) (bool, error) {
+	if profile.Name != "claude" {
+		fmt.Fprintf(stderr, "warning: agent %q does not run Claude Code hooks; --hooks is ignored\n", profile.Name)
+		return false, nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return false, fmt.Errorf("locate claude-grid executable: %w", err)
+	}
+	for i, wt := range worktrees {
+		err := hooks.Install(wt.Path, hooks.Command(exe, sessionName, i+1))
+		if errors.Is(err, hooks.ErrTracked) {
+			// Hooks would land in the repository's own settings file.
+			for _, installed := range worktrees[:i] {
+				_ = hooks.Uninstall(installed.Path)
+			}
+			fmt.Fprintf(stderr, "warning: the repository tracks %s; --hooks is ignored\n", hooks.SettingsFile)
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// agentLabel names the agent in progress messages.
+func agentLabel(profile agent.Profile) string {
+	if profile.Name == "claude" {
+		return "Claude Code"
+	}
+	return profile.Name
+}
+
+func hasAnyPrompt(prompts []string) bool {
+	for _, p := range prompts {
+		if strings.TrimSpace(p) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasEveryPrompt reports whether every instance has a prompt.
+func hasEveryPrompt(prompts []string) bool {
+	for _, p := range prompts {
+		if strings.TrimSpace(p) == "" {
+			return false
+		}
+	}
+	return len(prompts) > 0
+}
+
+// detectScreenInfo returns the screen to tile onto. Detection needs AppleScript;
+// elsewhere only pane-based backends are usable and a fixed fallback is used.
+func detectScreenInfo(executor script.ScriptExecutor, stderr io.Writer) screen.ScreenInfo {
+	fallback := screen.ScreenInfo{X: 0, Y: 0, Width: 1920, Height: 1080}
+	if !platform.HasAppleScript() {
+		return fallback
+	}
+
+	screenInfo, err := screen.DetectScreen(executor)
+	if err != nil {
+		fmt.Fprintf(stderr, "warning: failed to detect screen, using fallback 1920x1080: %v\n", err)
+		return fallback
+	}
+	return screenInfo
+}
+
+func calculateBounds(layout grid.GridLayout, screenInfo screen.ScreenInfo, count int) []grid.WindowBounds {
+	return grid.CalculateWindowBounds(layout, grid.ScreenInfo{
+		X:      screenInfo.X,
+		Y:      screenInfo.Y,
+		Width:  screenInfo.Width,
+		Height: screenInfo.Height,
+	}, count)
+}
+
+// loadSession loads a stored session, reporting a missing, unsupported or
+// unreadable session file to stderr.
+func loadSession(store *session.Store, name string, stderr io.Writer) (session.Session, error) {
+	sess, err := store.LoadSession(name)
+	switch {
+	case err == nil:
+		return sess, nil
+	case errors.Is(err, os.ErrNotExist):
+		fmt.Fprintf(stderr, "Session '%s' not found. Run 'claude-grid list' to see active sessions.\n", name)
+		return session.Session{}, fmt.Errorf("session '%s' not found", name)
+	case errors.Is(err, session.ErrNewerSchema):
+		fmt.Fprintf(stderr, "Session '%s' was written by a newer claude-grid: %v\n", name, err)
+	default:
+		fmt.Fprintf(stderr, "Session '%s' could not be loaded: %v\n", name, err)
+	}
+	return session.Session{}, fmt.Errorf("session '%s': %w", name, err)
+}
+
+// backendForSession returns the backend that created a stored session.
+func backendForSession(name string, executor script.ScriptExecutor) (terminal.TerminalBackend, error) {
+	switch name {
+	case "terminal":
+		return terminal.NewTerminalAppBackend(executor), nil
+	case "warp":
+		return terminal.NewWarpBackend(executor), nil
+	case "tmux":
+		return terminal.NewTmuxBackend(), nil
+	default:
+		return nil, fmt.Errorf("unknown backend: %s", name)
+	}
+}
+
 func allDirsSame(ss []string) bool {
 	if len(ss) <= 1 {
 		return true
@@ -2,8 +2,17 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/riricardoMa/claude-grid/internal/config"
+	"github.com/riricardoMa/claude-grid/internal/manifest"
+	"github.com/riricardoMa/claude-grid/internal/session"
 )
 
 func TestRootCommand(t *testing.T) {
@@ -122,11 +131,71 @@ func TestRootCommandManifestConflicts(t *testing.T) {
 			args:    []string{"--manifest", "/tmp/test.yaml", "3"},
 			wantMsg: "--manifest cannot be combined",
 		},
+		{
+			name:    "hooks without worktrees",
+			args:    []string{"2", "--hooks"},
+			wantMsg: "--hooks requires --worktrees",
+		},
+		{
+			name:    "base without worktrees",
+			args:    []string{"2", "--base", "origin/main"},
+			wantMsg: "--base and --branch require --worktrees",
+		},
+		{
+			name:    "branch without worktrees",
+			args:    []string{"--branch", "feature/login"},
+			wantMsg: "--base and --branch require --worktrees",
+		},
 		{
 			name:    "manifest + prompt",
 			args:    []string{"--manifest", "/tmp/test.yaml", "--prompt", "do X"},
 			wantMsg: "--manifest cannot be combined",
 		},
+		{
+			name:    "manifest + queue",
+			args:    []string{"--manifest", "/tmp/test.yaml", "--queue", "/tmp/tasks.txt"},
+			wantMsg: "--manifest cannot be combined",
+		},
+		{
+			name:    "queue + prompt",
+			args:    []string{"2", "--queue", "/tmp/tasks.txt", "--prompt", "do X"},
+			wantMsg: "--queue cannot be combined with --prompt",
+		},
+		{
+			name:    "headless + queue",
+			args:    []string{"2", "--headless", "--queue", "/tmp/tasks.txt"},
+			wantMsg: "--headless cannot be combined",
+		},
+		{
+			name:    "headless + terminal",
+			args:    []string{"2", "--headless", "--terminal", "tmux"},
+			wantMsg: "--headless cannot be combined",
+		},
+		{
+			name:    "best-of + count",
+			args:    []string{"3", "--best-of", "3", "--prompt", "do X"},
+			wantMsg: "--best-of cannot be combined",
+		},
+		{
+			name:    "best-of without prompt",
+			args:    []string{"--best-of", "3"},
+			wantMsg: "--best-of needs exactly one --prompt",
+		},
+		{
+			name:    "best-of out of range",
+			args:    []string{"--best-of", "1", "--prompt", "do X"},
+			wantMsg: "invalid --best-of 1",
+		},
+		{
+			name:    "verify without worktrees",
+			args:    []string{"2", "--verify", "go test ./..."},
+			wantMsg: "--verify requires --worktrees or --best-of",
+		},
+		{
+			name:    "missing queue file",
+			args:    []string{"2", "--queue", "/nonexistent/tasks.txt"},
+			wantMsg: "failed to read task queue",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -178,3 +247,207 @@ func TestRootCommandTooManyPrompts(t *testing.T) {
 		t.Errorf("stderr = %q, want to contain 'more --prompt flags'", stderrStr)
 	}
 }
+
+func TestRootCommandAgentValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantMsg string
+	}{
+		{
+			name:    "unknown agent",
+			args:    []string{"1", "--dir", "/tmp", "--agent", "gemini"},
+			wantMsg: "unknown agent",
+		},
+		{
+			name:    "custom without command",
+			args:    []string{"1", "--dir", "/tmp", "--agent", "custom"},
+			wantMsg: "requires --command",
+		},
+		{
+			name:    "command not in PATH",
+			args:    []string{"1", "--dir", "/tmp", "--command", "claude-grid-no-such-agent --fast"},
+			wantMsg: "'claude-grid-no-such-agent' not found in PATH",
+		},
+		{
+			name:    "unsupported prompt mode",
+			args:    []string{"1", "--dir", "/tmp", "--command", "sh", "--prompt-mode", "telepathy"},
+			wantMsg: "unsupported prompt mode",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewRootCommand("test", "abc123", "2026-01-01")
+			var stdout, stderr bytes.Buffer
+			cmd.SetOut(&stdout)
+			cmd.SetErr(&stderr)
+			cmd.SetArgs(tt.args)
+			if err := cmd.Execute(); err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			if !strings.Contains(stderr.String(), tt.wantMsg) {
+				t.Errorf("stderr = %q, want to contain %q", stderr.String(), tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestResolveAgent(t *testing.T) {
+	p, err := resolveAgent("", "", "", "")
+	if err != nil || p.Name != "claude" {
+		t.Errorf("default agent = %+v, %v; want claude", p, err)
+	}
+
+	p, err = resolveAgent("", "mytool --fast", "", "")
+	if err != nil || p.Name != "custom" || p.Command != "mytool" || len(p.Args) != 1 || p.PromptMode != "positional" {
+		t.Errorf("custom agent = %+v, %v", p, err)
+	}
+
+	p, err = resolveAgent("aider", "/opt/aider --model sonnet", "file", "--message-file")
+	if err != nil || p.Name != "aider" || p.Command != "/opt/aider" || p.PromptMode != "file" || p.PromptFlag != "--message-file" {
+		t.Errorf("overridden aider = %+v, %v", p, err)
+	}
+}
+
+func TestRootCommandConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := "defaults:\n  count: 2\n  command: claude-grid-no-such-agent\nprofiles:\n  review:\n    layout: 9x9x9\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	t.Setenv("CLAUDE_GRID_CONFIG", configPath)
+
+	run := func(args ...string) string {
+		cmd := NewRootCommand("test", "abc123", "2026-01-01")
+		var stdout, stderr bytes.Buffer
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&stderr)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err == nil {
+			t.Fatalf("Execute(%v) error = nil, want error", args)
+		}
+		return stderr.String()
+	}
+
+	// The configured count and command apply without a count argument.
+	if stderr := run("--dir", "/tmp", "--dir", "/tmp"); !strings.Contains(stderr, "'claude-grid-no-such-agent' not found") {
+		t.Errorf("stderr = %q, want configured command to be used", stderr)
+	}
+	if stderr := run(); strings.Contains(stderr, "count argument is required") {
+		t.Errorf("stderr = %q, want configured count to be used", stderr)
+	}
+
+	// A flag beats the config file.
+	if stderr := run("1", "--command", "claude-grid-other-agent"); !strings.Contains(stderr, "'claude-grid-other-agent' not found") {
+		t.Errorf("stderr = %q, want --command to override config", stderr)
+	}
+
+	// Naming another agent drops the configured command line with it.
+	t.Setenv("PATH", t.TempDir())
+	if stderr := run("1", "--agent", "codex"); strings.Contains(stderr, "claude-grid-no-such-agent") || !strings.Contains(stderr, "codex") {
+		t.Errorf("stderr = %q, want --agent to replace the configured command", stderr)
+	}
+
+	if stderr := run("1", "--profile", "ship"); !strings.Contains(stderr, `unknown profile "ship"`) {
+		t.Errorf("stderr = %q, want unknown profile error", stderr)
+	}
+}
+
+func TestCheckoutManifestBranch(t *testing.T) {
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test User"},
+		{"commit", "-q", "--allow-empty", "-m", "init"},
+		{"tag", "v1"},
+		{"commit", "-q", "--allow-empty", "-m", "later"},
+		{"tag", "v2"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	revParse := func(ref string) string {
+		out, err := exec.Command("git", "-C", repo, "rev-parse", ref).Output()
+		if err != nil {
+			t.Fatalf("git rev-parse %s: %v", ref, err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	message, err := checkoutManifestBranch(context.Background(), repo, manifest.Instance{Branch: "fix/login", Base: "v1"})
+	if err != nil {
+		t.Fatalf("checkoutManifestBranch() error = %v", err)
+	}
+	if !strings.Contains(message, "created branch fix/login from v1") {
+		t.Errorf("message = %q", message)
+	}
+	if revParse("HEAD") != revParse("v1") {
+		t.Error("new branch should start at the base")
+	}
+
+	if _, err := checkoutManifestBranch(context.Background(), repo, manifest.Instance{Branch: "other", Base: "no-such-ref"}); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("checkoutManifestBranch() error = %v, want missing base", err)
+	}
+
+	// fix/login now exists at v1, which v2 comes after.
+	message, err = checkoutManifestBranch(context.Background(), repo, manifest.Instance{Branch: "fix/login", Base: "v1"})
+	if err != nil {
+		t.Fatalf("checkoutManifestBranch() error = %v", err)
+	}
+	if !strings.Contains(message, "checked out existing branch fix/login, which already contains v1") {
+		t.Errorf("message = %q", message)
+	}
+	_, err = checkoutManifestBranch(context.Background(), repo, manifest.Instance{Branch: "fix/login", Base: "v2"})
+	if err == nil || !strings.Contains(err.Error(), "does not contain base") {
+		t.Errorf("checkoutManifestBranch() error = %v, want base missing from the existing branch", err)
+	}
+	if revParse("fix/login") != revParse("v1") {
+		t.Error("existing branch should be left at its tip")
+	}
+}
+
+func TestBootstrapWorktrees(t *testing.T) {
+	storeDir := t.TempDir()
+	store := session.NewStore(storeDir)
+	repo := t.TempDir()
+	os.WriteFile(filepath.Join(repo, ".env"), []byte("KEY=1\n"), 0644)
+
+	good, bad := t.TempDir(), t.TempDir()
+	os.WriteFile(filepath.Join(good, "ok"), nil, 0644)
+	worktrees := []session.WorktreeRef{{Path: good, Branch: "b-1"}, {Path: bad, Branch: "b-2"}}
+
+	var stdout, stderr bytes.Buffer
+	bootstrapWorktrees(context.Background(), store, "boot", repo, config.Bootstrap{
+		Copy:  []string{".env"},
+		Setup: []string{"echo setting up; test -f ok"},
+	}, worktrees, &stdout, &stderr)
+
+	if !strings.Contains(stdout.String(), "$ echo setting up; test -f ok") {
+		t.Errorf("stdout = %q, want the setup commands listed before they run", stdout.String())
+	}
+	for _, dir := range []string{good, bad} {
+		if data, err := os.ReadFile(filepath.Join(dir, ".env")); err != nil || string(data) != "KEY=1\n" {
+			t.Errorf("%s/.env = %q, %v", dir, data, err)
+		}
+	}
+	if !strings.Contains(stderr.String(), "bootstrap failed in window 2 (b-2)") || strings.Contains(stderr.String(), "window 1") {
+		t.Errorf("stderr = %q, want only window 2 to fail", stderr.String())
+	}
+	if data, err := os.ReadFile(store.BootstrapLogPath("boot", 2)); err != nil || !strings.Contains(string(data), "setting up") {
+		t.Errorf("bootstrap log = %q, %v", data, err)
+	}
+
+	entries, err := store.ReadLog("boot")
+	if err != nil {
+		t.Fatalf("ReadLog() error = %v", err)
+	}
+	var events []string
+	for _, e := range entries {
+		events = append(events, fmt.Sprintf("%s:%d", e.Event, e.Window))
+	}
+	if got := strings.Join(events, " "); got != "bootstrap:1 error:2" {
+		t.Errorf("log events = %q, want bootstrap:1 error:2", got)
+	}
+}
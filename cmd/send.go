@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/riricardoMa/claude-grid/internal/script"
+	"github.com/riricardoMa/claude-grid/internal/session"
+	"github.com/spf13/cobra"
+)
+
+func NewSendCmd(storePath string, executor script.ScriptExecutor) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "send <session-name> <window> <text>",
+		Short: "Send input to one window in a session",
+		Long: `Send input to one window in a session, as if it were typed into the window
+followed by Return. Windows are numbered from 1 in the order they were spawned,
+matching their position in the grid. For example:
+
+  claude-grid send sprint 3 "stop, the API is in internal/api not pkg/api"`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionName, text := args[0], args[2]
+
+			store := session.NewStore(storePath)
+			sess, err := loadSession(store, sessionName, cmd.ErrOrStderr())
+			if err != nil {
+				return err
+			}
+
+			window, err := windowByNumber(sess, args[1])
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				return err
+			}
+
+			if sess.Status == "stopped" || !checkWindowLiveness(cmd.Context(), executor, sess, window) {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Window %d of session '%s' is no longer open. Run 'claude-grid list' to check the session.\n", window.Index+1, sessionName)
+				return fmt.Errorf("window %d of session '%s' is not open", window.Index+1, sessionName)
+			}
+
+			sender, err := inputSenderForSession(sess, executor)
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				return err
+			}
+
+			if err := sender.SendInput(cmd.Context(), window, text); err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				return fmt.Errorf("send to window %d: %w", window.Index+1, err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Sent to window %d in session '%s'.\n", window.Index+1, sessionName)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// windowByNumber returns the window of sess numbered arg, counting from 1.
+func windowByNumber(sess session.Session, arg string) (session.WindowRef, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 {
+		return session.WindowRef{}, fmt.Errorf("invalid window %q: must be a number from 1 to %d", arg, len(sess.Windows))
+	}
+	for _, w := range sess.Windows {
+		if w.Index == n-1 {
+			return w, nil
+		}
+	}
+	return session.WindowRef{}, fmt.Errorf("session '%s' has no window %d (it has %d)", sess.Name, n, len(sess.Windows))
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/riricardoMa/claude-grid/internal/session"
+)
+
+func runSend(t *testing.T, storeDir string, executor *stubExecutor, args ...string) (string, string, error) {
+	t.Helper()
+	cmd := NewSendCmd(storeDir, executor)
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+func saveSendSession(t *testing.T, storeDir string) {
+	t.Helper()
+	if err := session.NewStore(storeDir).SaveSession(session.Session{
+		Name:    "sprint",
+		Backend: "terminal",
+		Count:   2,
+		Status:  "active",
+		Windows: []session.WindowRef{{ID: "42", Index: 0}, {ID: "43", Index: 1}},
+	}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+}
+
+func TestSendToOneWindow(t *testing.T) {
+	storeDir := t.TempDir()
+	saveSendSession(t, storeDir)
+
+	executor := &stubExecutor{output: "42, 43"}
+	stdout, _, err := runSend(t, storeDir, executor, "sprint", "2", "try again")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	last := executor.scripts[len(executor.scripts)-1]
+	if want := `do script "try again" in window id 43`; !strings.Contains(last, want) {
+		t.Errorf("script = %q, want it to contain %q", last, want)
+	}
+	if !strings.Contains(stdout, "Sent to window 2") {
+		t.Errorf("stdout = %q", stdout)
+	}
+}
+
+func TestSendErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		output     string
+		window     string
+		wantStderr string
+	}{
+		{name: "window closed", output: "42", window: "2", wantStderr: "Window 2 of session 'sprint' is no longer open"},
+		{name: "out of range", output: "42, 43", window: "3", wantStderr: "has no window 3"},
+		{name: "not a number", output: "42, 43", window: "first", wantStderr: `invalid window "first"`},
+		{name: "zero", output: "42, 43", window: "0", wantStderr: `invalid window "0"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storeDir := t.TempDir()
+			saveSendSession(t, storeDir)
+
+			executor := &stubExecutor{output: tt.output}
+			_, stderr, err := runSend(t, storeDir, executor, "sprint", tt.window, "hello")
+			if err == nil {
+				t.Fatal("Execute() error = nil, want error")
+			}
+			if !strings.Contains(stderr, tt.wantStderr) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr, tt.wantStderr)
+			}
+			for _, s := range executor.scripts {
+				if strings.Contains(s, "do script") {
+					t.Errorf("input was sent despite error: %q", s)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/riricardoMa/claude-grid/internal/session"
+	"github.com/spf13/cobra"
+)
+
+// taskSummary is one task as printed by the tasks command.
+type taskSummary struct {
+	Number       int `json:"task" yaml:"task"`
+	session.Task `yaml:",inline"`
+}
+
+func NewTasksCmd(storePath string) *cobra.Command {
+	var output outputOptions
+
+	cmd := &cobra.Command{
+		Use:   "tasks <session-name>",
+		Short: "Show the task queue of a session",
+		Long: `Show the tasks queued with --queue for a session, numbered in the order they
+are dispatched, with their state (pending, running, done or failed), the
+window they were dispatched to and how long they ran.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionName := args[0]
+
+			if err := output.validate(); err != nil {
+				return err
+			}
+
+			store := session.NewStore(storePath)
+			sess, err := loadSession(store, sessionName, cmd.ErrOrStderr())
+			if err != nil {
+				return err
+			}
+			if len(sess.Tasks) == 0 {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Session '%s' has no task queue. Spawn it with --queue.\n", sessionName)
+				return fmt.Errorf("session '%s' has no task queue", sessionName)
+			}
+
+			if output.structured() {
+				summaries := make([]taskSummary, len(sess.Tasks))
+				for i, t := range sess.Tasks {
+					summaries[i] = taskSummary{Number: i + 1, Task: t}
+				}
+				return printResults(&output, cmd.OutOrStdout(), summaries)
+			}
+
+			now := time.Now()
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TASK\tSTATE\tWINDOW\tTIME\tPROMPT")
+			for i, t := range sess.Tasks {
+				state := t.State
+				if t.Error != "" {
+					state += " (" + t.Error + ")"
+				}
+				window := "-"
+				if t.Window > 0 {
+					window = fmt.Sprintf("%d", t.Window)
+				}
+				elapsed := "-"
+				switch {
+				case t.StartedAt.IsZero():
+				case t.FinishedAt.IsZero():
+					elapsed = formatAge(now.Sub(t.StartedAt))
+				default:
+					elapsed = formatAge(t.FinishedAt.Sub(t.StartedAt))
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", i+1, state, window, elapsed, truncate(t.Prompt, 60))
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			counts := sess.TaskCounts()
+			fmt.Fprintf(cmd.OutOrStdout(), "\n%d tasks: %d pending, %d running, %d done, %d failed\n", len(sess.Tasks), counts[session.TaskPending], counts[session.TaskRunning], counts[session.TaskDone], counts[session.TaskFailed])
+			return nil
+		},
+	}
+
+	output.addFlags(cmd)
+
+	return cmd
+}
+
+// readTaskFile reads the prompts of a --queue file: one task per line, with
+// blank lines and lines starting with # ignored.
+func readTaskFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var tasks []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tasks = append(tasks, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("no tasks in %s", path)
+	}
+	return tasks, nil
+}
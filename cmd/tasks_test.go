@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/riricardoMa/claude-grid/internal/session"
+)
+
+func runTasks(t *testing.T, storeDir string, args ...string) (string, string, error) {
+	t.Helper()
+	cmd := NewTasksCmd(storeDir)
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+func TestTasksTable(t *testing.T) {
+	storeDir := t.TempDir()
+	started := time.Now().Add(-10 * time.Minute)
+	saveQueueSession(t, storeDir, false, []session.Task{
+		{Prompt: "fix the login bug", State: session.TaskDone, Window: 1, StartedAt: started, FinishedAt: started.Add(5 * time.Minute)},
+		{Prompt: "add rate limiting", State: session.TaskFailed, Window: 2, StartedAt: started, FinishedAt: started.Add(time.Minute), Error: "window closed"},
+		{Prompt: "write API docs", State: session.TaskRunning, Window: 1, StartedAt: started.Add(5 * time.Minute)},
+		{Prompt: "update changelog", State: session.TaskPending},
+	})
+
+	stdout, _, err := runTasks(t, storeDir, "queue")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("stdout has %d lines, want header, 4 tasks, blank and summary:\n%s", len(lines), stdout)
+	}
+	for i, want := range []string{
+		"1  done                   1       5m    fix the login bug",
+		"2  failed (window closed)  2       1m    add rate limiting",
+		"3  running",
+		"4  pending                -       -     update changelog",
+	} {
+		if got := strings.Join(strings.Fields(lines[i+1]), " "); !strings.HasPrefix(got, strings.Join(strings.Fields(want), " ")) {
+			t.Errorf("row %d = %q, want %q", i+1, lines[i+1], want)
+		}
+	}
+	if want := "4 tasks: 1 pending, 1 running, 1 done, 1 failed"; lines[6] != want {
+		t.Errorf("summary = %q, want %q", lines[6], want)
+	}
+}
+
+func TestTasksOutputJSON(t *testing.T) {
+	storeDir := t.TempDir()
+	saveQueueSession(t, storeDir, false, session.NewTaskQueue([]string{"a", "b", "c"}, 2, time.Now()))
+
+	stdout, _, err := runTasks(t, storeDir, "queue", "-o", "json")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	var tasks []map[string]any
+	if err := json.Unmarshal([]byte(stdout), &tasks); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, stdout)
+	}
+	if len(tasks) != 3 || tasks[2]["task"] != float64(3) || tasks[2]["state"] != "pending" || tasks[2]["prompt"] != "c" {
+		t.Errorf("tasks = %v", tasks)
+	}
+	if _, ok := tasks[2]["started_at"]; ok {
+		t.Errorf("pending task has started_at: %v", tasks[2])
+	}
+}
+
+func TestTasksWithoutQueue(t *testing.T) {
+	storeDir := t.TempDir()
+	saveQueueSession(t, storeDir, false, nil)
+
+	_, stderr, err := runTasks(t, storeDir, "queue")
+	if err == nil {
+		t.Fatal("Execute() error = nil, want error")
+	}
+	if !strings.Contains(stderr, "Spawn it with --queue") {
+		t.Errorf("stderr = %q", stderr)
+	}
+}
+
+func TestReadTaskFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.txt")
+	content := "# sprint 12\nfix the login bug\n\n  add rate limiting  \n# docs\nwrite API docs\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	tasks, err := readTaskFile(path)
+	if err != nil {
+		t.Fatalf("readTaskFile() error = %v", err)
+	}
+	want := []string{"fix the login bug", "add rate limiting", "write API docs"}
+	if !reflect.DeepEqual(tasks, want) {
+		t.Errorf("readTaskFile() = %q, want %q", tasks, want)
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.txt")
+	if err := os.WriteFile(empty, []byte("# nothing yet\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := readTaskFile(empty); err == nil {
+		t.Error("readTaskFile() of file without tasks error = nil, want error")
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/riricardoMa/claude-grid/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// NewTrustCmd creates the trust command, which allows the bootstrap setup
+// commands of the project config file to run. trustPath is the trust file,
+// config.TrustPath() when empty.
+func NewTrustCmd(trustPath string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "trust",
+		Short: "Allow the setup commands of the project config file to run",
+		Long: `Print the bootstrap setup commands of the nearest .claude-grid.yaml and
+allow them to run in new worktrees. Setup commands from a project config file
+come with the repository, so they are skipped until trusted, and again
+whenever they change.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			stdout, stderr := cmd.OutOrStdout(), cmd.ErrOrStderr()
+			if trustPath == "" {
+				trustPath = config.TrustPath()
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				fmt.Fprintf(stderr, "failed to determine current directory: %v\n", err)
+				return fmt.Errorf("get working directory: %w", err)
+			}
+			cfg, err := config.Load(config.GlobalPath(), cwd)
+			if err != nil {
+				fmt.Fprintf(stderr, "failed to load config: %v\n", err)
+				return fmt.Errorf("load config: %w", err)
+			}
+			if cfg.Project == "" {
+				fmt.Fprintf(stderr, "No %s found in this directory or its parents.\n", config.ProjectFileName)
+				return fmt.Errorf("no project config file")
+			}
+			if cfg.SetupSource != cfg.Project || len(cfg.Bootstrap.Setup) == 0 {
+				fmt.Fprintf(stdout, "%s has no setup commands to trust.\n", displayPath(cfg.Project))
+				return nil
+			}
+
+			if err := config.Trust(trustPath, cfg.Project, cfg.Bootstrap.Setup); err != nil {
+				fmt.Fprintf(stderr, "failed to trust %s: %v\n", cfg.Project, err)
+				return fmt.Errorf("trust project config: %w", err)
+			}
+			fmt.Fprintf(stdout, "Trusted the setup commands of %s:\n", displayPath(cfg.Project))
+			printSetupCommands(stdout, cfg.Bootstrap.Setup)
+			fmt.Fprintln(stdout, "They run in new worktrees until they change.")
+			return nil
+		},
+	}
+}
+
+// printSetupCommands lists bootstrap setup commands, one per line.
+func printSetupCommands(w io.Writer, setup []string) {
+	for _, command := range setup {
+		fmt.Fprintf(w, "  $ %s\n", command)
+	}
+}
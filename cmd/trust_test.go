@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/riricardoMa/claude-grid/internal/config"
+)
+
+func TestTrustedBootstrap(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("CLAUDE_GRID_CONFIG", filepath.Join(root, "home", "config.yaml"))
+	repo := filepath.Join(root, "repo")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "bootstrap:\n  copy: [\".env\"]\n  setup: [\"make deps\"]\n"
+	if err := os.WriteFile(filepath.Join(repo, config.ProjectFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(repo)
+
+	cfg, err := config.Load(config.GlobalPath(), repo)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// Untrusted setup commands are shown but dropped; copying still happens.
+	var stderr bytes.Buffer
+	steps := trustedBootstrap(cfg, config.TrustPath(), &stderr)
+	if len(steps.Setup) != 0 || len(steps.Copy) != 1 {
+		t.Errorf("trustedBootstrap() = %+v, want copy without setup", steps)
+	}
+	if !strings.Contains(stderr.String(), "$ make deps") || !strings.Contains(stderr.String(), "claude-grid trust") {
+		t.Errorf("stderr = %q, want the skipped commands and how to trust them", stderr.String())
+	}
+
+	cmd := NewTrustCmd("")
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs(nil)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("trust error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "$ make deps") {
+		t.Errorf("stdout = %q, want the trusted commands", stdout.String())
+	}
+
+	stderr.Reset()
+	if steps := trustedBootstrap(cfg, config.TrustPath(), &stderr); len(steps.Setup) != 1 || stderr.Len() != 0 {
+		t.Errorf("trustedBootstrap() = %+v, stderr %q; want setup to run once trusted", steps, stderr.String())
+	}
+}
@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/riricardoMa/claude-grid/internal/git"
+	"github.com/riricardoMa/claude-grid/internal/session"
+	"github.com/riricardoMa/claude-grid/internal/verify"
+	"github.com/spf13/cobra"
+)
+
+// verifyResult is the machine-readable outcome of verify for one window.
+type verifyResult struct {
+	Rank            int     `json:"rank" yaml:"rank"`
+	Window          int     `json:"window" yaml:"window"`
+	Branch          string  `json:"branch" yaml:"branch"`
+	Passed          bool    `json:"passed" yaml:"passed"`
+	ExitCode        int     `json:"exit_code" yaml:"exit_code"`
+	DurationSeconds float64 `json:"duration_seconds" yaml:"duration_seconds"`
+	FilesChanged    int     `json:"files_changed" yaml:"files_changed"`
+	Insertions      int     `json:"insertions" yaml:"insertions"`
+	Deletions       int     `json:"deletions" yaml:"deletions"`
+	LogPath         string  `json:"log_path" yaml:"log_path"`
+	Error           string  `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func NewVerifyCmd(storePath string) *cobra.Command {
+	var output outputOptions
+	var command string
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "verify <session-name>",
+		Short: "Run a verification command in each worktree and rank them",
+		Long: `Run the session's verification command, given with --verify at spawn time or
+with --command, in each of its worktrees one after another, then rank the
+worktrees: those with changes before those without, since an agent that did
+nothing usually passes but is no answer, then those that pass before those
+that fail, then by the number of lines they change, fewest first, then by how
+long the command took. The command runs
+with sh -c, its output goes to a log per window, and the ranking is kept in
+the session. Keep the best worktree with 'claude-grid pick'.
+
+Headless sessions spawned with --verify are verified as soon as their agents
+exit; for others, run verify once the agents are done.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionName := args[0]
+
+			if err := output.validate(); err != nil {
+				return err
+			}
+			if timeout < 0 {
+				return fmt.Errorf("--timeout must not be negative")
+			}
+
+			store := session.NewStore(storePath)
+			sess, err := loadSession(store, sessionName, cmd.ErrOrStderr())
+			if err != nil {
+				return err
+			}
+			if len(sess.Worktrees) == 0 {
+				return fmt.Errorf("session '%s' has no worktrees to verify", sessionName)
+			}
+			if command == "" {
+				command = sess.Verify
+			}
+			if command == "" {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Session '%s' has no verification command. Pass one with --command.\n", sessionName)
+				return fmt.Errorf("no verification command")
+			}
+			if sess.Backend == headlessBackend && len(liveHeadlessProcesses(sess)) > 0 {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Agents of session '%s' are still running.\n", sessionName)
+				return fmt.Errorf("session '%s' is still running", sessionName)
+			}
+
+			verifications, err := verifyWorktrees(cmd.Context(), store, sess, command, timeout, cmd.ErrOrStderr())
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				return err
+			}
+
+			if output.structured() {
+				if err := printResults(&output, cmd.OutOrStdout(), verifyResults(sess, verifications)); err != nil {
+					return err
+				}
+			} else {
+				printVerifications(cmd.OutOrStdout(), sess, verifications)
+			}
+			return verificationError(verifications)
+		},
+	}
+
+	output.addFlags(cmd)
+	cmd.Flags().StringVar(&command, "command", "", "Verification command, e.g. 'go test ./...' (default: the session's --verify); saved in the session")
+	cmd.Flags().DurationVar(&timeout, "timeout", verify.DefaultTimeout, "Time limit for the command in each worktree")
+
+	return cmd
+}
+
+// verifyWorktrees runs command in each worktree of sess in turn, ranks the
+// worktrees and records the command and outcome in the session. Progress is
+// reported to w.
+func verifyWorktrees(ctx context.Context, store *session.Store, sess session.Session, command string, timeout time.Duration, w io.Writer) ([]session.Verification, error) {
+	verifications := make([]session.Verification, 0, len(sess.Worktrees))
+	scores := make([]verify.Score, 0, len(sess.Worktrees))
+
+	for i, wt := range sess.Worktrees {
+		window := i + 1
+		fmt.Fprintf(w, "Verifying window %d (%s)...\n", window, wt.Branch)
+
+		v := session.Verification{Window: window, LogPath: store.VerifyLogPath(sess.Name, window)}
+		result := runVerification(ctx, wt.Path, command, timeout, v.LogPath)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("verification interrupted")
+		}
+		v.Passed = result.Passed()
+		v.ExitCode = result.ExitCode
+		v.Duration = result.Duration
+		v.FinishedAt = time.Now()
+		if result.Err != nil {
+			v.Error = result.Err.Error()
+		}
+
+		base := wt.BaseCommit
+		if base == "" {
+			_, base = repoBase(sess.RepoPath)
+		}
+		if size, err := git.WorkingTreeSize(wt.Path, base); err == nil {
+			v.FilesChanged, v.Insertions, v.Deletions = size.Files, size.Insertions, size.Deletions
+		} else {
+			fmt.Fprintf(w, "Warning: window %d: failed to measure changes: %v\n", window, err)
+		}
+
+		verifications = append(verifications, v)
+		scores = append(scores, verificationScore(v))
+	}
+
+	for rank, i := range verify.Rank(scores) {
+		verifications[i].Rank = rank + 1
+	}
+
+	err := store.ModifySession(sess.Name, func(s *session.Session) error {
+		s.Verify = command
+		s.Verifications = verifications
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(w, "Warning: failed to save verification results: %v\n", err)
+	}
+
+	for _, v := range verifications {
+		message := fmt.Sprintf("rank %d, passed in %s, +%d -%d lines", v.Rank, formatDuration(v.Duration), v.Insertions, v.Deletions)
+		if !v.Passed {
+			message = fmt.Sprintf("rank %d, failed in %s: %s", v.Rank, formatDuration(v.Duration), v.Error)
+		}
+		logEvent(store, sess.Name, session.LogEntry{Event: session.LogVerify, Window: v.Window, Message: message}, w)
+	}
+	return verifications, nil
+}
+
+// runVerification runs command in dir, writing the command line and its
+// output to logPath.
+func runVerification(ctx context.Context, dir, command string, timeout time.Duration, logPath string) verify.Result {
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return verify.Result{ExitCode: -1, Err: fmt.Errorf("create log: %w", err)}
+	}
+	log, err := os.Create(logPath)
+	if err != nil {
+		return verify.Result{ExitCode: -1, Err: fmt.Errorf("create log: %w", err)}
+	}
+	defer log.Close()
+
+	fmt.Fprintf(log, "$ %s\n", command)
+	return verify.Run(ctx, dir, command, timeout, log)
+}
+
+// verifyResults returns the verifications of sess as results, best first.
+func verifyResults(sess session.Session, verifications []session.Verification) []verifyResult {
+	results := make([]verifyResult, 0, len(verifications))
+	for _, v := range verifications {
+		r := verifyResult{
+			Rank:            v.Rank,
+			Window:          v.Window,
+			Passed:          v.Passed,
+			ExitCode:        v.ExitCode,
+			DurationSeconds: v.Duration.Seconds(),
+			FilesChanged:    v.FilesChanged,
+			Insertions:      v.Insertions,
+			Deletions:       v.Deletions,
+			LogPath:         v.LogPath,
+			Error:           v.Error,
+		}
+		if v.Window >= 1 && v.Window <= len(sess.Worktrees) {
+			r.Branch = sess.Worktrees[v.Window-1].Branch
+		}
+		results = append(results, r)
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Rank < results[j].Rank })
+	return results
+}
+
+// printVerifications writes the ranking, best first, and how to keep the
+// winner.
+func printVerifications(w io.Writer, sess session.Session, verifications []session.Verification) {
+	ranked := append([]session.Verification(nil), verifications...)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Rank < ranked[j].Rank })
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RANK\tWINDOW\tBRANCH\tRESULT\tLINES\tFILES\tTIME")
+	for _, v := range ranked {
+		branch := "-"
+		if v.Window >= 1 && v.Window <= len(sess.Worktrees) {
+			branch = sess.Worktrees[v.Window-1].Branch
+		}
+		result := "passed"
+		if v.Passed && !verificationScore(v).Candidate() {
+			result = "passed, no changes"
+		}
+		if !v.Passed {
+			result = "failed"
+			if v.ExitCode > 0 {
+				result = fmt.Sprintf("failed (%d)", v.ExitCode)
+			}
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t+%d -%d\t%d\t%s\n", v.Rank, v.Window, branch, result, v.Insertions, v.Deletions, v.FilesChanged, formatDuration(v.Duration))
+	}
+	tw.Flush()
+
+	if len(ranked) > 0 && verificationScore(ranked[0]).Candidate() {
+		fmt.Fprintf(w, "\nKeep the best with `claude-grid pick %s %d`. Logs are in %s.\n", sess.Name, ranked[0].Window, displayPath(filepath.Dir(ranked[0].LogPath)))
+	}
+}
+
+// verificationError fails when no worktree with changes passed, so scripts
+// can tell.
+func verificationError(verifications []session.Verification) error {
+	for _, v := range verifications {
+		if verificationScore(v).Candidate() {
+			return nil
+		}
+	}
+	return fmt.Errorf("no worktree with changes passed verification")
+}
+
+// verificationScore returns what v is ranked by.
+func verificationScore(v session.Verification) verify.Score {
+	return verify.Score{Passed: v.Passed, Lines: v.Insertions + v.Deletions, Duration: v.Duration}
+}
+
+// formatDuration rounds d for display, e.g. "850ms", "12.3s" or "2m5s".
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return d.Round(time.Millisecond).String()
+	case d < time.Minute:
+		return d.Round(100 * time.Millisecond).String()
+	default:
+		return d.Round(time.Second).String()
+	}
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/riricardoMa/claude-grid/internal/hooks"
+	"github.com/riricardoMa/claude-grid/internal/session"
+)
+
+func runVerify(t *testing.T, storeDir string, args ...string) (string, string, error) {
+	t.Helper()
+	cmd := NewVerifyCmd(storeDir)
+	cmd.SilenceUsage = true
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+func TestVerifyRanksWorktrees(t *testing.T) {
+	storeDir := t.TempDir()
+	// Windows 1 and 3 pass; window 3 changes fewer lines than window 1.
+	saveMergeSession(t, storeDir, map[string]string{"ok.txt": "line\nline\n", "bad.txt": ""}, "ok.txt", "bad.txt", "ok.txt")
+	store := session.NewStore(storeDir)
+	sess, err := store.LoadSession("sprint")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if err := os.WriteFile(sess.Worktrees[0].Path+"/extra.txt", []byte("more\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	stdout, stderr, err := runVerify(t, storeDir, "sprint", "--command", "test -f ok.txt")
+	if err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, stderr)
+	}
+	for _, want := range []string{
+		"RANK  WINDOW  BRANCH    RESULT      LINES  FILES  TIME",
+		"1     3       sprint-3  passed      +3 -0  1",
+		"2     1       sprint-1  passed      +4 -0  2",
+		"3     2       sprint-2  failed (1)  +1 -0  1",
+		"Keep the best with `claude-grid pick sprint 3`",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("stdout = %q, want %q", stdout, want)
+		}
+	}
+
+	sess, err = store.LoadSession("sprint")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if sess.Verify != "test -f ok.txt" || len(sess.Verifications) != 3 || sess.Verifications[2].Rank != 1 {
+		t.Errorf("session verify = %q, %+v; want command and ranking saved", sess.Verify, sess.Verifications)
+	}
+	if data, err := os.ReadFile(sess.Verifications[1].LogPath); err != nil || !strings.HasPrefix(string(data), "$ test -f ok.txt\n") {
+		t.Errorf("verify log = %q, %v; want the command line", data, err)
+	}
+
+	// The saved command is used by default.
+	stdout, _, err = runVerify(t, storeDir, "sprint", "-o", "json")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	var got []verifyResult
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, stdout)
+	}
+	if len(got) != 3 || got[0].Window != 3 || !got[0].Passed || got[2].ExitCode != 1 || got[2].Error == "" {
+		t.Errorf("results = %+v, want window 3 first and window 2 failing", got)
+	}
+}
+
+func TestVerifyErrors(t *testing.T) {
+	storeDir := t.TempDir()
+	saveMergeSession(t, storeDir, map[string]string{"a.txt": ""}, "a.txt")
+
+	if _, stderr, err := runVerify(t, storeDir, "sprint"); err == nil || !strings.Contains(stderr, "has no verification command") {
+		t.Errorf("Execute() error = %v, stderr = %q; want missing command", err, stderr)
+	}
+	if _, _, err := runVerify(t, storeDir, "sprint", "--command", "false"); err == nil || !strings.Contains(err.Error(), "no worktree with changes passed verification") {
+		t.Errorf("Execute() error = %v, want no worktree passed", err)
+	}
+}
+
+func TestVerifyRanksUnchangedLast(t *testing.T) {
+	storeDir := t.TempDir()
+	saveMergeSession(t, storeDir, map[string]string{"a.txt": ""}, "a.txt", "a.txt")
+	sess, err := session.NewStore(storeDir).LoadSession("sprint")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	// The agent in window 1 did nothing.
+	runGit(t, sess.Worktrees[0].Path, "reset", "-q", "--hard", "HEAD~1")
+
+	stdout, stderr, err := runVerify(t, storeDir, "sprint", "--command", "true")
+	if err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, stderr)
+	}
+	for _, want := range []string{
+		"1     2       sprint-2  passed              +1 -0  1",
+		"2     1       sprint-1  passed, no changes  +0 -0  0",
+		"claude-grid pick sprint 2",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("stdout = %q, want %q", stdout, want)
+		}
+	}
+
+	// Unchanged worktrees alone are no answer.
+	runGit(t, sess.Worktrees[1].Path, "reset", "-q", "--hard", "HEAD~1")
+	stdout, _, err = runVerify(t, storeDir, "sprint")
+	if err == nil || strings.Contains(stdout, "claude-grid pick") {
+		t.Errorf("Execute() error = %v, stdout = %q; want no winner", err, stdout)
+	}
+}
+
+func TestVerifyIgnoresHookSettings(t *testing.T) {
+	storeDir := t.TempDir()
+	saveMergeSession(t, storeDir, map[string]string{"a.txt": ""}, "a.txt", "a.txt")
+	sess, err := session.NewStore(storeDir).LoadSession("sprint")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	// The agent in window 1 did nothing, but claude-grid installed its hooks
+	// there.
+	runGit(t, sess.Worktrees[0].Path, "reset", "-q", "--hard", "HEAD~1")
+	if err := hooks.Install(sess.Worktrees[0].Path, hooks.Command("/usr/local/bin/claude-grid", "sprint", 1)); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	stdout, stderr, err := runVerify(t, storeDir, "sprint", "--command", "true")
+	if err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, stderr)
+	}
+	for _, want := range []string{
+		"1     2       sprint-2  passed              +1 -0  1",
+		"2     1       sprint-1  passed, no changes  +0 -0  0",
+		"claude-grid pick sprint 2",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("stdout = %q, want %q", stdout, want)
+		}
+	}
+}
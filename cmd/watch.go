@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/riricardoMa/claude-grid/internal/agentstate"
+	"github.com/riricardoMa/claude-grid/internal/claude"
+	"github.com/riricardoMa/claude-grid/internal/config"
+	"github.com/riricardoMa/claude-grid/internal/git"
+	"github.com/riricardoMa/claude-grid/internal/hooks"
+	"github.com/riricardoMa/claude-grid/internal/notify"
+	"github.com/riricardoMa/claude-grid/internal/script"
+	"github.com/riricardoMa/claude-grid/internal/session"
+	"github.com/riricardoMa/claude-grid/internal/terminal"
+	"github.com/spf13/cobra"
+)
+
+// clearScreen moves the cursor home and clears the terminal before a frame.
+const clearScreen = "\033[H\033[2J"
+
+// windowStatus is one row of the watch dashboard.
+type windowStatus struct {
+	Window     int
+	Open       bool
+	State      agentstate.State
+	Dir        string
+	Git        git.WorktreeStatus
+	GitErr     error
+	LastActive time.Time
+}
+
+func NewWatchCmd(storePath string, executor script.ScriptExecutor) *cobra.Command {
+	var interval time.Duration
+	var once bool
+	var notifyCommand string
+	var bell bool
+	var desktop bool
+
+	cmd := &cobra.Command{
+		Use:   "watch <session-name>",
+		Short: "Show a live status dashboard for a session",
+		Long: `Show a refreshing dashboard of a session's windows: what each agent is doing,
+its directory, branch, uncommitted file count, last commit, and time since
+the agent was last active. Activity is taken from the agent's conversation
+transcript when it can be located, otherwise from the last commit.
+
+Agents are classified as working, waiting (for permission or input), idle or
+exited from the events reported by the Claude Code hooks installed with
+--hooks, or else from the text their window shows. When an agent starts waiting, the
+notifications configured under "notify" in the config files or with
+--notify-command, --bell and --desktop are sent. Press Ctrl-C to exit.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionName := args[0]
+
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+
+			store := session.NewStore(storePath)
+			if _, err := loadSession(store, sessionName, cmd.ErrOrStderr()); err != nil {
+				return err
+			}
+
+			locator := claude.NewLocator("")
+			events := hooks.NewLog(storePath)
+			if once {
+				_, err := renderWatchFrame(cmd.Context(), store, events, executor, locator, sessionName, cmd.OutOrStdout())
+				return err
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("get working directory: %w", err)
+			}
+			cfg, err := config.Load(config.GlobalPath(), cwd)
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "failed to load config: %v\n", err)
+				return fmt.Errorf("load config: %w", err)
+			}
+			warnIgnoredSettings(cmd.ErrOrStderr(), cfg)
+			notifier := notify.New(cmd.OutOrStdout(), executor)
+			notifier.Command = cfg.Notify.Command
+			if cfg.Notify.Bell != nil {
+				notifier.Bell = *cfg.Notify.Bell
+			}
+			if cfg.Notify.Desktop != nil {
+				notifier.Desktop = *cfg.Notify.Desktop
+			}
+			if cmd.Flags().Changed("notify-command") {
+				notifier.Command = notifyCommand
+			}
+			if cmd.Flags().Changed("bell") {
+				notifier.Bell = bell
+			}
+			if cmd.Flags().Changed("desktop") {
+				notifier.Desktop = desktop
+			}
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			previous := make(map[int]agentstate.State)
+			for {
+				var frame strings.Builder
+				statuses, err := renderWatchFrame(cmd.Context(), store, events, executor, locator, sessionName, &frame)
+				if cmd.Context().Err() != nil {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				fmt.Fprint(cmd.OutOrStdout(), clearScreen+frame.String())
+				notifyWaiting(cmd.Context(), notifier, sessionName, previous, statuses, cmd.ErrOrStderr())
+
+				select {
+				case <-cmd.Context().Done():
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "Refresh interval")
+	cmd.Flags().BoolVar(&once, "once", false, "Print the dashboard once and exit")
+	cmd.Flags().StringVar(&notifyCommand, "notify-command", "", "Shell command run when an agent starts waiting; $CLAUDE_GRID_SESSION, $CLAUDE_GRID_WINDOW, $CLAUDE_GRID_STATE and $CLAUDE_GRID_DIR describe it")
+	cmd.Flags().BoolVar(&bell, "bell", false, "Ring the terminal bell when an agent starts waiting")
+	cmd.Flags().BoolVar(&desktop, "desktop", false, "Post a desktop notification when an agent starts waiting")
+
+	return cmd
+}
+
+// renderWatchFrame reloads the session and writes one dashboard frame to w,
+// returning the window statuses it shows.
+func renderWatchFrame(ctx context.Context, store *session.Store, events *hooks.Log, executor script.ScriptExecutor, locator *claude.Locator, name string, w io.Writer) ([]windowStatus, error) {
+	sess, err := store.LoadSession(name)
+	if err != nil {
+		return nil, fmt.Errorf("session '%s' is no longer available: %w", name, err)
+	}
+
+	// Backends that cannot read window text leave agent states unknown.
+	capturer, _ := outputCapturerForSession(sess, executor)
+
+	var latest map[int]hooks.Event
+	if sess.Hooks {
+		latest, _ = events.Latest(sess.Name)
+	}
+
+	// One query of the backend serves both the windows and the header.
+	live, known := liveWindowIDs(ctx, executor, sess)
+	statuses := collectWindowStatuses(ctx, capturer, latest, locator, sess, live, known)
+	writeWatchFrame(w, sess, statuses, sessionLive(live, known), time.Now())
+	return statuses, nil
+}
+
+// collectWindowStatuses gathers the dashboard row of every window of sess,
+// in grid order. The latest hook event of a window, keyed by window number,
+// takes precedence over classifying its text; capturer and latest may be nil.
+// live and known are the result of liveWindowIDs for sess.
+func collectWindowStatuses(ctx context.Context, capturer terminal.OutputCapturer, latest map[int]hooks.Event, locator *claude.Locator, sess session.Session, live map[string]bool, known bool) []windowStatus {
+	dirs := sessionDirs(sess)
+	canResume := sessionAgent(sess).CanResume()
+
+	windows := append([]session.WindowRef(nil), sess.Windows...)
+	sort.SliceStable(windows, func(i, j int) bool { return windows[i].Index < windows[j].Index })
+
+	statuses := make([]windowStatus, 0, len(windows))
+	for _, win := range windows {
+		status := windowStatus{
+			Window: win.Index + 1,
+			Open:   sess.Status != "stopped" && (!known || live[win.ID]),
+		}
+		event, hasEvent := latest[status.Window]
+		if sess.Backend == headlessBackend && status.Open {
+			// Headless agents cannot wait for input; running means working.
+			status.State = agentstate.Working
+		} else {
+			status.State = windowAgentState(ctx, capturer, latest, win, status.Open)
+		}
+		if win.Index >= 0 && win.Index < len(dirs) {
+			status.Dir = dirs[win.Index]
+		}
+
+		if status.Dir != "" {
+			status.Git, status.GitErr = git.Status(status.Dir)
+			status.LastActive = status.Git.LastCommit.Time
+			if canResume {
+				conversations, err := locator.RecentConversations(status.Dir, sess.CreatedAt)
+				if err == nil && len(conversations) > 0 && conversations[0].ModTime.After(status.LastActive) {
+					status.LastActive = conversations[0].ModTime
+				}
+			}
+		}
+		if hasEvent && event.Time.After(status.LastActive) {
+			status.LastActive = event.Time
+		}
+
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// windowAgentState returns what the agent in win is doing: exited unless the
+// window is open, else the state implied by its latest hook event, else the
+// state shown by its text. capturer and latest may be nil.
+func windowAgentState(ctx context.Context, capturer terminal.OutputCapturer, latest map[int]hooks.Event, win session.WindowRef, open bool) agentstate.State {
+	if !open {
+		return agentstate.Exited
+	}
+	if event, ok := latest[win.Index+1]; ok && event.State() != agentstate.Unknown {
+		return event.State()
+	}
+	if capturer != nil {
+		if text, err := capturer.CaptureOutput(ctx, win, false); err == nil {
+			return agentstate.Classify(text)
+		}
+	}
+	return agentstate.Unknown
+}
+
+// writeWatchFrame writes the dashboard header and one row per window.
+func writeWatchFrame(w io.Writer, sess session.Session, statuses []windowStatus, live bool, now time.Time) {
+	state := sess.Status
+	if !live {
+		state += " (stale)"
+	}
+	fmt.Fprintf(w, "Session %s  %s  %s  %d windows  %s\n\n", sess.Name, sess.Backend, state, len(sess.Windows), now.Format("15:04:05"))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "WINDOW\tSTATE\tBRANCH\tCHANGES\tLAST COMMIT\tACTIVE\tDIR")
+	for _, s := range statuses {
+		state := string(s.State)
+		if s.State == agentstate.Unknown || s.State == "" {
+			state = "open"
+		}
+
+		branch, changes, commit := "-", "-", "-"
+		if s.GitErr == nil && s.Dir != "" {
+			branch = s.Git.Branch
+			if branch == "" {
+				branch = "(detached)"
+			}
+			changes = fmt.Sprintf("%d", s.Git.Uncommitted)
+			if c := s.Git.LastCommit; c.Hash != "" {
+				commit = fmt.Sprintf("%s %s (%s ago)", c.Hash, truncate(c.Subject, 40), formatAge(now.Sub(c.Time)))
+			}
+		}
+
+		active := "-"
+		if !s.LastActive.IsZero() {
+			active = formatAge(now.Sub(s.LastActive)) + " ago"
+		}
+
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", s.Window, state, branch, changes, commit, active, displayPath(s.Dir))
+	}
+	tw.Flush()
+}
+
+// notifyWaiting sends a notification for every window that is waiting now but
+// was not in the previous frame, then records the current states in previous.
+func notifyWaiting(ctx context.Context, notifier *notify.Notifier, sessionName string, previous map[int]agentstate.State, statuses []windowStatus, stderr io.Writer) {
+	for _, s := range statuses {
+		if s.State == agentstate.Waiting && previous[s.Window] != agentstate.Waiting && notifier.Enabled() {
+			event := notify.Event{Session: sessionName, Window: s.Window, State: string(s.State), Dir: s.Dir}
+			if err := notifier.Notify(ctx, event); err != nil {
+				fmt.Fprintf(stderr, "Warning: window %d: %v\n", s.Window, err)
+			}
+		}
+		previous[s.Window] = s.State
+	}
+}
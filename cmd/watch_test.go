@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/riricardoMa/claude-grid/internal/agentstate"
+	"github.com/riricardoMa/claude-grid/internal/git"
+	"github.com/riricardoMa/claude-grid/internal/hooks"
+	"github.com/riricardoMa/claude-grid/internal/notify"
+	"github.com/riricardoMa/claude-grid/internal/session"
+)
+
+func runWatch(t *testing.T, storeDir string, executor *stubExecutor, args ...string) (string, string, error) {
+	t.Helper()
+	t.Setenv("CLAUDE_CONFIG_DIR", t.TempDir())
+	cmd := NewWatchCmd(storeDir, executor)
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	var stdout, stderr bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+// initWatchRepo creates a git repository with one commit and one untracked file.
+func initWatchRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-b", "sprint-1"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test User"},
+		{"commit", "--allow-empty", "-m", "add login form"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v (%s)", args, err, out)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "wip.txt"), []byte("wip\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return dir
+}
+
+func TestWatchOnce(t *testing.T) {
+	storeDir := t.TempDir()
+	repo := initWatchRepo(t)
+	if err := session.NewStore(storeDir).SaveSession(session.Session{
+		Name:      "sprint",
+		Backend:   "terminal",
+		Count:     2,
+		Status:    "active",
+		CreatedAt: time.Now(),
+		Windows:   []session.WindowRef{{ID: "43", Index: 1}, {ID: "42", Index: 0}},
+		Worktrees: []session.WorktreeRef{{Path: repo, Branch: "sprint-1"}, {Path: filepath.Join(storeDir, "gone"), Branch: "sprint-2"}},
+	}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	stdout, _, err := runWatch(t, storeDir, &stubExecutor{output: "42"}, "sprint", "--once")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if strings.Contains(stdout, clearScreen) {
+		t.Errorf("stdout contains clear-screen sequence with --once")
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("stdout has %d lines, want header, blank, table header and 2 rows:\n%s", len(lines), stdout)
+	}
+	if !strings.HasPrefix(lines[0], "Session sprint  terminal  active  2 windows") {
+		t.Errorf("header = %q", lines[0])
+	}
+
+	first := strings.Fields(lines[3])
+	if first[0] != "1" || first[1] != "idle" || first[2] != "sprint-1" || first[3] != "1" {
+		t.Errorf("window 1 row = %q, want idle on sprint-1 with 1 change", lines[3])
+	}
+	if !strings.Contains(lines[3], "add login form") || !strings.Contains(lines[3], repo) {
+		t.Errorf("window 1 row = %q, want last commit and directory", lines[3])
+	}
+
+	second := strings.Fields(lines[4])
+	if second[0] != "2" || second[1] != "exited" || second[2] != "-" {
+		t.Errorf("window 2 row = %q, want exited without git status", lines[4])
+	}
+}
+
+func TestWatchPrefersHookEvents(t *testing.T) {
+	storeDir := t.TempDir()
+	if err := session.NewStore(storeDir).SaveSession(session.Session{
+		Name:      "sprint",
+		Backend:   "terminal",
+		Count:     1,
+		Status:    "active",
+		Hooks:     true,
+		CreatedAt: time.Now(),
+		Windows:   []session.WindowRef{{ID: "42", Index: 0}},
+		Worktrees: []session.WorktreeRef{{Path: initWatchRepo(t), Branch: "sprint-1"}},
+	}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+	if err := hooks.NewLog(storeDir).Append("sprint", hooks.Event{Time: time.Now(), Window: 1, Hook: "Notification"}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	// The window text alone would classify as idle.
+	stdout, _, err := runWatch(t, storeDir, &stubExecutor{output: "42"}, "sprint", "--once")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if fields := strings.Fields(lines[len(lines)-1]); fields[1] != "waiting" {
+		t.Errorf("window row = %q, want waiting from hook event", lines[len(lines)-1])
+	}
+}
+
+func TestWatchSessionNotFound(t *testing.T) {
+	_, stderr, err := runWatch(t, t.TempDir(), nil, "missing", "--once")
+	if err == nil {
+		t.Fatal("Execute() error = nil, want error")
+	}
+	if !strings.Contains(stderr, "not found") {
+		t.Errorf("stderr = %q, want not found message", stderr)
+	}
+}
+
+func TestWriteWatchFrameStoppedSession(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	writeWatchFrame(&buf, session.Session{Name: "sprint", Backend: "tmux", Status: "stopped"}, []windowStatus{{
+		Window:     1,
+		State:      agentstate.Exited,
+		Dir:        "/work/a",
+		Git:        git.WorktreeStatus{Branch: "sprint-1", LastCommit: git.Commit{Hash: "abc1234", Subject: "fix", Time: now.Add(-3 * time.Hour)}},
+		LastActive: now.Add(-90 * time.Second),
+	}}, false, now)
+
+	out := buf.String()
+	for _, want := range []string{"stopped (stale)", "exited", "abc1234 fix (3h ago)", "1m ago", "/work/a"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("frame = %q, want it to contain %q", out, want)
+		}
+	}
+}
+
+func TestNotifyWaitingOnTransition(t *testing.T) {
+	var terminal, stderr bytes.Buffer
+	notifier := notify.New(&terminal, nil)
+	notifier.Bell = true
+
+	previous := map[int]agentstate.State{}
+	frames := [][]windowStatus{
+		{{Window: 1, State: agentstate.Working}, {Window: 2, State: agentstate.Waiting}},
+		{{Window: 1, State: agentstate.Waiting}, {Window: 2, State: agentstate.Waiting}},
+		{{Window: 1, State: agentstate.Waiting}, {Window: 2, State: agentstate.Idle}},
+	}
+	var bells []int
+	for _, frame := range frames {
+		terminal.Reset()
+		notifyWaiting(context.Background(), notifier, "sprint", previous, frame, &stderr)
+		bells = append(bells, strings.Count(terminal.String(), "\a"))
+	}
+
+	if want := []int{1, 1, 0}; fmt.Sprint(bells) != fmt.Sprint(want) {
+		t.Errorf("bells per frame = %v, want %v", bells, want)
+	}
+}
@@ -0,0 +1,326 @@
+// Package agent describes the coding agents claude-grid can run in its windows
+// and builds the shell command line that starts each one with its prompt.
+package agent
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/riricardoMa/claude-grid/internal/claude"
+)
+
+// PromptMode is how an agent receives its initial prompt.
+type PromptMode string
+
+const (
+	// PromptPositional appends the prompt as the last argument.
+	PromptPositional PromptMode = "positional"
+	// PromptFlag passes the prompt as the value of PromptFlag.
+	PromptFlag PromptMode = "flag"
+	// PromptStdin pipes the prompt into the agent's standard input.
+	PromptStdin PromptMode = "stdin"
+	// PromptFile writes the prompt to a file and passes its path, after
+	// PromptFlag if one is set. The file is created with process substitution,
+	// so the window's shell must be bash or zsh.
+	PromptFile PromptMode = "file"
+	// PromptNone drops the prompt.
+	PromptNone PromptMode = "none"
+)
+
+// PromptPlaceholder in an argument is replaced by the shell-quoted prompt,
+// and the argument is dropped when there is no prompt. An argument template
+// takes precedence over PromptMode.
+const PromptPlaceholder = "{prompt}"
+
+// CustomName is the profile name used for an agent given only by --command.
+const CustomName = "custom"
+
+// Profile describes how to start one kind of agent.
+type Profile struct {
+	Name       string     `json:"name" yaml:"name"`
+	Command    string     `json:"command" yaml:"command"`
+	Args       []string   `json:"args,omitempty" yaml:"args,omitempty"`
+	PromptMode PromptMode `json:"prompt_mode" yaml:"prompt_mode"`
+	PromptFlag string     `json:"prompt_flag,omitempty" yaml:"prompt_flag,omitempty"`
+	// ResumeFlag continues a previous conversation when followed by its ID.
+	// Only agents whose conversations claude-grid can locate set it.
+	ResumeFlag string `json:"resume_flag,omitempty" yaml:"resume_flag,omitempty"`
+	// HeadlessArgs precede Args when the agent runs without a terminal, making
+	// it process its prompt and exit instead of waiting for input.
+	HeadlessArgs []string `json:"headless_args,omitempty" yaml:"headless_args,omitempty"`
+}
+
+// builtins are the agents selectable with --agent. aider exits after answering
+// --message, so only its headless runs are given the prompt.
+var builtins = map[string]Profile{
+	"claude": {Name: "claude", Command: "claude", PromptMode: PromptPositional, ResumeFlag: claude.ResumeFlag, HeadlessArgs: []string{"-p"}},
+	"aider":  {Name: "aider", Command: "aider", PromptMode: PromptNone, HeadlessArgs: []string{"--yes-always", "--message=" + PromptPlaceholder}},
+	"codex":  {Name: "codex", Command: "codex", PromptMode: PromptPositional, HeadlessArgs: []string{"exec"}},
+	"shell":  {Name: "shell", PromptMode: PromptNone},
+}
+
+var installHints = map[string]string{
+	"claude": "Install: npm install -g @anthropic-ai/claude-code",
+	"aider":  "Install: python -m pip install aider-install && aider-install",
+	"codex":  "Install: npm install -g @openai/codex",
+}
+
+// Default returns the claude profile, used when no agent is selected and for
+// sessions stored before agents were configurable.
+func Default() Profile {
+	p, _ := Lookup("claude")
+	return p
+}
+
+// Lookup returns the built-in profile called name. The shell profile runs
+// $SHELL, falling back to /bin/sh.
+func Lookup(name string) (Profile, error) {
+	p, ok := builtins[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown agent %q. Available agents: %s, or use --command", name, strings.Join(Names(), ", "))
+	}
+	p.Args = append([]string(nil), p.Args...)
+	p.HeadlessArgs = append([]string(nil), p.HeadlessArgs...)
+	if p.Name == "shell" {
+		p.Command = os.Getenv("SHELL")
+		if p.Command == "" {
+			p.Command = "/bin/sh"
+		}
+	}
+	return p, nil
+}
+
+// Names returns the built-in profile names in sorted order.
+func Names() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithCommand returns p running commandLine instead of its own command and
+// arguments. commandLine is split like a shell would, honouring quotes.
+func (p Profile) WithCommand(commandLine string) (Profile, error) {
+	words, err := SplitCommand(commandLine)
+	if err != nil {
+		return Profile{}, err
+	}
+	if len(words) == 0 {
+		return Profile{}, fmt.Errorf("command is empty")
+	}
+	p.Command = words[0]
+	p.Args = words[1:]
+	return p, nil
+}
+
+// Validate checks the prompt settings and resolves Command with lookPath,
+// returning the resolved executable path.
+func (p Profile) Validate(lookPath func(file string) (string, error)) (string, error) {
+	switch p.PromptMode {
+	case PromptPositional, PromptStdin, PromptNone:
+	case PromptFlag:
+		if p.PromptFlag == "" {
+			return "", fmt.Errorf("prompt mode %q requires a prompt flag", p.PromptMode)
+		}
+	case PromptFile:
+	default:
+		return "", fmt.Errorf("unsupported prompt mode %q (supported: positional, flag, stdin, file, none)", p.PromptMode)
+	}
+
+	if strings.TrimSpace(p.Command) == "" {
+		return "", fmt.Errorf("agent %q has no command", p.Name)
+	}
+
+	path, err := lookPath(p.Command)
+	if err != nil {
+		msg := fmt.Sprintf("'%s' not found in PATH", p.Command)
+		if hint := installHints[p.Name]; hint != "" && p.Command == builtins[p.Name].Command {
+			msg += ". " + hint
+		}
+		return "", fmt.Errorf("%s", msg)
+	}
+	return path, nil
+}
+
+// AcceptsPrompt reports whether p passes prompts to the agent at all.
+func (p Profile) AcceptsPrompt() bool {
+	if p.PromptMode != PromptNone {
+		return true
+	}
+	for _, arg := range p.Args {
+		if strings.Contains(arg, PromptPlaceholder) {
+			return true
+		}
+	}
+	return false
+}
+
+// Headless returns p for running without a terminal: HeadlessArgs are moved
+// in front of Args. Agents without HeadlessArgs are returned unchanged, so
+// their command must already run non-interactively.
+func (p Profile) Headless() Profile {
+	if len(p.HeadlessArgs) == 0 {
+		return p
+	}
+	p.Args = append(append([]string(nil), p.HeadlessArgs...), p.Args...)
+	p.HeadlessArgs = nil
+	return p
+}
+
+// CanResume reports whether p can continue a previous conversation.
+func (p Profile) CanResume() bool {
+	return p.ResumeFlag != ""
+}
+
+// CommandLine returns the shell command line that starts the agent with
+// prompt, continuing conversationID when it is set and p can resume.
+func (p Profile) CommandLine(prompt, conversationID string) string {
+	hasPrompt := strings.TrimSpace(prompt) != ""
+
+	words := []string{shellWord(p.Command)}
+	templated := false
+	for _, arg := range p.Args {
+		if !strings.Contains(arg, PromptPlaceholder) {
+			words = append(words, shellWord(arg))
+			continue
+		}
+		templated = true
+		if hasPrompt {
+			words = append(words, expandTemplate(arg, prompt))
+		}
+	}
+
+	if conversationID != "" && p.CanResume() {
+		words = append(words, p.ResumeFlag, conversationID)
+	}
+
+	if !hasPrompt || templated {
+		return strings.Join(words, " ")
+	}
+
+	switch p.PromptMode {
+	case PromptPositional:
+		words = append(words, ShellQuote(prompt))
+	case PromptFlag:
+		words = append(words, shellWord(p.PromptFlag), ShellQuote(prompt))
+	case PromptFile:
+		if p.PromptFlag != "" {
+			words = append(words, shellWord(p.PromptFlag))
+		}
+		words = append(words, fmt.Sprintf("<(printf '%%s\\n' %s)", ShellQuote(prompt)))
+	case PromptStdin:
+		return fmt.Sprintf("printf '%%s\\n' %s | %s", ShellQuote(prompt), strings.Join(words, " "))
+	}
+	return strings.Join(words, " ")
+}
+
+// expandTemplate replaces each PromptPlaceholder in arg with the prompt,
+// quoting the literal parts and the prompt separately so the result is a
+// single shell word.
+func expandTemplate(arg, prompt string) string {
+	parts := strings.Split(arg, PromptPlaceholder)
+	var b strings.Builder
+	for i, part := range parts {
+		if i > 0 {
+			b.WriteString(ShellQuote(prompt))
+		}
+		if part != "" {
+			b.WriteString(shellWord(part))
+		}
+	}
+	return b.String()
+}
+
+// ShellQuote wraps s in single quotes for POSIX shells, escaping embedded single quotes.
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellWord quotes s only when it contains characters the shell would
+// interpret, so ordinary commands stay readable.
+func shellWord(s string) string {
+	if s == "" {
+		return "''"
+	}
+	for _, r := range s {
+		if !isSafeShellRune(r) {
+			return ShellQuote(s)
+		}
+	}
+	return s
+}
+
+func isSafeShellRune(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	}
+	return strings.ContainsRune("-_./:=@%+,", r)
+}
+
+// SplitCommand splits s into words the way a POSIX shell would for simple
+// commands: whitespace separates words, single quotes are literal, and double
+// quotes and backslashes escape. Expansions are not performed.
+func SplitCommand(s string) ([]string, error) {
+	var words []string
+	var current strings.Builder
+	inWord := false
+	var quote rune
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				if i+1 < len(runes) && strings.ContainsRune(`"\$`+"`", runes[i+1]) {
+					i++
+					current.WriteRune(runes[i])
+				} else {
+					current.WriteRune(r)
+				}
+			default:
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == '\\':
+			if i+1 < len(runes) {
+				i++
+				current.WriteRune(runes[i])
+			}
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in command %q", quote, s)
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
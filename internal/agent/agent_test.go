@@ -0,0 +1,186 @@
+package agent
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommandLine(t *testing.T) {
+	tests := []struct {
+		name           string
+		profile        Profile
+		prompt         string
+		conversationID string
+		want           string
+	}{
+		{
+			name:    "claude positional prompt",
+			profile: Default(),
+			prompt:  "it's broken",
+			want:    `claude 'it'\''s broken'`,
+		},
+		{
+			name:    "claude without prompt",
+			profile: Default(),
+			want:    "claude",
+		},
+		{
+			name:           "claude resumes instead of prompting",
+			profile:        Default(),
+			conversationID: "1205b5d1-2d9e-4429-8617-4c7087ce0bf6",
+			want:           "claude --resume 1205b5d1-2d9e-4429-8617-4c7087ce0bf6",
+		},
+		{
+			name:           "agent without resume ignores conversation",
+			profile:        Profile{Command: "codex", PromptMode: PromptPositional},
+			prompt:         "add tests",
+			conversationID: "1205b5d1-2d9e-4429-8617-4c7087ce0bf6",
+			want:           "codex 'add tests'",
+		},
+		{
+			name:    "flag mode with extra args",
+			profile: Profile{Command: "aider", Args: []string{"--model", "sonnet"}, PromptMode: PromptFlag, PromptFlag: "--message"},
+			prompt:  "fix login",
+			want:    "aider --model sonnet --message 'fix login'",
+		},
+		{
+			name:    "stdin mode",
+			profile: Profile{Command: "agent", PromptMode: PromptStdin},
+			prompt:  "fix login",
+			want:    `printf '%s\n' 'fix login' | agent`,
+		},
+		{
+			name:    "file mode",
+			profile: Profile{Command: "agent", PromptMode: PromptFile, PromptFlag: "--prompt-file"},
+			prompt:  "fix login",
+			want:    `agent --prompt-file <(printf '%s\n' 'fix login')`,
+		},
+		{
+			name:    "none mode drops prompt",
+			profile: Profile{Command: "/bin/zsh", PromptMode: PromptNone},
+			prompt:  "fix login",
+			want:    "/bin/zsh",
+		},
+		{
+			name:    "template argument",
+			profile: Profile{Command: "tool", Args: []string{"--task={prompt}", "--yes"}, PromptMode: PromptPositional},
+			prompt:  "fix login",
+			want:    "tool --task='fix login' --yes",
+		},
+		{
+			name:    "template argument dropped without prompt",
+			profile: Profile{Command: "tool", Args: []string{"--task={prompt}", "--yes"}, PromptMode: PromptPositional},
+			want:    "tool --yes",
+		},
+		{
+			name:    "command with spaces is quoted",
+			profile: Profile{Command: "/opt/my tools/agent", PromptMode: PromptPositional},
+			want:    "'/opt/my tools/agent'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.profile.CommandLine(tt.prompt, tt.conversationID); got != tt.want {
+				t.Errorf("CommandLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, name := range Names() {
+		p, err := Lookup(name)
+		if err != nil {
+			t.Fatalf("Lookup(%q) error = %v", name, err)
+		}
+		if p.Command == "" {
+			t.Errorf("Lookup(%q).Command is empty", name)
+		}
+	}
+
+	t.Setenv("SHELL", "/usr/bin/fish")
+	if p, _ := Lookup("Shell"); p.Command != "/usr/bin/fish" || p.AcceptsPrompt() {
+		t.Errorf("shell profile = %+v, want $SHELL without prompts", p)
+	}
+
+	if _, err := Lookup("gemini"); err == nil || !strings.Contains(err.Error(), "unknown agent") {
+		t.Errorf("Lookup(gemini) error = %v, want unknown agent", err)
+	}
+}
+
+func TestWithCommand(t *testing.T) {
+	p, err := Default().WithCommand(`/usr/local/bin/claude --model "opus 4" --add-dir '/tmp/a b'`)
+	if err != nil {
+		t.Fatalf("WithCommand() error = %v", err)
+	}
+	if p.Command != "/usr/local/bin/claude" {
+		t.Errorf("Command = %q", p.Command)
+	}
+	if want := []string{"--model", "opus 4", "--add-dir", "/tmp/a b"}; !reflect.DeepEqual(p.Args, want) {
+		t.Errorf("Args = %q, want %q", p.Args, want)
+	}
+	if p.ResumeFlag == "" {
+		t.Error("WithCommand() should keep the profile's resume flag")
+	}
+
+	if _, err := Default().WithCommand(`claude "unterminated`); err == nil {
+		t.Error("expected error for unterminated quote")
+	}
+	if _, err := Default().WithCommand("   "); err == nil {
+		t.Error("expected error for empty command")
+	}
+}
+
+func TestHeadless(t *testing.T) {
+	p, err := Default().WithCommand("claude --model opus")
+	if err != nil {
+		t.Fatalf("WithCommand() error = %v", err)
+	}
+	if got, want := p.Headless().CommandLine("fix it", ""), "claude -p --model opus 'fix it'"; got != want {
+		t.Errorf("Headless().CommandLine() = %q, want %q", got, want)
+	}
+	if len(p.Args) != 2 {
+		t.Errorf("Headless() modified the original profile: Args = %q", p.Args)
+	}
+
+	// aider only receives the prompt headless, where answering it and exiting is wanted.
+	aider, _ := Lookup("aider")
+	if got, want := aider.CommandLine("fix it", ""), "aider"; got != want {
+		t.Errorf("aider CommandLine() = %q, want %q", got, want)
+	}
+	if got, want := aider.Headless().CommandLine("fix it", ""), "aider --yes-always --message='fix it'"; got != want {
+		t.Errorf("aider Headless().CommandLine() = %q, want %q", got, want)
+	}
+	if !aider.Headless().AcceptsPrompt() {
+		t.Error("headless aider should accept prompts")
+	}
+
+	custom := Profile{Name: CustomName, Command: "run-agent", PromptMode: PromptPositional}
+	if got := custom.Headless(); !reflect.DeepEqual(got, custom) {
+		t.Errorf("Headless() = %+v, want profile unchanged", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	found := func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	missing := func(string) (string, error) { return "", errors.New("not found") }
+
+	if path, err := Default().Validate(found); err != nil || path != "/usr/bin/claude" {
+		t.Errorf("Validate() = %q, %v", path, err)
+	}
+
+	_, err := Default().Validate(missing)
+	if err == nil || !strings.Contains(err.Error(), "'claude' not found in PATH") || !strings.Contains(err.Error(), "npm install") {
+		t.Errorf("Validate() error = %v, want not found with install hint", err)
+	}
+
+	if _, err := (Profile{Command: "x", PromptMode: PromptFlag}).Validate(found); err == nil {
+		t.Error("expected error for flag mode without prompt flag")
+	}
+	if _, err := (Profile{Command: "x", PromptMode: "magic"}).Validate(found); err == nil {
+		t.Error("expected error for unknown prompt mode")
+	}
+}
@@ -0,0 +1,82 @@
+// Package agentstate classifies what the agent in a window is doing from the
+// text the window shows.
+package agentstate
+
+import (
+	"strings"
+)
+
+// State is what an agent is doing.
+type State string
+
+const (
+	// Unknown means the state could not be determined, e.g. because the
+	// backend cannot read window text.
+	Unknown State = "unknown"
+	// Working means the agent is busy and can be interrupted.
+	Working State = "working"
+	// Waiting means the agent is asking for permission or input.
+	Waiting State = "waiting"
+	// Idle means the agent finished and is at its prompt.
+	Idle State = "idle"
+	// Exited means the window, and with it the agent, is gone.
+	Exited State = "exited"
+)
+
+// tailLines is how many of the last non-blank lines are inspected. Prompts
+// and status lines live at the bottom of the screen; older ones higher up
+// have usually been answered already.
+const tailLines = 15
+
+// waitingMarkers appear while an agent asks a question. Matching is
+// case-insensitive.
+var waitingMarkers = []string{
+	"do you want to",
+	"would you like to",
+	"❯ 1. yes",
+	"(y/n)",
+	"[y/n]",
+	"(y)es/(n)o",
+	"press enter to continue",
+	"waiting for your input",
+}
+
+// workingMarkers appear while an agent is busy.
+var workingMarkers = []string{
+	"esc to interrupt",
+	"ctrl+c to interrupt",
+}
+
+// Classify returns the state shown by the captured text of a window whose
+// agent is still running. Questions win over activity, since an agent that
+// asks for permission mid-task still shows its spinner line.
+func Classify(text string) State {
+	tail := strings.ToLower(strings.Join(lastLines(text, tailLines), "\n"))
+
+	for _, marker := range waitingMarkers {
+		if strings.Contains(tail, marker) {
+			return Waiting
+		}
+	}
+	for _, marker := range workingMarkers {
+		if strings.Contains(tail, marker) {
+			return Working
+		}
+	}
+	return Idle
+}
+
+// lastLines returns up to n of the last non-blank lines of text.
+func lastLines(text string, n int) []string {
+	var lines []string
+	all := strings.Split(text, "\n")
+	for i := len(all) - 1; i >= 0 && len(lines) < n; i-- {
+		if strings.TrimSpace(all[i]) != "" {
+			lines = append(lines, all[i])
+		}
+	}
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return lines
+}
@@ -0,0 +1,53 @@
+package agentstate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want State
+	}{
+		{
+			name: "permission prompt",
+			text: "● Bash(go test ./...)\n\n Do you want to proceed?\n ❯ 1. Yes\n   2. No, and tell Claude what to do differently (esc)\n",
+			want: Waiting,
+		},
+		{
+			name: "aider confirmation",
+			text: "Add internal/api/handler.go to the chat? (Y)es/(N)o [Yes]:",
+			want: Waiting,
+		},
+		{
+			name: "working spinner",
+			text: "● Reading files\n\n✻ Pondering… (12s · esc to interrupt)\n\n> \n",
+			want: Working,
+		},
+		{
+			name: "idle prompt",
+			text: "● Done. All tests pass.\n\n╭──────────╮\n│ >        │\n╰──────────╯\n  ? for shortcuts\n",
+			want: Idle,
+		},
+		{
+			name: "empty screen",
+			text: "",
+			want: Idle,
+		},
+		{
+			name: "answered question scrolled away",
+			text: "Do you want to proceed?\n" + strings.Repeat("output line\n", 20) + "> \n",
+			want: Idle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.text); got != tt.want {
+				t.Errorf("Classify() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
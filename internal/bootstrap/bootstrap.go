@@ -0,0 +1,270 @@
+// Package bootstrap prepares fresh git worktrees for their agents: it copies
+// untracked and ignored files such as .env files and installed dependencies
+// from the main checkout, then runs setup commands in each worktree.
+package bootstrap
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds the setup commands of one worktree when Options sets
+// no timeout.
+const DefaultTimeout = 10 * time.Minute
+
+// Copy modes.
+const (
+	// ModeCopy copies file contents.
+	ModeCopy = "copy"
+	// ModeHardlink links files into the worktree, copying where linking fails
+	// (e.g. across file systems). Edits in one worktree show up in all.
+	ModeHardlink = "hardlink"
+	// ModeReflink clones files copy-on-write where the file system supports
+	// it (APFS, Btrfs, XFS) and copies them otherwise.
+	ModeReflink = "reflink"
+)
+
+// Options describes how to bootstrap worktrees.
+type Options struct {
+	// Source is the main checkout files are copied from.
+	Source string
+	// Copy lists glob patterns relative to Source, as understood by
+	// filepath.Glob. Matched directories are copied recursively. Files that
+	// already exist in a worktree, such as tracked files, are left alone.
+	Copy []string
+	// Mode is ModeCopy, ModeHardlink or ModeReflink; empty means ModeCopy.
+	Mode string
+	// Setup lists commands run with sh -c in each worktree, in order. The
+	// first failing command stops the worktree's setup.
+	Setup []string
+	// Timeout bounds all setup commands of one worktree; zero means
+	// DefaultTimeout. A command that runs out of time is killed along with
+	// every process it started.
+	Timeout time.Duration
+}
+
+// Result is the outcome of bootstrapping one worktree.
+type Result struct {
+	Worktree string
+	// Copied lists the matched paths that were copied, relative to Source.
+	Copied []string
+	// Output is the combined output of the setup commands that ran.
+	Output string
+	// Err describes the first failure; nil when the worktree is ready.
+	Err error
+}
+
+// RunAll bootstraps every worktree concurrently and returns their results in
+// the order of worktrees.
+func RunAll(ctx context.Context, opts Options, worktrees []string) []Result {
+	results := make([]Result, len(worktrees))
+	var wg sync.WaitGroup
+	for i, worktree := range worktrees {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = Run(ctx, opts, worktree)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// Run copies the files matched by opts.Copy into worktree, then runs the
+// setup commands in it.
+func Run(ctx context.Context, opts Options, worktree string) Result {
+	result := Result{Worktree: worktree}
+
+	copied, err := copyMatches(opts, worktree)
+	result.Copied = copied
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	for _, command := range opts.Setup {
+		fmt.Fprintf(&output, "$ %s\n", command)
+		cmd := exec.CommandContext(ctx, "sh", "-c", command)
+		cmd.Dir = worktree
+		cmd.Stdout = &output
+		cmd.Stderr = &output
+		setProcessGroup(cmd)
+		cmd.WaitDelay = time.Second
+		err := cmd.Run()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			result.Err = fmt.Errorf("setup command %q timed out after %s", command, timeout)
+			break
+		}
+		if err != nil {
+			result.Err = fmt.Errorf("setup command %q failed: %w", command, err)
+			break
+		}
+	}
+	result.Output = output.String()
+	return result
+}
+
+// copyMatches copies every path matched by opts.Copy from opts.Source into
+// worktree, returning the matched paths relative to opts.Source.
+func copyMatches(opts Options, worktree string) ([]string, error) {
+	var copied []string
+	for _, pattern := range opts.Copy {
+		if filepath.IsAbs(pattern) {
+			return copied, fmt.Errorf("copy pattern %q must be relative to the repository", pattern)
+		}
+		matches, err := filepath.Glob(filepath.Join(opts.Source, pattern))
+		if err != nil {
+			return copied, fmt.Errorf("invalid copy pattern %q: %w", pattern, err)
+		}
+		for _, match := range matches {
+			rel, err := filepath.Rel(opts.Source, match)
+			if err != nil || !inCheckout(rel) {
+				continue
+			}
+			target := filepath.Join(worktree, rel)
+			if opts.Mode == ModeReflink {
+				// Whole trees are cloned with one cp, which matters for
+				// directories like node_modules.
+				if _, err := os.Lstat(target); os.IsNotExist(err) {
+					if reflink(match, target) == nil {
+						copied = append(copied, rel)
+						continue
+					}
+					os.RemoveAll(target)
+				}
+			}
+			if err := copyTree(match, target, opts.Mode); err != nil {
+				return copied, fmt.Errorf("copy %s: %w", rel, err)
+			}
+			copied = append(copied, rel)
+		}
+	}
+	return copied, nil
+}
+
+// inCheckout reports whether rel, a path relative to the main checkout, names
+// something inside it other than the checkout itself or its .git.
+func inCheckout(rel string) bool {
+	if rel == "." || !filepath.IsLocal(rel) {
+		return false
+	}
+	first, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+	return first != ".git"
+}
+
+// copyTree copies src to dst, recursing into directories and recreating
+// symlinks. Existing files at the destination are kept.
+func copyTree(src, dst, mode string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		case d.Type()&fs.ModeSymlink != 0:
+			if _, err := os.Lstat(target); err == nil {
+				return nil
+			}
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			if _, err := os.Lstat(target); err == nil {
+				return nil
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			return copyFile(path, target, info.Mode().Perm(), mode)
+		default:
+			// Sockets, pipes and devices are not copied.
+			return nil
+		}
+	})
+}
+
+// copyFile copies a regular file with the given mode, falling back to a
+// plain copy when linking or cloning is not possible.
+func copyFile(src, dst string, perm fs.FileMode, mode string) error {
+	switch mode {
+	case ModeHardlink:
+		if os.Link(src, dst) == nil {
+			return nil
+		}
+	case ModeReflink:
+		if reflink(src, dst) == nil {
+			return nil
+		}
+		os.Remove(dst)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// reflink clones the file or directory src to dst with cp, which uses
+// clonefile on macOS and the FICLONE ioctl on Linux. It fails where the file
+// system cannot clone.
+func reflink(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	var args []string
+	switch runtime.GOOS {
+	case "darwin":
+		args = []string{"-c", "-R", "-p", src, dst}
+	case "linux":
+		args = []string{"--reflink=always", "-R", "-p", src, dst}
+	default:
+		return fmt.Errorf("reflink is not supported on %s", runtime.GOOS)
+	}
+	return exec.Command("cp", args...).Run()
+}
@@ -0,0 +1,152 @@
+package bootstrap
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	return string(data)
+}
+
+func TestRunCopiesMatches(t *testing.T) {
+	for _, mode := range []string{ModeCopy, ModeHardlink, ModeReflink} {
+		t.Run(mode, func(t *testing.T) {
+			source := t.TempDir()
+			worktree := t.TempDir()
+			writeFile(t, filepath.Join(source, ".env"), "SECRET=1\n")
+			writeFile(t, filepath.Join(source, ".env.local"), "LOCAL=1\n")
+			writeFile(t, filepath.Join(source, "node_modules", "left-pad", "index.js"), "pad\n")
+			writeFile(t, filepath.Join(source, "README.md"), "main checkout\n")
+			writeFile(t, filepath.Join(worktree, "README.md"), "tracked\n")
+
+			result := Run(context.Background(), Options{
+				Source: source,
+				Copy:   []string{".env*", "node_modules", "README.md", "missing/*"},
+				Mode:   mode,
+			}, worktree)
+			if result.Err != nil {
+				t.Fatalf("Run() error = %v", result.Err)
+			}
+
+			if got := readFile(t, filepath.Join(worktree, ".env")); got != "SECRET=1\n" {
+				t.Errorf(".env = %q", got)
+			}
+			if got := readFile(t, filepath.Join(worktree, "node_modules", "left-pad", "index.js")); got != "pad\n" {
+				t.Errorf("node_modules file = %q", got)
+			}
+			if got := readFile(t, filepath.Join(worktree, "README.md")); got != "tracked\n" {
+				t.Errorf("README.md = %q, existing files must not be overwritten", got)
+			}
+			if len(result.Copied) != 4 {
+				t.Errorf("Copied = %v, want 4 matches", result.Copied)
+			}
+		})
+	}
+}
+
+func TestRunRejectsAbsolutePattern(t *testing.T) {
+	result := Run(context.Background(), Options{Source: t.TempDir(), Copy: []string{"/etc/passwd"}}, t.TempDir())
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "relative") {
+		t.Errorf("Run() error = %v, want relative pattern error", result.Err)
+	}
+}
+
+func TestRunSkipsPathsOutsideCheckout(t *testing.T) {
+	root := t.TempDir()
+	source := filepath.Join(root, "repo")
+	worktree := t.TempDir()
+	writeFile(t, filepath.Join(root, "sibling", "secret.txt"), "outside\n")
+	writeFile(t, filepath.Join(source, ".git", "config"), "[core]\n")
+	writeFile(t, filepath.Join(source, ".env"), "SECRET=1\n")
+
+	result := Run(context.Background(), Options{
+		Source: source,
+		Copy:   []string{"..", "../sibling", "sub/../..", ".", ".git", ".git/*", ".env"},
+	}, worktree)
+	if result.Err != nil {
+		t.Fatalf("Run() error = %v", result.Err)
+	}
+	if len(result.Copied) != 1 || result.Copied[0] != ".env" {
+		t.Errorf("Copied = %v, want only .env", result.Copied)
+	}
+	for _, name := range []string{"repo", "sibling", "secret.txt", "config"} {
+		if _, err := os.Stat(filepath.Join(worktree, name)); !os.IsNotExist(err) {
+			t.Errorf("%s was copied into the worktree: %v", name, err)
+		}
+	}
+}
+
+func TestRunSetup(t *testing.T) {
+	worktree := t.TempDir()
+	result := Run(context.Background(), Options{
+		Setup: []string{"echo installing > installed.txt; echo done", "echo broken >&2; exit 2", "touch never"},
+	}, worktree)
+
+	if result.Err == nil || !strings.Contains(result.Err.Error(), `"echo broken >&2; exit 2" failed`) {
+		t.Errorf("Run() error = %v, want second command failure", result.Err)
+	}
+	if !strings.Contains(result.Output, "done") || !strings.Contains(result.Output, "broken") {
+		t.Errorf("Output = %q, want output of both commands", result.Output)
+	}
+	if got := readFile(t, filepath.Join(worktree, "installed.txt")); got != "installing\n" {
+		t.Errorf("setup did not run in the worktree: %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(worktree, "never")); err == nil {
+		t.Error("commands after a failure should not run")
+	}
+}
+
+func TestRunSetupTimeout(t *testing.T) {
+	start := time.Now()
+	result := Run(context.Background(), Options{Setup: []string{"sleep 30"}, Timeout: 100 * time.Millisecond}, t.TempDir())
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "timed out") {
+		t.Errorf("Run() error = %v, want timeout", result.Err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("timeout took %s", time.Since(start))
+	}
+}
+
+func TestRunSetupTimeoutKillsChildren(t *testing.T) {
+	worktree := t.TempDir()
+	// sh forks the subshell, so it outlives a kill of sh alone.
+	result := Run(context.Background(), Options{Setup: []string{"(sleep 1 && touch late) && echo done"}, Timeout: 100 * time.Millisecond}, worktree)
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "timed out") {
+		t.Fatalf("Run() error = %v, want timeout", result.Err)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	if _, err := os.Stat(filepath.Join(worktree, "late")); !os.IsNotExist(err) {
+		t.Error("setup command kept changing the worktree after the timeout")
+	}
+}
+
+func TestRunAllKeepsOrder(t *testing.T) {
+	worktrees := []string{t.TempDir(), t.TempDir(), t.TempDir()}
+	results := RunAll(context.Background(), Options{Setup: []string{"pwd"}}, worktrees)
+	for i, r := range results {
+		if r.Worktree != worktrees[i] || r.Err != nil {
+			t.Errorf("results[%d] = %+v, want success for %s", i, r, worktrees[i])
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package claude locates the conversation transcripts Claude Code writes under
+// its config directory so claude-grid can resume them.
+package claude
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ResumeFlag is the claude CLI flag that continues a previous conversation.
+const ResumeFlag = "--resume"
+
+var (
+	nonAlphanumeric  = regexp.MustCompile(`[^a-zA-Z0-9]`)
+	conversationIDRe = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// Conversation is a Claude Code transcript found on disk.
+type Conversation struct {
+	ID      string
+	ModTime time.Time
+}
+
+// Locator finds transcripts under a Claude Code config directory.
+type Locator struct {
+	projectsDir string
+}
+
+// NewLocator creates a Locator rooted at configDir. If configDir is empty,
+// $CLAUDE_CONFIG_DIR is used, falling back to ~/.claude.
+func NewLocator(configDir string) *Locator {
+	if configDir == "" {
+		configDir = os.Getenv("CLAUDE_CONFIG_DIR")
+	}
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			configDir = "~/.claude"
+		} else {
+			configDir = filepath.Join(home, ".claude")
+		}
+	}
+	return &Locator{projectsDir: filepath.Join(configDir, "projects")}
+}
+
+// ProjectDir returns the directory holding transcripts for conversations
+// started in cwd. Claude Code names it after cwd with every non-alphanumeric
+// character replaced by '-'.
+func (l *Locator) ProjectDir(cwd string) string {
+	return filepath.Join(l.projectsDir, nonAlphanumeric.ReplaceAllString(cwd, "-"))
+}
+
+// RecentConversations returns conversations started in cwd that were written
+// to at or after since, newest first. A missing project directory yields no
+// conversations rather than an error.
+func (l *Locator) RecentConversations(cwd string, since time.Time) ([]Conversation, error) {
+	dir := l.ProjectDir(cwd)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read transcript directory %q: %w", dir, err)
+	}
+
+	var conversations []Conversation
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".jsonl" {
+			continue
+		}
+
+		id := strings.TrimSuffix(entry.Name(), ".jsonl")
+		if !IsConversationID(id) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().Before(since) {
+			continue
+		}
+
+		conversations = append(conversations, Conversation{ID: id, ModTime: info.ModTime()})
+	}
+
+	sort.Slice(conversations, func(i, j int) bool {
+		return conversations[i].ModTime.After(conversations[j].ModTime)
+	})
+
+	return conversations, nil
+}
+
+// IsConversationID reports whether id looks like a Claude Code session UUID.
+// IDs are interpolated into shell commands, so anything else is rejected.
+func IsConversationID(id string) bool {
+	return conversationIDRe.MatchString(id)
+}
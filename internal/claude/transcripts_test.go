@@ -0,0 +1,101 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTranscript(t *testing.T, dir, name string, modTime time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("{}\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+}
+
+func TestProjectDir(t *testing.T) {
+	l := NewLocator("/cfg")
+	tests := []struct {
+		cwd  string
+		want string
+	}{
+		{cwd: "/root/module", want: "/cfg/projects/-root-module"},
+		{cwd: "/Users/bob/.claude-grid/worktrees/brave-fox-1_abc", want: "/cfg/projects/-Users-bob--claude-grid-worktrees-brave-fox-1-abc"},
+	}
+	for _, tt := range tests {
+		if got := l.ProjectDir(tt.cwd); got != tt.want {
+			t.Errorf("ProjectDir(%q) = %q, want %q", tt.cwd, got, tt.want)
+		}
+	}
+}
+
+func TestNewLocatorUsesConfigDirEnv(t *testing.T) {
+	t.Setenv("CLAUDE_CONFIG_DIR", "/env/claude")
+	l := NewLocator("")
+	if got := l.ProjectDir("/a"); got != "/env/claude/projects/-a" {
+		t.Errorf("ProjectDir() = %q, want %q", got, "/env/claude/projects/-a")
+	}
+}
+
+func TestRecentConversations(t *testing.T) {
+	configDir := t.TempDir()
+	l := NewLocator(configDir)
+	dir := l.ProjectDir("/work/repo")
+
+	now := time.Now()
+	older := "11111111-1111-1111-1111-111111111111"
+	newer := "22222222-2222-2222-2222-222222222222"
+	stale := "33333333-3333-3333-3333-333333333333"
+	writeTranscript(t, dir, older+".jsonl", now.Add(-time.Hour))
+	writeTranscript(t, dir, newer+".jsonl", now)
+	writeTranscript(t, dir, stale+".jsonl", now.Add(-48*time.Hour))
+	writeTranscript(t, dir, "not-a-uuid.jsonl", now)
+	writeTranscript(t, dir, "44444444-4444-4444-4444-444444444444.txt", now)
+
+	got, err := l.RecentConversations("/work/repo", now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("RecentConversations() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("len(conversations) = %d, want 2: %+v", len(got), got)
+	}
+	if got[0].ID != newer || got[1].ID != older {
+		t.Errorf("conversation order = [%s, %s], want [%s, %s]", got[0].ID, got[1].ID, newer, older)
+	}
+}
+
+func TestRecentConversationsMissingDir(t *testing.T) {
+	l := NewLocator(t.TempDir())
+	got, err := l.RecentConversations("/never/used", time.Time{})
+	if err != nil {
+		t.Fatalf("RecentConversations() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("RecentConversations() = %+v, want none", got)
+	}
+}
+
+func TestIsConversationID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{id: "1205b5d1-2d9e-4429-8617-4c7087ce0bf6", want: true},
+		{id: "1205b5d1-2d9e-4429-8617", want: false},
+		{id: "1205b5d1-2d9e-4429-8617-4c7087ce0bf6; rm -rf", want: false},
+		{id: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := IsConversationID(tt.id); got != tt.want {
+			t.Errorf("IsConversationID(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,413 @@
+// Package config loads claude-grid's global and project config files and
+// resolves the spawn settings they, a named profile and CLAUDE_GRID_*
+// environment variables provide.
+//
+// Precedence, lowest to highest: global defaults, project defaults, the
+// selected profile, environment variables, then command-line flags (applied
+// by the caller).
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ProjectFileName is the project-local config file, found by walking up
+	// from the working directory.
+	ProjectFileName = ".claude-grid.yaml"
+
+	// EnvPrefix prefixes the environment variables that override settings.
+	EnvPrefix = "CLAUDE_GRID_"
+)
+
+// Settings are the spawn options a config file, profile or environment
+// variable can default. Zero values mean "not set".
+type Settings struct {
+	Terminal     string `yaml:"terminal,omitempty"`
+	Layout       string `yaml:"layout,omitempty"`
+	Count        int    `yaml:"count,omitempty"`
+	Worktrees    *bool  `yaml:"worktrees,omitempty"`
+	Hooks        *bool  `yaml:"hooks,omitempty"`
+	BranchPrefix string `yaml:"branch_prefix,omitempty"`
+	Base         string `yaml:"base,omitempty"`
+	Agent        string `yaml:"agent,omitempty"`
+	Command      string `yaml:"command,omitempty"`
+	PromptMode   string `yaml:"prompt_mode,omitempty"`
+	PromptFlag   string `yaml:"prompt_flag,omitempty"`
+}
+
+// Notify configures the notifications sent when an agent starts waiting for
+// input. Zero values mean "not set".
+type Notify struct {
+	Command string `yaml:"command,omitempty"`
+	Bell    *bool  `yaml:"bell,omitempty"`
+	Desktop *bool  `yaml:"desktop,omitempty"`
+}
+
+// Bootstrap prepares each new worktree before its agent starts. It usually
+// lives in the project config file of the repository. Zero values mean "not
+// set".
+type Bootstrap struct {
+	// Copy lists glob patterns, relative to the main checkout, of untracked
+	// or ignored files and directories to copy into each worktree.
+	Copy []string `yaml:"copy,omitempty"`
+	// CopyMode is how files are copied: copy, hardlink or reflink.
+	CopyMode string `yaml:"copy_mode,omitempty"`
+	// Setup lists commands run with sh -c in each worktree, in order.
+	Setup []string `yaml:"setup,omitempty"`
+	// Timeout bounds how long the setup commands of one worktree may run.
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// File is the content of a config file.
+type File struct {
+	Defaults  Settings            `yaml:"defaults"`
+	Profiles  map[string]Settings `yaml:"profiles"`
+	Notify    Notify              `yaml:"notify"`
+	Bootstrap Bootstrap           `yaml:"bootstrap"`
+}
+
+// Config is the merged content of the global and project config files.
+type Config struct {
+	Defaults Settings
+	Profiles map[string]Settings
+	Notify   Notify
+	// Bootstrap is merged field by field, so a project file replaces the
+	// global copy list or setup commands rather than extending them.
+	Bootstrap Bootstrap
+	// Sources lists the config files that were read, global first.
+	Sources []string
+	// Project is the project config file that was read, if any.
+	Project string
+	// SetupSource is the config file the bootstrap setup commands come from.
+	SetupSource string
+	// Ignored lists the settings of the project config file that were not
+	// applied because only the global config file may set them, e.g.
+	// "notify.command".
+	Ignored []string
+}
+
+// GlobalPath returns the global config file: $CLAUDE_GRID_CONFIG if set,
+// otherwise ~/.claude-grid/config.yaml.
+func GlobalPath() string {
+	if path := os.Getenv(EnvPrefix + "CONFIG"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "~/.claude-grid/config.yaml"
+	}
+	return filepath.Join(home, ".claude-grid", "config.yaml")
+}
+
+// FindProjectFile returns the nearest ProjectFileName in dir or one of its
+// parents, or "" if there is none.
+func FindProjectFile(dir string) string {
+	for {
+		path := filepath.Join(dir, ProjectFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Load reads the global config file at globalPath and the project config file
+// found from cwd, merging project values over global ones. Missing files are
+// skipped.
+func Load(globalPath, cwd string) (Config, error) {
+	cfg := Config{Profiles: map[string]Settings{}}
+
+	paths := []string{globalPath}
+	if project := FindProjectFile(cwd); project != "" && project != globalPath {
+		paths = append(paths, project)
+	}
+
+	for _, path := range paths {
+		file, err := readFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Config{}, err
+		}
+		if path != globalPath {
+			cfg.Ignored = append(cfg.Ignored, file.dropCommands()...)
+		}
+
+		cfg.Defaults = cfg.Defaults.Merge(file.Defaults)
+		cfg.Notify = cfg.Notify.Merge(file.Notify)
+		cfg.Bootstrap = cfg.Bootstrap.Merge(file.Bootstrap)
+		if file.Bootstrap.Setup != nil {
+			cfg.SetupSource = path
+		}
+		for name, profile := range file.Profiles {
+			cfg.Profiles[name] = cfg.Profiles[name].Merge(profile)
+		}
+		cfg.Sources = append(cfg.Sources, path)
+		if path != globalPath {
+			cfg.Project = path
+		}
+	}
+
+	return cfg, nil
+}
+
+// Resolve returns the settings for profile (none if empty; $CLAUDE_GRID_PROFILE
+// is used when profile is empty) with environment overrides applied. getenv
+// is usually os.Getenv.
+func (c Config) Resolve(profile string, getenv func(string) string) (Settings, error) {
+	settings := c.Defaults
+
+	if profile == "" {
+		profile = getenv(EnvPrefix + "PROFILE")
+	}
+	if profile != "" {
+		p, ok := c.Profiles[profile]
+		if !ok {
+			return Settings{}, fmt.Errorf("unknown profile %q%s", profile, c.availableProfiles())
+		}
+		settings = settings.Merge(p)
+	}
+
+	env, err := envSettings(getenv)
+	if err != nil {
+		return Settings{}, err
+	}
+	return settings.Merge(env), nil
+}
+
+// Merge returns s with every field set in over replacing its own.
+func (s Settings) Merge(over Settings) Settings {
+	if over.Terminal != "" {
+		s.Terminal = over.Terminal
+	}
+	if over.Layout != "" {
+		s.Layout = over.Layout
+	}
+	if over.Count != 0 {
+		s.Count = over.Count
+	}
+	if over.Worktrees != nil {
+		worktrees := *over.Worktrees
+		s.Worktrees = &worktrees
+	}
+	if over.Hooks != nil {
+		hooks := *over.Hooks
+		s.Hooks = &hooks
+	}
+	if over.BranchPrefix != "" {
+		s.BranchPrefix = over.BranchPrefix
+	}
+	if over.Base != "" {
+		s.Base = over.Base
+	}
+	if over.Agent != "" {
+		s.Agent = over.Agent
+	}
+	if over.Command != "" {
+		s.Command = over.Command
+	}
+	if over.PromptMode != "" {
+		s.PromptMode = over.PromptMode
+	}
+	if over.PromptFlag != "" {
+		s.PromptFlag = over.PromptFlag
+	}
+	return s
+}
+
+// Merge returns n with every field set in over replacing its own.
+func (n Notify) Merge(over Notify) Notify {
+	if over.Command != "" {
+		n.Command = over.Command
+	}
+	if over.Bell != nil {
+		bell := *over.Bell
+		n.Bell = &bell
+	}
+	if over.Desktop != nil {
+		desktop := *over.Desktop
+		n.Desktop = &desktop
+	}
+	return n
+}
+
+// Merge returns b with every field set in over replacing its own.
+func (b Bootstrap) Merge(over Bootstrap) Bootstrap {
+	if over.Copy != nil {
+		b.Copy = over.Copy
+	}
+	if over.CopyMode != "" {
+		b.CopyMode = over.CopyMode
+	}
+	if over.Setup != nil {
+		b.Setup = over.Setup
+	}
+	if over.Timeout != 0 {
+		b.Timeout = over.Timeout
+	}
+	return b
+}
+
+// Enabled reports whether b has anything to do.
+func (b Bootstrap) Enabled() bool {
+	return len(b.Copy) > 0 || len(b.Setup) > 0
+}
+
+// dropCommands clears the settings of f that decide a command claude-grid
+// runs, returning their keys. A project config file comes with the
+// repository, so these are only read from the global config file.
+func (f *File) dropCommands() []string {
+	dropped := f.Defaults.dropCommandLine("defaults.")
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		profile := f.Profiles[name]
+		if keys := profile.dropCommandLine("profiles." + name + "."); len(keys) > 0 {
+			f.Profiles[name] = profile
+			dropped = append(dropped, keys...)
+		}
+	}
+	if f.Notify.Command != "" {
+		f.Notify.Command = ""
+		dropped = append(dropped, "notify.command")
+	}
+	return dropped
+}
+
+// dropCommandLine clears the settings of s that shape the agent's command
+// line: the command, and the prompt mode and flag, which can add any flag to
+// it. It returns their keys, prefixed with prefix.
+func (s *Settings) dropCommandLine(prefix string) []string {
+	var dropped []string
+	for _, field := range []struct {
+		key   string
+		value *string
+	}{
+		{"command", &s.Command},
+		{"prompt_mode", &s.PromptMode},
+		{"prompt_flag", &s.PromptFlag},
+	} {
+		if *field.value != "" {
+			*field.value = ""
+			dropped = append(dropped, prefix+field.key)
+		}
+	}
+	return dropped
+}
+
+func readFile(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, err
+	}
+
+	var file File
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return File{}, fmt.Errorf("parse config %q: %w", path, err)
+	}
+	if err := file.Defaults.validate(); err != nil {
+		return File{}, fmt.Errorf("config %q: defaults: %w", path, err)
+	}
+	for name, profile := range file.Profiles {
+		if err := profile.validate(); err != nil {
+			return File{}, fmt.Errorf("config %q: profile %q: %w", path, name, err)
+		}
+	}
+	if err := file.Bootstrap.validate(); err != nil {
+		return File{}, fmt.Errorf("config %q: bootstrap: %w", path, err)
+	}
+	return file, nil
+}
+
+func (s Settings) validate() error {
+	if s.Count < 0 || s.Count > 16 {
+		return fmt.Errorf("count %d must be between 1 and 16", s.Count)
+	}
+	return nil
+}
+
+func (b Bootstrap) validate() error {
+	switch b.CopyMode {
+	case "", "copy", "hardlink", "reflink":
+	default:
+		return fmt.Errorf("unsupported copy_mode %q (supported: copy, hardlink, reflink)", b.CopyMode)
+	}
+	if b.Timeout < 0 {
+		return fmt.Errorf("timeout %s must be positive", b.Timeout)
+	}
+	return nil
+}
+
+func envSettings(getenv func(string) string) (Settings, error) {
+	s := Settings{
+		Terminal:     getenv(EnvPrefix + "TERMINAL"),
+		Layout:       getenv(EnvPrefix + "LAYOUT"),
+		BranchPrefix: getenv(EnvPrefix + "BRANCH_PREFIX"),
+		Base:         getenv(EnvPrefix + "BASE"),
+		Agent:        getenv(EnvPrefix + "AGENT"),
+		Command:      getenv(EnvPrefix + "COMMAND"),
+		PromptMode:   getenv(EnvPrefix + "PROMPT_MODE"),
+		PromptFlag:   getenv(EnvPrefix + "PROMPT_FLAG"),
+	}
+
+	if value := getenv(EnvPrefix + "COUNT"); value != "" {
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return Settings{}, fmt.Errorf("invalid %sCOUNT %q: must be a number", EnvPrefix, value)
+		}
+		s.Count = count
+	}
+	if value := getenv(EnvPrefix + "WORKTREES"); value != "" {
+		worktrees, err := strconv.ParseBool(value)
+		if err != nil {
+			return Settings{}, fmt.Errorf("invalid %sWORKTREES %q: must be true or false", EnvPrefix, value)
+		}
+		s.Worktrees = &worktrees
+	}
+	if value := getenv(EnvPrefix + "HOOKS"); value != "" {
+		hooks, err := strconv.ParseBool(value)
+		if err != nil {
+			return Settings{}, fmt.Errorf("invalid %sHOOKS %q: must be true or false", EnvPrefix, value)
+		}
+		s.Hooks = &hooks
+	}
+
+	if err := s.validate(); err != nil {
+		return Settings{}, fmt.Errorf("%sCOUNT: %w", EnvPrefix, err)
+	}
+	return s, nil
+}
+
+func (c Config) availableProfiles() string {
+	if len(c.Profiles) == 0 {
+		return "; no profiles are defined"
+	}
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return ". Available profiles: " + strings.Join(names, ", ")
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TrustFileName is the file, next to the global config file, recording which
+// project config files may run their bootstrap setup commands.
+const TrustFileName = "trusted.yaml"
+
+// trustFile maps the path of a project config file to the hash of the setup
+// commands that were trusted.
+type trustFile struct {
+	Setup map[string]string `yaml:"setup"`
+}
+
+// TrustPath returns the trust file next to the global config file.
+func TrustPath() string {
+	return filepath.Join(filepath.Dir(GlobalPath()), TrustFileName)
+}
+
+// SetupTrusted reports whether the bootstrap setup commands of c may run:
+// commands from the global config file always may, while those from the
+// project config file, which comes with the repository, must have been
+// trusted with Trust since they last changed. trustPath is usually TrustPath.
+func (c Config) SetupTrusted(trustPath string) (bool, error) {
+	if len(c.Bootstrap.Setup) == 0 || c.Project == "" || c.SetupSource != c.Project {
+		return true, nil
+	}
+	trust, err := readTrustFile(trustPath)
+	if err != nil {
+		return false, err
+	}
+	return trust.Setup[c.Project] == setupHash(c.Bootstrap.Setup), nil
+}
+
+// Trust records in the trust file at trustPath that the setup commands of
+// the project config file at project may run until they change.
+func Trust(trustPath, project string, setup []string) error {
+	trust, err := readTrustFile(trustPath)
+	if err != nil {
+		return err
+	}
+	if trust.Setup == nil {
+		trust.Setup = map[string]string{}
+	}
+	trust.Setup[project] = setupHash(setup)
+
+	data, err := yaml.Marshal(trust)
+	if err != nil {
+		return fmt.Errorf("encode trust file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(trustPath), 0755); err != nil {
+		return fmt.Errorf("create trust file directory: %w", err)
+	}
+	if err := os.WriteFile(trustPath, data, 0644); err != nil {
+		return fmt.Errorf("write trust file: %w", err)
+	}
+	return nil
+}
+
+func readTrustFile(path string) (trustFile, error) {
+	var trust trustFile
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return trust, nil
+	}
+	if err != nil {
+		return trust, fmt.Errorf("read trust file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &trust); err != nil {
+		return trust, fmt.Errorf("parse trust file %q: %w", path, err)
+	}
+	return trust, nil
+}
+
+func setupHash(setup []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(setup, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
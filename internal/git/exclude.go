@@ -0,0 +1,124 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Exclude adds pattern to the exclude file of the repository checked out at
+// dir, so git treats matching untracked files as ignored without a change to
+// .gitignore. The exclude file is shared by every worktree of the repository.
+// The pattern is written after marker, a comment line, which tells Unexclude
+// it was added by Exclude. A pattern already listed is not added again.
+func Exclude(dir, pattern, marker string) error {
+	path, err := excludeFile(dir)
+	if err != nil {
+		return err
+	}
+	lines, err := readExcludeFile(path)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if line == pattern {
+			return nil
+		}
+	}
+	return writeExcludeFile(path, append(lines, marker, pattern))
+}
+
+// Unexclude removes pattern, and the marker before it, from the exclude file
+// of the repository checked out at dir. A pattern that is not listed after
+// marker, such as one the user added, is left alone.
+func Unexclude(dir, pattern, marker string) error {
+	path, err := excludeFile(dir)
+	if err != nil {
+		return err
+	}
+	lines, err := readExcludeFile(path)
+	if err != nil {
+		return err
+	}
+	kept := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); i++ {
+		if lines[i] == marker && i+1 < len(lines) && lines[i+1] == pattern {
+			i++
+			continue
+		}
+		kept = append(kept, lines[i])
+	}
+	if len(kept) == len(lines) {
+		return nil
+	}
+	return writeExcludeFile(path, kept)
+}
+
+// Tracked reports whether path, relative to the checkout at dir, is tracked
+// by git.
+func Tracked(dir, path string) (bool, error) {
+	if _, err := runGitIn(dir, "rev-parse", "--git-dir"); err != nil {
+		return false, err
+	}
+	_, err := runGitIn(dir, "ls-files", "--error-unmatch", "--", path)
+	return err == nil, nil
+}
+
+// WorktreePaths returns the paths of every worktree of the repository checked
+// out at dir, the main checkout first.
+func WorktreePaths(dir string) ([]string, error) {
+	output, err := runGitRaw(dir, "worktree", "list", "--porcelain", "-z")
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, field := range nulFields(output) {
+		if path, ok := strings.CutPrefix(field, "worktree "); ok {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// excludeFile returns the path of the exclude file of the repository checked
+// out at dir.
+func excludeFile(dir string) (string, error) {
+	path, err := runGitIn(dir, "rev-parse", "--git-path", "info/exclude")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return path, nil
+}
+
+func readExcludeFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", path, err)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"), nil
+}
+
+func writeExcludeFile(path string, lines []string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %q: %w", filepath.Dir(path), err)
+	}
+	data := strings.Join(lines, "\n")
+	if len(lines) > 0 {
+		data += "\n"
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		return fmt.Errorf("failed to write %q: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExcludeInWorktree(t *testing.T) {
+	repoPath := initGitRepo(t)
+	manager, err := NewManager(repoPath)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	manager.worktreeBase = filepath.Join(t.TempDir(), "worktrees")
+	worktreePath, err := manager.CreateWorktree("feature-exclude")
+	if err != nil {
+		t.Fatalf("CreateWorktree() error = %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(worktreePath, ".tool"), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(worktreePath, ".tool", "state.json"), []byte("{}\n"), 0644); err != nil {
+		t.Fatalf("failed to write state.json: %v", err)
+	}
+
+	// Excluding twice must not list the pattern twice.
+	for range 2 {
+		if err := Exclude(worktreePath, "/.tool/state.json", "# tool"); err != nil {
+			t.Fatalf("Exclude() error = %v", err)
+		}
+	}
+	if status, err := Status(worktreePath); err != nil || status.Uncommitted != 0 {
+		t.Errorf("Status() = %+v, %v; want no uncommitted files", status, err)
+	}
+	data, err := os.ReadFile(filepath.Join(repoPath, ".git", "info", "exclude"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if got := string(data); !containsLineOnce(got, "/.tool/state.json") {
+		t.Errorf("exclude file = %q, want pattern listed once", got)
+	}
+
+	if err := Unexclude(worktreePath, "/.tool/state.json", "# tool"); err != nil {
+		t.Fatalf("Unexclude() error = %v", err)
+	}
+	if status, err := Status(worktreePath); err != nil || status.Uncommitted != 1 {
+		t.Errorf("Status() = %+v, %v; want the untracked file back", status, err)
+	}
+	if err := Unexclude(worktreePath, "/.tool/state.json", "# tool"); err != nil {
+		t.Errorf("Unexclude() of missing pattern error = %v, want nil", err)
+	}
+
+	paths, err := WorktreePaths(worktreePath)
+	if err != nil {
+		t.Fatalf("WorktreePaths() error = %v", err)
+	}
+	if len(paths) != 2 || filepath.Base(paths[1]) != filepath.Base(worktreePath) {
+		t.Errorf("WorktreePaths() = %v, want main checkout and %q", paths, worktreePath)
+	}
+}
+
+func TestUnexcludeKeepsUserPatterns(t *testing.T) {
+	repoPath := initGitRepo(t)
+	exclude := filepath.Join(repoPath, ".git", "info", "exclude")
+	if err := os.WriteFile(exclude, []byte("/.tool/state.json\n"), 0644); err != nil {
+		t.Fatalf("failed to write exclude file: %v", err)
+	}
+
+	if err := Exclude(repoPath, "/.tool/state.json", "# tool"); err != nil {
+		t.Fatalf("Exclude() error = %v", err)
+	}
+	if err := Unexclude(repoPath, "/.tool/state.json", "# tool"); err != nil {
+		t.Fatalf("Unexclude() error = %v", err)
+	}
+	data, err := os.ReadFile(exclude)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if got := string(data); got != "/.tool/state.json\n" {
+		t.Errorf("exclude file = %q, want the user's pattern kept", got)
+	}
+}
+
+func TestTracked(t *testing.T) {
+	repoPath := initGitRepo(t)
+	if err := os.WriteFile(filepath.Join(repoPath, "new.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatalf("failed to write new.txt: %v", err)
+	}
+
+	for path, want := range map[string]bool{"README.md": true, "new.txt": false, "missing.txt": false} {
+		if got, err := Tracked(repoPath, path); err != nil || got != want {
+			t.Errorf("Tracked(%q) = %v, %v; want %v", path, got, err, want)
+		}
+	}
+	if _, err := Tracked(t.TempDir(), "README.md"); err == nil {
+		t.Error("Tracked() outside a repository error = nil, want error")
+	}
+}
+
+func containsLineOnce(text, line string) bool {
+	count := 0
+	for _, l := range nonEmptyLines(text) {
+		if l == line {
+			count++
+		}
+	}
+	return count == 1
+}
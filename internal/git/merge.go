@@ -0,0 +1,225 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// MergeStrategy is how branches are integrated into the target branch.
+type MergeStrategy string
+
+const (
+	// MergeCommit merges each branch with a merge commit.
+	MergeCommit MergeStrategy = "merge"
+	// MergeSquash adds each branch's changes as a single new commit.
+	MergeSquash MergeStrategy = "squash"
+	// MergeRebase replays each branch's commits on top of the target.
+	MergeRebase MergeStrategy = "rebase"
+)
+
+// Outcomes of integrating one branch.
+const (
+	MergeMerged   = "merged"
+	MergeUpToDate = "up-to-date"
+	MergeConflict = "conflict"
+	MergeSkipped  = "skipped"
+)
+
+// MergeOptions configures MergeBranches.
+type MergeOptions struct {
+	Strategy MergeStrategy
+	// DryRun integrates the branches without updating the target branch.
+	DryRun bool
+}
+
+// BranchMerge is the outcome of integrating one branch.
+type BranchMerge struct {
+	Branch string
+	// Result is MergeMerged, MergeUpToDate, MergeConflict, or MergeSkipped
+	// for branches after a conflict.
+	Result string
+	// Commit is the target's commit after the branch was integrated.
+	Commit string
+	// Conflicts lists the conflicting files of a MergeConflict.
+	Conflicts []string
+}
+
+// MergeReport describes a MergeBranches run.
+type MergeReport struct {
+	Target string
+	// Before and After are the target's commits before and after the run.
+	// After equals Before on a dry run or when nothing was merged.
+	Before   string
+	After    string
+	Branches []BranchMerge
+}
+
+// Conflict returns the branch that conflicted, if any.
+func (r MergeReport) Conflict() (BranchMerge, bool) {
+	for _, b := range r.Branches {
+		if b.Result == MergeConflict {
+			return b, true
+		}
+	}
+	return BranchMerge{}, false
+}
+
+// MergeBranches integrates branches into the local branch target in order,
+// stopping at the first conflict. The work happens in a temporary worktree,
+// so neither the main checkout nor the branches' worktrees are touched.
+// Unless opts.DryRun is set, target is then advanced over the branches that
+// were integrated before any conflict: fast-forwarded in the worktree that
+// has it checked out, or moved directly if none does.
+func (m *Manager) MergeBranches(target string, branches []string, opts MergeOptions) (MergeReport, error) {
+	report := MergeReport{Target: target}
+
+	if !m.BranchExists(target) {
+		return report, fmt.Errorf("target branch %q does not exist", target)
+	}
+	before, err := runGitIn(m.repoPath, "rev-parse", "refs/heads/"+target)
+	if err != nil {
+		return report, err
+	}
+	report.Before, report.After = before, before
+
+	scratch, err := os.MkdirTemp("", "claude-grid-merge-")
+	if err != nil {
+		return report, fmt.Errorf("failed to create merge worktree: %w", err)
+	}
+	defer os.RemoveAll(scratch)
+	if _, err := runGitIn(m.repoPath, "worktree", "add", "--detach", scratch, before); err != nil {
+		return report, err
+	}
+	defer func() {
+		_, _ = runGitIn(m.repoPath, "worktree", "remove", "--force", scratch)
+		_ = m.Prune()
+	}()
+
+	head := before
+	for i, branch := range branches {
+		result, err := integrate(scratch, head, branch, opts.Strategy)
+		if err != nil {
+			return report, fmt.Errorf("integrate branch %q: %w", branch, err)
+		}
+		report.Branches = append(report.Branches, result)
+		if result.Result == MergeConflict {
+			for _, rest := range branches[i+1:] {
+				report.Branches = append(report.Branches, BranchMerge{Branch: rest, Result: MergeSkipped})
+			}
+			break
+		}
+		head = result.Commit
+	}
+
+	if opts.DryRun || head == before {
+		return report, nil
+	}
+	if err := m.advanceBranch(target, before, head); err != nil {
+		return report, err
+	}
+	report.After = head
+	return report, nil
+}
+
+// integrate applies branch on top of head in the scratch worktree and
+// returns the outcome. Conflicts are aborted, leaving the worktree at head.
+func integrate(scratch, head, branch string, strategy MergeStrategy) (BranchMerge, error) {
+	result := BranchMerge{Branch: branch, Commit: head}
+
+	if _, err := runGitIn(scratch, "merge-base", "--is-ancestor", "refs/heads/"+branch, head); err == nil {
+		result.Result = MergeUpToDate
+		return result, nil
+	}
+
+	var err error
+	switch strategy {
+	case MergeSquash:
+		if _, err = runGitIn(scratch, "merge", "--squash", "refs/heads/"+branch); err == nil {
+			if staged, _ := runGitIn(scratch, "diff", "--cached", "--name-only"); staged == "" {
+				result.Result = MergeUpToDate
+				return result, nil
+			}
+			if _, err := runGitIn(scratch, "commit", "--no-edit", "--no-verify"); err != nil {
+				return result, err
+			}
+		}
+	case MergeRebase:
+		if _, err := runGitIn(scratch, "checkout", "--quiet", "--detach", "refs/heads/"+branch); err != nil {
+			return result, err
+		}
+		_, err = runGitIn(scratch, "rebase", "--quiet", head)
+	default:
+		_, err = runGitIn(scratch, "merge", "--no-ff", "--no-edit", "--no-verify", "-m", fmt.Sprintf("Merge branch '%s'", branch), "refs/heads/"+branch)
+	}
+
+	if err != nil {
+		conflicts, _ := runGitIn(scratch, "diff", "--name-only", "--diff-filter=U")
+		if conflicts == "" {
+			return result, err
+		}
+		result.Result = MergeConflict
+		result.Conflicts = strings.Split(conflicts, "\n")
+		if strategy == MergeRebase {
+			_, _ = runGitIn(scratch, "rebase", "--abort")
+		}
+		if _, err := runGitIn(scratch, "reset", "--hard", "--quiet", head); err != nil {
+			return result, err
+		}
+		_, _ = runGitIn(scratch, "checkout", "--quiet", "--detach", head)
+		return result, nil
+	}
+
+	commit, err := runGitIn(scratch, "rev-parse", "HEAD")
+	if err != nil {
+		return result, err
+	}
+	// A rebase whose commits are all already applied leaves head as it was.
+	if commit == head {
+		result.Result = MergeUpToDate
+		return result, nil
+	}
+	result.Result = MergeMerged
+	result.Commit = commit
+	return result, nil
+}
+
+// advanceBranch moves branch from before to after. A branch checked out in
+// a worktree is fast-forwarded there, which fails rather than overwrite local
+// changes; otherwise the ref is updated only if it still points at before.
+func (m *Manager) advanceBranch(branch, before, after string) error {
+	list, err := runGitIn(m.repoPath, "worktree", "list", "--porcelain")
+	if err != nil {
+		return err
+	}
+	var path string
+	for _, line := range strings.Split(list, "\n") {
+		if p, ok := strings.CutPrefix(line, "worktree "); ok {
+			path = p
+		}
+		if line == "branch refs/heads/"+branch {
+			if _, err := runGitIn(path, "merge", "--ff-only", "--quiet", after); err != nil {
+				return fmt.Errorf("failed to fast-forward %q, checked out in %s: %w", branch, path, err)
+			}
+			return nil
+		}
+	}
+
+	if _, err := runGitIn(m.repoPath, "update-ref", "-m", "claude-grid merge", "refs/heads/"+branch, after, before); err != nil {
+		return fmt.Errorf("failed to update %q: %w", branch, err)
+	}
+	return nil
+}
+
+// CurrentBranch returns the branch checked out in the main checkout, or an
+// error if HEAD is detached.
+func (m *Manager) CurrentBranch() (string, error) {
+	branch, err := runGitIn(m.repoPath, "branch", "--show-current")
+	if err != nil {
+		return "", err
+	}
+	if branch == "" {
+		return "", fmt.Errorf("HEAD is detached in %s", m.repoPath)
+	}
+	return branch, nil
+}
@@ -0,0 +1,160 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// commitOnBranch creates branch from base with one commit writing content
+// to file, leaving the main checkout where it was.
+func commitOnBranch(t *testing.T, repoPath, branch, base, file, content string) {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), branch)
+	runGit(t, repoPath, "worktree", "add", "-b", branch, dir, base)
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", file, err)
+	}
+	runGit(t, dir, "add", file)
+	runGit(t, dir, "commit", "-m", "change "+file+" on "+branch)
+	runGit(t, repoPath, "worktree", "remove", "--force", dir)
+}
+
+func mergeTestRepo(t *testing.T) (*Manager, string) {
+	t.Helper()
+
+	repoPath := initGitRepo(t)
+	commitOnBranch(t, repoPath, "grid-1", "HEAD", "one.txt", "one\n")
+	commitOnBranch(t, repoPath, "grid-2", "HEAD", "two.txt", "two\n")
+	commitOnBranch(t, repoPath, "grid-3", "HEAD", "one.txt", "three\n")
+
+	manager, err := NewManager(repoPath)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	return manager, runGit(t, repoPath, "branch", "--show-current")
+}
+
+func mergeResults(report MergeReport) []string {
+	var results []string
+	for _, b := range report.Branches {
+		results = append(results, b.Branch+"="+b.Result)
+	}
+	return results
+}
+
+func TestMergeBranches(t *testing.T) {
+	for _, strategy := range []MergeStrategy{MergeCommit, MergeSquash, MergeRebase} {
+		t.Run(string(strategy), func(t *testing.T) {
+			manager, target := mergeTestRepo(t)
+			repoPath := manager.RepoPath()
+
+			report, err := manager.MergeBranches(target, []string{"grid-1", "grid-2"}, MergeOptions{Strategy: strategy})
+			if err != nil {
+				t.Fatalf("MergeBranches() error = %v", err)
+			}
+			if want := []string{"grid-1=merged", "grid-2=merged"}; !reflect.DeepEqual(mergeResults(report), want) {
+				t.Fatalf("results = %v, want %v", mergeResults(report), want)
+			}
+			if head := runGit(t, repoPath, "rev-parse", "HEAD"); head != report.After || report.After == report.Before {
+				t.Errorf("HEAD = %s, want target advanced to %s", head, report.After)
+			}
+			for _, file := range []string{"one.txt", "two.txt"} {
+				if _, err := os.Stat(filepath.Join(repoPath, file)); err != nil {
+					t.Errorf("%s missing from the checked out target: %v", file, err)
+				}
+			}
+
+			merges := runGit(t, repoPath, "rev-list", "--merges", "--count", report.Before+".."+report.After)
+			wantMerges := "0"
+			if strategy == MergeCommit {
+				wantMerges = "2"
+			}
+			if merges != wantMerges {
+				t.Errorf("merge commits = %s, want %s", merges, wantMerges)
+			}
+
+			again, err := manager.MergeBranches(target, []string{"grid-1"}, MergeOptions{Strategy: strategy})
+			if err != nil {
+				t.Fatalf("second MergeBranches() error = %v", err)
+			}
+			if again.Branches[0].Result != MergeUpToDate || again.After != report.After {
+				t.Errorf("second merge result = %s, want %s", again.Branches[0].Result, MergeUpToDate)
+			}
+		})
+	}
+}
+
+func TestMergeBranchesStopsOnConflict(t *testing.T) {
+	manager, target := mergeTestRepo(t)
+	repoPath := manager.RepoPath()
+
+	report, err := manager.MergeBranches(target, []string{"grid-1", "grid-3", "grid-2"}, MergeOptions{Strategy: MergeCommit})
+	if err != nil {
+		t.Fatalf("MergeBranches() error = %v", err)
+	}
+	if want := []string{"grid-1=merged", "grid-3=conflict", "grid-2=skipped"}; !reflect.DeepEqual(mergeResults(report), want) {
+		t.Fatalf("results = %v, want %v", mergeResults(report), want)
+	}
+	conflict, ok := report.Conflict()
+	if !ok || conflict.Branch != "grid-3" || !reflect.DeepEqual(conflict.Conflicts, []string{"one.txt"}) {
+		t.Errorf("Conflict() = %+v, %v; want grid-3 conflicting in one.txt", conflict, ok)
+	}
+
+	// The branch merged before the conflict is kept.
+	if head := runGit(t, repoPath, "rev-parse", "HEAD"); head != report.After || head != report.Branches[0].Commit {
+		t.Errorf("HEAD = %s, want %s", head, report.Branches[0].Commit)
+	}
+	if status := runGit(t, repoPath, "status", "--porcelain"); status != "" {
+		t.Errorf("main checkout left dirty: %q", status)
+	}
+	if list := runGit(t, repoPath, "worktree", "list", "--porcelain"); strings.Count(list, "worktree ") != 1 {
+		t.Errorf("temporary worktree not removed:\n%s", list)
+	}
+}
+
+func TestMergeBranchesDryRun(t *testing.T) {
+	manager, target := mergeTestRepo(t)
+	repoPath := manager.RepoPath()
+	before := runGit(t, repoPath, "rev-parse", "HEAD")
+
+	report, err := manager.MergeBranches(target, []string{"grid-1", "grid-2", "grid-3"}, MergeOptions{Strategy: MergeRebase, DryRun: true})
+	if err != nil {
+		t.Fatalf("MergeBranches() error = %v", err)
+	}
+	if want := []string{"grid-1=merged", "grid-2=merged", "grid-3=conflict"}; !reflect.DeepEqual(mergeResults(report), want) {
+		t.Errorf("results = %v, want %v", mergeResults(report), want)
+	}
+	if head := runGit(t, repoPath, "rev-parse", "HEAD"); head != before || report.After != before {
+		t.Errorf("dry run moved the target: HEAD = %s, After = %s, want %s", head, report.After, before)
+	}
+}
+
+func TestMergeBranchesTargetNotCheckedOut(t *testing.T) {
+	manager, target := mergeTestRepo(t)
+	repoPath := manager.RepoPath()
+	runGit(t, repoPath, "branch", "release", target)
+
+	report, err := manager.MergeBranches("release", []string{"grid-2"}, MergeOptions{Strategy: MergeSquash})
+	if err != nil {
+		t.Fatalf("MergeBranches() error = %v", err)
+	}
+	if got := runGit(t, repoPath, "rev-parse", "release"); got != report.After || got == report.Before {
+		t.Errorf("release = %s, want %s", got, report.After)
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, "two.txt")); !os.IsNotExist(err) {
+		t.Errorf("main checkout changed although %s was not the target", target)
+	}
+}
+
+func TestMergeBranchesMissingTarget(t *testing.T) {
+	manager, _ := mergeTestRepo(t)
+
+	_, err := manager.MergeBranches("nope", []string{"grid-1"}, MergeOptions{})
+	if err == nil || !strings.Contains(err.Error(), `target branch "nope" does not exist`) {
+		t.Errorf("MergeBranches() error = %v, want missing target error", err)
+	}
+}
@@ -0,0 +1,101 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Commit describes a single commit.
+type Commit struct {
+	Hash    string
+	Subject string
+	Time    time.Time
+}
+
+// WorktreeStatus summarizes the state of a checkout.
+type WorktreeStatus struct {
+	// Branch is the checked-out branch, or empty when HEAD is detached.
+	Branch string
+	// Uncommitted is the number of files with staged, unstaged or untracked changes.
+	Uncommitted int
+	// LastCommit is HEAD's commit, zero in a repository without commits.
+	LastCommit Commit
+}
+
+// Status reports the branch, uncommitted file count and last commit of the
+// checkout at dir.
+func Status(dir string) (WorktreeStatus, error) {
+	var status WorktreeStatus
+
+	branch, err := runGitIn(dir, "branch", "--show-current")
+	if err != nil {
+		return WorktreeStatus{}, err
+	}
+	status.Branch = branch
+
+	porcelain, err := runGitIn(dir, "status", "--porcelain")
+	if err != nil {
+		return WorktreeStatus{}, err
+	}
+	if porcelain != "" {
+		status.Uncommitted = len(strings.Split(porcelain, "\n"))
+	}
+
+	// A repository without commits has no HEAD to log.
+	if _, err := runGitIn(dir, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		return status, nil
+	}
+
+	last, err := runGitIn(dir, "log", "-1", "--format="+commitFormat)
+	if err != nil {
+		return WorktreeStatus{}, err
+	}
+	status.LastCommit, err = parseCommit(last)
+	if err != nil {
+		return WorktreeStatus{}, err
+	}
+
+	return status, nil
+}
+
+// commitFormat is the git log format read by parseCommit.
+const commitFormat = "%h%x00%ct%x00%s"
+
+// parseCommit parses one line of git log output in commitFormat.
+func parseCommit(line string) (Commit, error) {
+	fields := strings.SplitN(line, "\x00", 3)
+	if len(fields) != 3 {
+		return Commit{}, fmt.Errorf("unexpected git log output %q", line)
+	}
+	seconds, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return Commit{}, fmt.Errorf("unexpected commit time %q: %w", fields[1], err)
+	}
+	return Commit{Hash: fields[0], Subject: fields[2], Time: time.Unix(seconds, 0)}, nil
+}
+
+// runGitIn runs git in dir and returns its trimmed standard output.
+func runGitIn(dir string, args ...string) (string, error) {
+	output, err := runGitRaw(dir, args...)
+	return strings.TrimSpace(output), err
+}
+
+// runGitRaw runs git in dir and returns its standard output unchanged, for
+// output whose leading whitespace is significant.
+func runGitRaw(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	output, err := cmd.Output()
+	if err != nil {
+		var stderr string
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			stderr = strings.TrimSpace(string(exitErr.Stderr))
+		}
+		return "", fmt.Errorf("git %s failed in %q: %w (output: %s)", args[0], dir, err, stderr)
+	}
+	return string(output), nil
+}
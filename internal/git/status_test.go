@@ -0,0 +1,56 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStatus(t *testing.T) {
+	repoPath := initGitRepo(t)
+	runGit(t, repoPath, "checkout", "-b", "feature-status")
+
+	if err := os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("changed\n"), 0644); err != nil {
+		t.Fatalf("failed to write README.md: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, "new.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatalf("failed to write new.txt: %v", err)
+	}
+
+	status, err := Status(repoPath)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+
+	if status.Branch != "feature-status" {
+		t.Errorf("Branch = %q, want %q", status.Branch, "feature-status")
+	}
+	if status.Uncommitted != 2 {
+		t.Errorf("Uncommitted = %d, want 2", status.Uncommitted)
+	}
+	if status.LastCommit.Subject != "init" {
+		t.Errorf("LastCommit.Subject = %q, want %q", status.LastCommit.Subject, "init")
+	}
+	if status.LastCommit.Hash == "" || status.LastCommit.Time.IsZero() {
+		t.Errorf("LastCommit = %+v, want hash and time", status.LastCommit)
+	}
+}
+
+func TestStatusWithoutCommits(t *testing.T) {
+	repoPath := t.TempDir()
+	runGit(t, repoPath, "init")
+
+	status, err := Status(repoPath)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if status.LastCommit != (Commit{}) {
+		t.Errorf("LastCommit = %+v, want zero", status.LastCommit)
+	}
+}
+
+func TestStatusNonGitDir(t *testing.T) {
+	if _, err := Status(t.TempDir()); err == nil {
+		t.Fatal("Status() error = nil, want error")
+	}
+}
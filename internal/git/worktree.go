@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 )
@@ -45,7 +46,62 @@ func NewManager(dir string) (*Manager, error) {
 	}, nil
 }
 
+// CreateWorktree creates a worktree on a new branch starting at HEAD of the
+// main checkout.
 func (m *Manager) CreateWorktree(branchName string) (string, error) {
+	return m.CreateWorktreeFrom(branchName, "HEAD")
+}
+
+// CreateWorktreeFrom creates a worktree on a new branch starting at base, any
+// ref or commit of the repository.
+func (m *Manager) CreateWorktreeFrom(branchName, base string) (string, error) {
+	baseSHA, err := m.ResolveRef(base)
+	if err != nil {
+		return "", err
+	}
+	return m.addWorktree(branchName, baseSHA)
+}
+
+// CheckoutWorktree creates a worktree on the existing local branch
+// branchName, so an agent can continue work already started on it.
+func (m *Manager) CheckoutWorktree(branchName string) (string, error) {
+	if !m.BranchExists(branchName) {
+		return "", fmt.Errorf("branch %q does not exist", branchName)
+	}
+	return m.addWorktree(branchName, "")
+}
+
+// ResolveRef returns the commit SHA ref points to, failing if ref does not
+// name a commit.
+func (m *Manager) ResolveRef(ref string) (string, error) {
+	if strings.TrimSpace(ref) == "" || strings.HasPrefix(ref, "-") {
+		return "", fmt.Errorf("invalid base ref %q", ref)
+	}
+	cmd := exec.Command("git", "-C", m.repoPath, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	output, err := cmd.CombinedOutput()
+	outputStr := strings.TrimSpace(string(output))
+	if err != nil {
+		return "", fmt.Errorf("base ref %q does not exist or is not a commit: %w (output: %s)", ref, err, outputStr)
+	}
+	return outputStr, nil
+}
+
+// BranchExists reports whether branchName is a local branch.
+func (m *Manager) BranchExists(branchName string) bool {
+	cmd := exec.Command("git", "-C", m.repoPath, "show-ref", "--verify", "--quiet", "refs/heads/"+branchName)
+	return cmd.Run() == nil
+}
+
+// BranchContains reports whether commit is reachable from the local branch
+// branchName.
+func (m *Manager) BranchContains(branchName, commit string) bool {
+	cmd := exec.Command("git", "-C", m.repoPath, "merge-base", "--is-ancestor", commit, "refs/heads/"+branchName)
+	return cmd.Run() == nil
+}
+
+// addWorktree adds a worktree for branchName at a new path, creating the
+// branch at startSHA if set and checking out the existing branch otherwise.
+func (m *Manager) addWorktree(branchName, startSHA string) (string, error) {
 	if err := os.MkdirAll(m.worktreeBase, 0755); err != nil {
 		return "", fmt.Errorf("failed to create worktree base directory %q: %w", m.worktreeBase, err)
 	}
@@ -57,21 +113,20 @@ func (m *Manager) CreateWorktree(branchName string) (string, error) {
 		return "", fmt.Errorf("failed to list worktrees: %w (output: %s)", err, listOutputStr)
 	}
 
-	if strings.Contains(listOutputStr, "branch refs/heads/"+branchName) {
+	if slices.Contains(strings.Split(listOutputStr, "\n"), "branch refs/heads/"+branchName) {
 		return "", fmt.Errorf("branch %q is already checked out in another worktree. Run `claude-grid clean` to remove stale worktrees (output: %s)", branchName, listOutputStr)
 	}
 
-	cmdHead := exec.Command("git", "-C", m.repoPath, "rev-parse", "HEAD")
-	headOutput, err := cmdHead.CombinedOutput()
-	headOutputStr := strings.TrimSpace(string(headOutput))
-	if err != nil {
-		return "", fmt.Errorf("failed to resolve HEAD SHA: %w (output: %s)", err, headOutputStr)
-	}
-
 	suffix := fmt.Sprintf("%x", time.Now().UnixNano())
 	worktreePath := filepath.Join(m.worktreeBase, fmt.Sprintf("%s_%s", branchName, suffix))
 
-	cmdAdd := exec.Command("git", "-C", m.repoPath, "worktree", "add", "-b", branchName, worktreePath, headOutputStr)
+	args := []string{"-C", m.repoPath, "worktree", "add"}
+	if startSHA != "" {
+		args = append(args, "-b", branchName, worktreePath, startSHA)
+	} else {
+		args = append(args, worktreePath, branchName)
+	}
+	cmdAdd := exec.Command("git", args...)
 	addOutput, err := cmdAdd.CombinedOutput()
 	addOutputStr := strings.TrimSpace(string(addOutput))
 	if err != nil {
@@ -108,6 +163,17 @@ func (m *Manager) RemoveWorktree(worktreePath string) error {
 	return nil
 }
 
+// DeleteBranch deletes the local branch branchName, even if it has commits
+// that are not merged anywhere.
+func (m *Manager) DeleteBranch(branchName string) error {
+	cmd := exec.Command("git", "-C", m.repoPath, "branch", "-D", branchName)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to delete branch %q: %w (output: %s)", branchName, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 func (m *Manager) Prune() error {
 	cmd := exec.Command("git", "-C", m.repoPath, "worktree", "prune")
 	output, err := cmd.CombinedOutput()
@@ -109,6 +109,79 @@ func TestCreateWorktreeBranchAlreadyExists(t *testing.T) {
 	}
 }
 
+func TestCreateWorktreeFromBase(t *testing.T) {
+	repoPath := initGitRepo(t)
+
+	manager, err := NewManager(repoPath)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	manager.worktreeBase = filepath.Join(t.TempDir(), "worktrees")
+
+	baseSHA := runGit(t, repoPath, "rev-parse", "HEAD")
+	runGit(t, repoPath, "tag", "v1")
+	runGit(t, repoPath, "commit", "--allow-empty", "-m", "later")
+
+	if got, err := manager.ResolveRef("v1"); err != nil || got != baseSHA {
+		t.Fatalf("ResolveRef(v1) = %q, %v; want %q", got, err, baseSHA)
+	}
+
+	worktreePath, err := manager.CreateWorktreeFrom("from-tag", "v1")
+	if err != nil {
+		t.Fatalf("CreateWorktreeFrom() error = %v", err)
+	}
+	if head := runGit(t, worktreePath, "rev-parse", "HEAD"); head != baseSHA {
+		t.Errorf("worktree HEAD = %q, want base %q", head, baseSHA)
+	}
+
+	_, err = manager.CreateWorktreeFrom("from-missing", "no-such-ref")
+	if err == nil || !strings.Contains(err.Error(), `base ref "no-such-ref" does not exist`) {
+		t.Errorf("CreateWorktreeFrom() error = %v, want missing base ref", err)
+	}
+	if manager.BranchExists("from-missing") {
+		t.Error("branch was created for a missing base ref")
+	}
+}
+
+func TestCheckoutWorktree(t *testing.T) {
+	repoPath := initGitRepo(t)
+
+	manager, err := NewManager(repoPath)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	manager.worktreeBase = filepath.Join(t.TempDir(), "worktrees")
+
+	runGit(t, repoPath, "checkout", "-q", "-b", "human-started")
+	runGit(t, repoPath, "commit", "--allow-empty", "-m", "work in progress")
+	tip := runGit(t, repoPath, "rev-parse", "HEAD")
+	runGit(t, repoPath, "checkout", "-q", "-")
+
+	if start := runGit(t, repoPath, "rev-parse", "HEAD"); !manager.BranchContains("human-started", start) {
+		t.Error("BranchContains() = false for the commit the branch started from")
+	}
+	if manager.BranchContains(runGit(t, repoPath, "branch", "--show-current"), tip) {
+		t.Error("BranchContains() = true for a commit only on another branch")
+	}
+
+	worktreePath, err := manager.CheckoutWorktree("human-started")
+	if err != nil {
+		t.Fatalf("CheckoutWorktree() error = %v", err)
+	}
+	if head := runGit(t, worktreePath, "rev-parse", "HEAD"); head != tip {
+		t.Errorf("worktree HEAD = %q, want branch tip %q", head, tip)
+	}
+	if branch := runGit(t, worktreePath, "branch", "--show-current"); branch != "human-started" {
+		t.Errorf("worktree branch = %q, want human-started", branch)
+	}
+
+	if _, err := manager.CheckoutWorktree("never-created"); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("CheckoutWorktree() error = %v, want missing branch", err)
+	}
+}
+
 func TestCreateWorktreeBranchAlreadyCheckedOut(t *testing.T) {
 	repoPath := initGitRepo(t)
 
@@ -141,6 +214,28 @@ func TestCreateWorktreeBranchAlreadyCheckedOut(t *testing.T) {
 	}
 }
 
+func TestCheckoutWorktreePrefixOfCheckedOutBranch(t *testing.T) {
+	repoPath := initGitRepo(t)
+
+	manager, err := NewManager(repoPath)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	manager.worktreeBase = filepath.Join(t.TempDir(), "worktrees")
+
+	runGit(t, repoPath, "branch", "feat")
+	runGit(t, repoPath, "checkout", "-q", "-b", "feature")
+
+	// feature is checked out, but feat is not.
+	if _, err := manager.CheckoutWorktree("feat"); err != nil {
+		t.Fatalf("CheckoutWorktree() error = %v", err)
+	}
+	if _, err := manager.CheckoutWorktree("feature"); err == nil || !strings.Contains(err.Error(), "already checked out") {
+		t.Errorf("CheckoutWorktree() error = %v, want feature already checked out", err)
+	}
+}
+
 func TestRemoveWorktree(t *testing.T) {
 	repoPath := initGitRepo(t)
 
@@ -212,6 +307,25 @@ func TestDetectSubmodulesFalseWhenNone(t *testing.T) {
 	}
 }
 
+func TestDeleteBranch(t *testing.T) {
+	repoPath := initGitRepo(t)
+	runGit(t, repoPath, "branch", "feature-delete")
+
+	manager, err := NewManager(repoPath)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	if err := manager.DeleteBranch("feature-delete"); err != nil {
+		t.Fatalf("DeleteBranch() error = %v", err)
+	}
+	if manager.BranchExists("feature-delete") {
+		t.Error("branch still exists after DeleteBranch()")
+	}
+	if err := manager.DeleteBranch("feature-delete"); err == nil {
+		t.Error("DeleteBranch() of a missing branch error = nil, want error")
+	}
+}
+
 func initGitRepo(t *testing.T) string {
 	t.Helper()
 
@@ -131,6 +131,11 @@ func CalculateWindowBounds(grid GridLayout, screen ScreenInfo, count int) []Wind
 	return bounds
 }
 
+// String formats the layout as "RxC", the inverse of ParseLayout.
+func (g GridLayout) String() string {
+	return fmt.Sprintf("%dx%d", g.Rows, g.Cols)
+}
+
 // ParseLayout parses a layout string in the format "RxC" or "RXC" where R is rows and C is cols.
 // Returns an error if the format is invalid or values are <= 0.
 func ParseLayout(s string) (GridLayout, error) {
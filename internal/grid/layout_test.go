@@ -201,3 +201,16 @@ func TestParseLayout(t *testing.T) {
 		})
 	}
 }
+
+func TestGridLayoutStringRoundTrip(t *testing.T) {
+	layouts := []GridLayout{{Rows: 1, Cols: 1}, {Rows: 2, Cols: 3}, {Rows: 4, Cols: 4}}
+	for _, want := range layouts {
+		got, err := ParseLayout(want.String())
+		if err != nil {
+			t.Fatalf("ParseLayout(%q) error = %v", want.String(), err)
+		}
+		if got != want {
+			t.Errorf("ParseLayout(%q) = %+v, want %+v", want.String(), got, want)
+		}
+	}
+}
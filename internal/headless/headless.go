@@ -0,0 +1,117 @@
+// Package headless runs agents as child processes of claude-grid instead of in
+// terminal windows, writing the output of each to its own log file.
+package headless
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// stopDelay is how long a stopped process group may take to exit after
+// SIGTERM before it is killed.
+const stopDelay = 10 * time.Second
+
+// Instance is one agent to run.
+type Instance struct {
+	// Dir is the working directory.
+	Dir string
+	// CommandLine is run with Shell.
+	CommandLine string
+	// LogPath receives the agent's stdout and stderr. Its directory is created
+	// if needed and an existing file is appended to.
+	LogPath string
+}
+
+// Process is a started instance.
+type Process struct {
+	PID     int
+	LogPath string
+	// Started is the StartTime of the process, empty if it is unknown.
+	Started string
+
+	cmd *exec.Cmd
+	log *os.File
+}
+
+// Start starts every instance with no standard input, each in its own process
+// group so Stop reaches the agents the shell starts. Cancelling ctx stops them
+// all. If any instance fails to start, those already started are stopped.
+func Start(ctx context.Context, instances []Instance) ([]*Process, error) {
+	processes := make([]*Process, 0, len(instances))
+	for i, inst := range instances {
+		p, err := start(ctx, inst)
+		if err != nil {
+			for _, started := range processes {
+				_ = Stop(started.PID)
+				_, _ = started.Wait()
+			}
+			return nil, fmt.Errorf("start instance %d: %w", i+1, err)
+		}
+		processes = append(processes, p)
+	}
+	return processes, nil
+}
+
+func start(ctx context.Context, inst Instance) (*Process, error) {
+	if err := os.MkdirAll(filepath.Dir(inst.LogPath), 0755); err != nil {
+		return nil, fmt.Errorf("create log directory: %w", err)
+	}
+	log, err := os.OpenFile(inst.LogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open log: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, Shell(), "-c", inst.CommandLine)
+	cmd.Dir = inst.Dir
+	cmd.Stdout = log
+	cmd.Stderr = log
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error { return Stop(cmd.Process.Pid) }
+	cmd.WaitDelay = stopDelay
+
+	if err := cmd.Start(); err != nil {
+		log.Close()
+		return nil, err
+	}
+	started, _ := StartTime(cmd.Process.Pid)
+	return &Process{PID: cmd.Process.Pid, LogPath: inst.LogPath, Started: started, cmd: cmd, log: log}, nil
+}
+
+// Wait waits for the process to exit and returns its exit code. A process
+// killed by a signal reports -1. err is only set when the process could not
+// be waited for.
+func (p *Process) Wait() (int, error) {
+	defer p.log.Close()
+
+	if err := p.cmd.Wait(); err != nil && p.cmd.ProcessState == nil {
+		return -1, err
+	}
+	return p.cmd.ProcessState.ExitCode(), nil
+}
+
+// Running reports whether the process pid that started at started is still
+// running, rather than a later process that was given the same pid. An empty
+// started, as recorded where StartTime is unknown, only checks the pid.
+func Running(pid int, started string) bool {
+	if !Alive(pid) {
+		return false
+	}
+	if started == "" {
+		return true
+	}
+	current, err := StartTime(pid)
+	return err == nil && current == started
+}
+
+// Shell returns the shell command lines run with: $SHELL, falling back to
+// /bin/sh, as in a terminal window.
+func Shell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "/bin/sh"
+}
@@ -0,0 +1,96 @@
+package headless
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStartAndWait(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	dir := t.TempDir()
+	logs := filepath.Join(dir, "logs")
+
+	processes, err := Start(context.Background(), []Instance{
+		{Dir: dir, CommandLine: "pwd; echo oops >&2", LogPath: filepath.Join(logs, "1.log")},
+		{Dir: dir, CommandLine: "exit 3", LogPath: filepath.Join(logs, "2.log")},
+	})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	for i, want := range []int{0, 3} {
+		code, err := processes[i].Wait()
+		if err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+		if code != want {
+			t.Errorf("instance %d exit code = %d, want %d", i+1, code, want)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(logs, "1.log"))
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	if !strings.Contains(string(data), dir) || !strings.Contains(string(data), "oops") {
+		t.Errorf("log = %q, want working directory and stderr", data)
+	}
+}
+
+func TestStartCancel(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	dir := t.TempDir()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	processes, err := Start(ctx, []Instance{{Dir: dir, CommandLine: "sleep 30", LogPath: filepath.Join(dir, "1.log")}})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if !Alive(processes[0].PID) {
+		t.Error("Alive() = false for a running process")
+	}
+
+	cancel()
+	done := make(chan int, 1)
+	go func() {
+		code, _ := processes[0].Wait()
+		done <- code
+	}()
+	select {
+	case code := <-done:
+		if code == 0 {
+			t.Error("cancelled process exited with 0")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("process was not stopped by cancelling the context")
+	}
+}
+
+func TestStartFailure(t *testing.T) {
+	dir := t.TempDir()
+	_, err := Start(context.Background(), []Instance{{Dir: filepath.Join(dir, "missing"), CommandLine: "true", LogPath: filepath.Join(dir, "1.log")}})
+	if err == nil || !strings.Contains(err.Error(), "start instance 1") {
+		t.Errorf("Start() error = %v, want start instance 1 failure", err)
+	}
+}
+
+func TestRunning(t *testing.T) {
+	pid := os.Getpid()
+	started, err := StartTime(pid)
+	if err != nil {
+		t.Skipf("StartTime() error = %v", err)
+	}
+	if !Running(pid, started) {
+		t.Error("Running() = false for this process")
+	}
+	if !Running(pid, "") {
+		t.Error("Running() = false for this process without a start time")
+	}
+	if Running(pid, "Thu Jan  1 00:00:00 1970") {
+		t.Error("Running() = true for a reused pid")
+	}
+}
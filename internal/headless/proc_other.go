@@ -0,0 +1,32 @@
+//go:build !unix
+
+package headless
+
+import (
+	"os"
+	"os/exec"
+)
+
+// Process groups are only used on unix; elsewhere only the shell is stopped.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// Stop kills the process pid.
+func Stop(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return nil
+	}
+	return p.Kill()
+}
+
+// Alive reports whether the process pid is still running. Without a way to
+// probe processes, any valid pid is assumed to be running.
+func Alive(pid int) bool {
+	return pid > 0
+}
+
+// StartTime returns an empty start time: without ps, processes are identified
+// by pid alone.
+func StartTime(pid int) (string, error) {
+	return "", nil
+}
@@ -0,0 +1,143 @@
+package hooks
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/riricardoMa/claude-grid/internal/agentstate"
+)
+
+// Event is one Claude Code hook invocation recorded for a window.
+type Event struct {
+	Time time.Time `json:"time"`
+	// Window is numbered from 1.
+	Window int `json:"window"`
+	// Hook is the Claude Code hook event name, e.g. "Stop".
+	Hook string `json:"hook"`
+	Tool string `json:"tool,omitempty"`
+	// Message is the text of Notification events.
+	Message        string `json:"message,omitempty"`
+	ConversationID string `json:"conversation_id,omitempty"`
+}
+
+// State returns the agent state e implies: working after a prompt or tool
+// use, waiting after a notification and idle once the agent stops.
+func (e Event) State() agentstate.State {
+	switch e.Hook {
+	case "UserPromptSubmit", "PostToolUse":
+		return agentstate.Working
+	case "Notification":
+		return agentstate.Waiting
+	case "Stop":
+		return agentstate.Idle
+	default:
+		return agentstate.Unknown
+	}
+}
+
+// Log stores the hook events of each session in its own JSONL file.
+type Log struct {
+	dir string
+}
+
+// NewLog creates a Log with the given base directory.
+// If baseDir is empty, defaults to ~/.claude-grid/events/
+func NewLog(baseDir string) *Log {
+	if baseDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			baseDir = "~/.claude-grid/events"
+		} else {
+			baseDir = filepath.Join(home, ".claude-grid", "events")
+		}
+	} else {
+		baseDir = filepath.Join(baseDir, "events")
+	}
+	return &Log{dir: baseDir}
+}
+
+// Path returns the event log file of session.
+func (l *Log) Path(session string) string {
+	return filepath.Join(l.dir, session+".jsonl")
+}
+
+// Append adds e to the log of session. Each event is written with a single
+// append-mode write, so concurrent hooks do not interleave.
+func (l *Log) Append(session string, e Event) error {
+	if err := os.MkdirAll(l.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create events directory: %w", err)
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	f, err := os.OpenFile(l.Path(session), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open event log: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write event log: %w", err)
+	}
+	return f.Close()
+}
+
+// Read returns the events of session in the order they were recorded. A
+// missing log yields no events; malformed lines are skipped.
+func (l *Log) Read(session string) ([]Event, error) {
+	f, err := os.Open(l.Path(session))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event log: %w", err)
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read event log: %w", err)
+	}
+	return events, nil
+}
+
+// Latest returns the most recent event of each window of session, keyed by
+// window number.
+func (l *Log) Latest(session string) (map[int]Event, error) {
+	events, err := l.Read(session)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make(map[int]Event)
+	for _, e := range events {
+		if prev, ok := latest[e.Window]; !ok || !e.Time.Before(prev.Time) {
+			latest[e.Window] = e
+		}
+	}
+	return latest, nil
+}
+
+// Remove deletes the log of session. A missing log is not an error.
+func (l *Log) Remove(session string) error {
+	if err := os.Remove(l.Path(session)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove event log: %w", err)
+	}
+	return nil
+}
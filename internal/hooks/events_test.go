@@ -0,0 +1,73 @@
+package hooks
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/riricardoMa/claude-grid/internal/agentstate"
+)
+
+func TestLogAppendReadLatest(t *testing.T) {
+	log := NewLog(t.TempDir())
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	events := []Event{
+		{Time: base, Window: 1, Hook: "UserPromptSubmit"},
+		{Time: base.Add(time.Minute), Window: 2, Hook: "PostToolUse", Tool: "Bash"},
+		{Time: base.Add(2 * time.Minute), Window: 1, Hook: "Notification", Message: "Claude needs your permission to use Bash"},
+	}
+	for _, e := range events {
+		if err := log.Append("sprint", e); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	read, err := log.Read("sprint")
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(read) != 3 || read[1].Tool != "Bash" {
+		t.Fatalf("Read() = %+v, want the 3 appended events", read)
+	}
+
+	latest, err := log.Latest("sprint")
+	if err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+	if latest[1].State() != agentstate.Waiting || latest[2].State() != agentstate.Working {
+		t.Errorf("Latest() = %+v, want window 1 waiting and window 2 working", latest)
+	}
+
+	if err := log.Remove("sprint"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := os.Stat(log.Path("sprint")); !os.IsNotExist(err) {
+		t.Errorf("log still exists after Remove(): %v", err)
+	}
+	if err := log.Remove("sprint"); err != nil {
+		t.Errorf("Remove() of missing log error = %v, want nil", err)
+	}
+}
+
+func TestLogReadMissing(t *testing.T) {
+	events, err := NewLog(t.TempDir()).Read("none")
+	if err != nil || len(events) != 0 {
+		t.Errorf("Read() = %v, %v, want no events and no error", events, err)
+	}
+}
+
+func TestEventState(t *testing.T) {
+	tests := map[string]agentstate.State{
+		"UserPromptSubmit": agentstate.Working,
+		"PostToolUse":      agentstate.Working,
+		"Notification":     agentstate.Waiting,
+		"Stop":             agentstate.Idle,
+		"SessionStart":     agentstate.Unknown,
+	}
+	for hook, want := range tests {
+		if got := (Event{Hook: hook}).State(); got != want {
+			t.Errorf("Event{Hook: %q}.State() = %q, want %q", hook, got, want)
+		}
+	}
+}
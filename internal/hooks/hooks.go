@@ -0,0 +1,222 @@
+// Package hooks installs Claude Code hooks that report agent activity back to
+// claude-grid, and reads the per-session event logs they append to.
+package hooks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/riricardoMa/claude-grid/internal/agent"
+	"github.com/riricardoMa/claude-grid/internal/git"
+)
+
+// SettingsFile is the Claude Code settings file hooks are installed into,
+// relative to the worktree. Claude Code reads it but it is not meant to be
+// committed.
+const SettingsFile = ".claude/settings.local.json"
+
+// excludePattern keeps SettingsFile out of git status while hooks are
+// installed.
+const excludePattern = "/" + SettingsFile
+
+// Marker ends every hook command claude-grid installs, as a shell comment, so
+// they can be told apart from the user's own hooks when uninstalling. It also
+// tags the line excluding SettingsFile from git.
+const Marker = "# claude-grid-hook"
+
+// ErrTracked is returned by Install when the repository tracks SettingsFile,
+// so hooks written to it would show up in the user's changes.
+var ErrTracked = errors.New(SettingsFile + " is tracked by git")
+
+// Events are the Claude Code hook events claude-grid listens to.
+var Events = []string{"UserPromptSubmit", "PostToolUse", "Notification", "Stop"}
+
+// Command returns the hook command line that records events for window
+// (numbered from 1) of session, run by the claude-grid executable at exe.
+func Command(exe, session string, window int) string {
+	return fmt.Sprintf("%s hook --session %s --window %d %s", agent.ShellQuote(exe), agent.ShellQuote(session), window, Marker)
+}
+
+// Install adds command as a hook for every event in Events to the settings
+// file of the worktree at dir, keeping any settings and hooks already there.
+// The settings file is excluded from git, so it neither shows as an
+// uncommitted change nor gets committed by an agent; ErrTracked is returned
+// when the repository tracks it.
+func Install(dir, command string) error {
+	if !strings.Contains(command, Marker) {
+		return fmt.Errorf("hook command %q does not contain %q", command, Marker)
+	}
+	tracked, err := git.Tracked(dir, SettingsFile)
+	if err != nil {
+		return err
+	}
+	if tracked {
+		return fmt.Errorf("%s: %w", dir, ErrTracked)
+	}
+
+	path := filepath.Join(dir, SettingsFile)
+	settings, err := readSettings(path)
+	if err != nil {
+		return err
+	}
+
+	hooks, _ := settings["hooks"].(map[string]any)
+	if hooks == nil {
+		hooks = map[string]any{}
+	}
+	for _, event := range Events {
+		matchers, _ := hooks[event].([]any)
+		matchers = removeMarked(matchers)
+		matcher := map[string]any{
+			"hooks": []any{map[string]any{"type": "command", "command": command}},
+		}
+		if event == "PostToolUse" {
+			matcher["matcher"] = "*"
+		}
+		hooks[event] = append(matchers, matcher)
+	}
+	settings["hooks"] = hooks
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %q: %w", filepath.Dir(path), err)
+	}
+	if err := writeSettings(path, settings); err != nil {
+		return err
+	}
+	return git.Exclude(dir, excludePattern, Marker)
+}
+
+// Uninstall removes the hooks Install added to the worktree at dir. The
+// settings file is deleted when nothing else is left in it, along with the
+// .claude directory if that is then empty. The settings file stays excluded
+// from git while another worktree of the repository still has hooks
+// installed, as they share one exclude file. A worktree without hooks from
+// claude-grid is left alone.
+func Uninstall(dir string) error {
+	if !installed(dir) {
+		return nil
+	}
+	if err := removeHooks(filepath.Join(dir, SettingsFile)); err != nil {
+		return err
+	}
+
+	worktrees, err := git.WorktreePaths(dir)
+	if err != nil {
+		return err
+	}
+	for _, worktree := range worktrees {
+		if installed(worktree) {
+			return nil
+		}
+	}
+	return git.Unexclude(dir, excludePattern, Marker)
+}
+
+// removeHooks removes the hooks Install added from the settings file at path,
+// deleting the file when it is left empty.
+func removeHooks(path string) error {
+	settings, err := readSettings(path)
+	if err != nil {
+		return err
+	}
+
+	if hooks, ok := settings["hooks"].(map[string]any); ok {
+		for event, value := range hooks {
+			matchers, ok := value.([]any)
+			if !ok {
+				continue
+			}
+			if matchers = removeMarked(matchers); len(matchers) == 0 {
+				delete(hooks, event)
+			} else {
+				hooks[event] = matchers
+			}
+		}
+		if len(hooks) == 0 {
+			delete(settings, "hooks")
+		}
+	}
+
+	if len(settings) > 0 {
+		return writeSettings(path, settings)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove %q: %w", path, err)
+	}
+	// Only succeeds when the directory is empty.
+	_ = os.Remove(filepath.Dir(path))
+	return nil
+}
+
+// installed reports whether the worktree at dir has hooks installed.
+func installed(dir string) bool {
+	data, err := os.ReadFile(filepath.Join(dir, SettingsFile))
+	return err == nil && strings.Contains(string(data), Marker)
+}
+
+// removeMarked drops the hook commands containing Marker from a list of hook
+// matchers, and matchers left without commands.
+func removeMarked(matchers []any) []any {
+	kept := make([]any, 0, len(matchers))
+	for _, m := range matchers {
+		matcher, ok := m.(map[string]any)
+		if !ok {
+			kept = append(kept, m)
+			continue
+		}
+		commands, ok := matcher["hooks"].([]any)
+		if !ok {
+			kept = append(kept, m)
+			continue
+		}
+
+		var remaining []any
+		for _, c := range commands {
+			hook, _ := c.(map[string]any)
+			if command, _ := hook["command"].(string); strings.Contains(command, Marker) {
+				continue
+			}
+			remaining = append(remaining, c)
+		}
+		if len(remaining) == 0 {
+			continue
+		}
+		matcher["hooks"] = remaining
+		kept = append(kept, matcher)
+	}
+	return kept
+}
+
+func readSettings(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]any{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", path, err)
+	}
+
+	settings := map[string]any{}
+	if strings.TrimSpace(string(data)) == "" {
+		return settings, nil
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", path, err)
+	}
+	return settings, nil
+}
+
+func writeSettings(path string, settings map[string]any) error {
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %q: %w", path, err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %q: %w", path, err)
+	}
+	return nil
+}
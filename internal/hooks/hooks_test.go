@@ -0,0 +1,185 @@
+package hooks
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// initRepo returns a new git repository to install hooks into.
+func initRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	runGit(t, dir, "init")
+	return dir
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v (output: %s)", args, err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func readSettingsFile(t *testing.T, dir string) map[string]any {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, SettingsFile))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	var settings map[string]any
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	return settings
+}
+
+func TestCommand(t *testing.T) {
+	got := Command("/opt/claude grid/claude-grid", "sprint", 3)
+	want := "'/opt/claude grid/claude-grid' hook --session 'sprint' --window 3 " + Marker
+	if got != want {
+		t.Errorf("Command() = %q, want %q", got, want)
+	}
+}
+
+func TestInstallAndUninstall(t *testing.T) {
+	dir := initRepo(t)
+	command := Command("/usr/local/bin/claude-grid", "sprint", 1)
+
+	if err := Install(dir, command); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	// Installing twice must not duplicate hooks.
+	if err := Install(dir, command); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	hooks := readSettingsFile(t, dir)["hooks"].(map[string]any)
+	for _, event := range Events {
+		matchers, _ := hooks[event].([]any)
+		if len(matchers) != 1 {
+			t.Fatalf("hooks[%s] = %v, want one matcher", event, hooks[event])
+		}
+		data, _ := json.Marshal(matchers[0])
+		if !strings.Contains(string(data), "claude-grid-hook") {
+			t.Errorf("hooks[%s] = %s, want claude-grid command", event, data)
+		}
+	}
+
+	if err := Uninstall(dir); err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".claude")); !os.IsNotExist(err) {
+		t.Errorf(".claude directory still exists after Uninstall(): %v", err)
+	}
+}
+
+func TestUninstallKeepsUserSettings(t *testing.T) {
+	dir := initRepo(t)
+	if err := os.MkdirAll(filepath.Join(dir, ".claude"), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	existing := `{
+  "permissions": {"allow": ["Bash(go test:*)"]},
+  "hooks": {"Stop": [{"hooks": [{"type": "command", "command": "say done"}]}]}
+}`
+	if err := os.WriteFile(filepath.Join(dir, SettingsFile), []byte(existing), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if err := Install(dir, Command("claude-grid", "sprint", 2)); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	stop := readSettingsFile(t, dir)["hooks"].(map[string]any)["Stop"].([]any)
+	if len(stop) != 2 {
+		t.Fatalf("Stop hooks = %v, want user hook and claude-grid hook", stop)
+	}
+
+	if err := Uninstall(dir); err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	settings := readSettingsFile(t, dir)
+	if _, ok := settings["permissions"]; !ok {
+		t.Errorf("settings = %v, want permissions kept", settings)
+	}
+	data, _ := json.Marshal(settings["hooks"])
+	if !strings.Contains(string(data), "say done") || strings.Contains(string(data), Marker) {
+		t.Errorf("hooks = %s, want only the user's Stop hook", data)
+	}
+}
+
+func TestUninstallMissingFile(t *testing.T) {
+	if err := Uninstall(t.TempDir()); err != nil {
+		t.Errorf("Uninstall() error = %v, want nil", err)
+	}
+}
+
+func TestInstallExcludesSettingsFile(t *testing.T) {
+	repo := initRepo(t)
+	runGit(t, repo, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "init")
+	worktree := filepath.Join(t.TempDir(), "wt")
+	runGit(t, repo, "worktree", "add", "-b", "feature", worktree)
+
+	for _, dir := range []string{repo, worktree} {
+		if err := Install(dir, Command("claude-grid", "sprint", 1)); err != nil {
+			t.Fatalf("Install() error = %v", err)
+		}
+	}
+	for _, dir := range []string{repo, worktree} {
+		if status := runGit(t, dir, "status", "--porcelain"); status != "" {
+			t.Errorf("git status in %q = %q, want settings file ignored", dir, status)
+		}
+	}
+
+	// The worktrees share an exclude file, so it is kept until the last
+	// worktree's hooks are removed.
+	if err := Uninstall(worktree); err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	if status := runGit(t, repo, "status", "--porcelain"); status != "" {
+		t.Errorf("git status = %q after uninstalling another worktree, want settings file ignored", status)
+	}
+	if err := Install(worktree, Command("claude-grid", "sprint", 1)); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	for _, dir := range []string{repo, worktree} {
+		if err := Uninstall(dir); err != nil {
+			t.Fatalf("Uninstall() error = %v", err)
+		}
+	}
+	exclude, err := os.ReadFile(filepath.Join(repo, ".git", "info", "exclude"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(exclude), SettingsFile) {
+		t.Errorf("exclude file = %q, want settings file no longer excluded", exclude)
+	}
+}
+
+func TestInstallRefusesTrackedSettingsFile(t *testing.T) {
+	dir := initRepo(t)
+	existing := "{\"permissions\": {\"allow\": []}}\n"
+	if err := os.MkdirAll(filepath.Join(dir, ".claude"), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, SettingsFile), []byte(existing), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	runGit(t, dir, "add", SettingsFile)
+
+	if err := Install(dir, Command("claude-grid", "sprint", 1)); !errors.Is(err, ErrTracked) {
+		t.Errorf("Install() error = %v, want ErrTracked", err)
+	}
+	if err := Uninstall(dir); err != nil {
+		t.Errorf("Uninstall() error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, SettingsFile)); err != nil || string(data) != existing {
+		t.Errorf("settings file = %q, %v; want it untouched", data, err)
+	}
+}
@@ -19,6 +19,9 @@ type Instance struct {
 	Dir    string `yaml:"dir"`
 	Prompt string `yaml:"prompt"`
 	Branch string `yaml:"branch"`
+	// Base is the ref Branch is created from when it does not exist yet. An
+	// existing Branch must already contain it.
+	Base string `yaml:"base"`
 }
 
 func Parse(manifestPath string) (Manifest, error) {
@@ -46,6 +49,9 @@ func Parse(manifestPath string) (Manifest, error) {
 		if inst.Dir == "" {
 			return Manifest{}, fmt.Errorf("manifest %q: instance %d is missing required field \"dir\"", manifestPath, i)
 		}
+		if inst.Base != "" && inst.Branch == "" {
+			return Manifest{}, fmt.Errorf("manifest %q: instance %d sets \"base\" without \"branch\"", manifestPath, i)
+		}
 
 		expanded, err := pathutil.ExpandTilde(inst.Dir)
 		if err != nil {
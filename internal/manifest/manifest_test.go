@@ -24,6 +24,7 @@ instances:
   - dir: /tmp/frontend
     prompt: "fix the login page"
     branch: fix/login
+    base: origin/main
   - dir: /tmp/backend
     prompt: "add rate limiting"
 `,
@@ -43,6 +44,9 @@ instances:
 				if m.Instances[0].Branch != "fix/login" {
 					t.Errorf("Instances[0].Branch = %q, want fix/login", m.Instances[0].Branch)
 				}
+				if m.Instances[0].Base != "origin/main" {
+					t.Errorf("Instances[0].Base = %q, want origin/main", m.Instances[0].Base)
+				}
 				if m.Instances[1].Branch != "" {
 					t.Errorf("Instances[1].Branch should be empty, got %q", m.Instances[1].Branch)
 				}
@@ -89,6 +93,15 @@ instances:
 			wantErr:     true,
 			errContains: "dir",
 		},
+		{
+			name: "base without branch",
+			yaml: `instances:
+  - dir: /tmp/x
+    base: origin/main
+`,
+			wantErr:     true,
+			errContains: "base",
+		},
 		{
 			name: "too many instances (17)",
 			yaml: func() string {
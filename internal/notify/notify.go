@@ -0,0 +1,123 @@
+// Package notify alerts the user when an agent changes state, by running a
+// user command, ringing the terminal bell or posting a desktop notification.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/riricardoMa/claude-grid/internal/platform"
+	"github.com/riricardoMa/claude-grid/internal/script"
+)
+
+// commandTimeout bounds how long a notification command may run.
+const commandTimeout = 10 * time.Second
+
+// Event describes an agent state change.
+type Event struct {
+	Session string
+	// Window is numbered from 1.
+	Window int
+	State  string
+	Dir    string
+}
+
+// Message returns a one-line description of e.
+func (e Event) Message() string {
+	return fmt.Sprintf("%s window %d is %s", e.Session, e.Window, e.State)
+}
+
+// Notifier delivers events through every enabled channel.
+type Notifier struct {
+	// Command is run with sh -c. The event is passed in the environment as
+	// CLAUDE_GRID_SESSION, CLAUDE_GRID_WINDOW, CLAUDE_GRID_STATE and
+	// CLAUDE_GRID_DIR.
+	Command string
+	// Bell writes the terminal bell character to Terminal.
+	Bell     bool
+	Terminal io.Writer
+	// Desktop posts a notification through AppleScript on macOS and
+	// notify-send elsewhere.
+	Desktop  bool
+	Executor script.ScriptExecutor
+
+	lookPath func(file string) (string, error)
+	run      func(cmd *exec.Cmd) error
+}
+
+// New creates a Notifier that rings the bell on terminal and posts desktop
+// notifications through executor on macOS.
+func New(terminal io.Writer, executor script.ScriptExecutor) *Notifier {
+	return &Notifier{
+		Terminal: terminal,
+		Executor: executor,
+		lookPath: exec.LookPath,
+		run:      func(cmd *exec.Cmd) error { return cmd.Run() },
+	}
+}
+
+// Enabled reports whether any channel is configured.
+func (n *Notifier) Enabled() bool {
+	return n.Command != "" || n.Bell || n.Desktop
+}
+
+// Notify delivers e through every enabled channel, returning the failures of
+// all of them.
+func (n *Notifier) Notify(ctx context.Context, e Event) error {
+	var errs []error
+
+	if n.Bell && n.Terminal != nil {
+		if _, err := io.WriteString(n.Terminal, "\a"); err != nil {
+			errs = append(errs, fmt.Errorf("ring bell: %w", err))
+		}
+	}
+	if n.Desktop {
+		if err := n.desktop(ctx, e); err != nil {
+			errs = append(errs, fmt.Errorf("desktop notification: %w", err))
+		}
+	}
+	if strings.TrimSpace(n.Command) != "" {
+		if err := n.command(ctx, e); err != nil {
+			errs = append(errs, fmt.Errorf("notification command: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) desktop(ctx context.Context, e Event) error {
+	if platform.HasAppleScript() {
+		if n.Executor == nil {
+			return fmt.Errorf("no AppleScript executor")
+		}
+		_, err := n.Executor.RunAppleScript(ctx, fmt.Sprintf(`display notification "%s" with title "claude-grid"`, script.SanitizeForAppleScript(e.Message())))
+		return err
+	}
+
+	path, err := n.lookPath("notify-send")
+	if err != nil {
+		return fmt.Errorf("notify-send not found in PATH")
+	}
+	return n.run(exec.CommandContext(ctx, path, "claude-grid", e.Message()))
+}
+
+func (n *Notifier) command(ctx context.Context, e Event) error {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", n.Command)
+	cmd.Env = append(os.Environ(),
+		"CLAUDE_GRID_SESSION="+e.Session,
+		"CLAUDE_GRID_WINDOW="+strconv.Itoa(e.Window),
+		"CLAUDE_GRID_STATE="+e.State,
+		"CLAUDE_GRID_DIR="+e.Dir,
+	)
+	return n.run(cmd)
+}
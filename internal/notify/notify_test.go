@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestNotifyBell(t *testing.T) {
+	var terminal bytes.Buffer
+	n := New(&terminal, nil)
+	n.Bell = true
+
+	if err := n.Notify(context.Background(), Event{Session: "sprint", Window: 2, State: "waiting"}); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if terminal.String() != "\a" {
+		t.Errorf("terminal = %q, want bell", terminal.String())
+	}
+}
+
+func TestNotifyCommandReceivesEvent(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "event")
+	n := New(nil, nil)
+	n.Command = `printf '%s %s %s %s' "$CLAUDE_GRID_SESSION" "$CLAUDE_GRID_WINDOW" "$CLAUDE_GRID_STATE" "$CLAUDE_GRID_DIR" > ` + out
+
+	if err := n.Notify(context.Background(), Event{Session: "sprint", Window: 3, State: "waiting", Dir: "/work/a"}); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if got, want := string(data), "sprint 3 waiting /work/a"; got != want {
+		t.Errorf("command saw %q, want %q", got, want)
+	}
+}
+
+func TestNotifyCommandFailure(t *testing.T) {
+	n := New(nil, nil)
+	n.Command = "exit 3"
+
+	err := n.Notify(context.Background(), Event{Session: "sprint", Window: 1, State: "waiting"})
+	if err == nil || !strings.Contains(err.Error(), "notification command") {
+		t.Fatalf("Notify() error = %v, want notification command failure", err)
+	}
+}
+
+func TestNotifyDesktopUsesNotifySend(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("macOS posts desktop notifications through AppleScript")
+	}
+
+	var ran []string
+	n := New(nil, nil)
+	n.Desktop = true
+	n.lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	n.run = func(cmd *exec.Cmd) error {
+		ran = cmd.Args
+		return nil
+	}
+
+	if err := n.Notify(context.Background(), Event{Session: "sprint", Window: 1, State: "waiting"}); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	want := []string{"/usr/bin/notify-send", "claude-grid", "sprint window 1 is waiting"}
+	if strings.Join(ran, "|") != strings.Join(want, "|") {
+		t.Errorf("ran %q, want %q", ran, want)
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	n := New(nil, nil)
+	if n.Enabled() {
+		t.Error("Enabled() = true with no channels")
+	}
+	n.Desktop = true
+	if !n.Enabled() {
+		t.Error("Enabled() = false with desktop notifications")
+	}
+}
@@ -0,0 +1,10 @@
+// Package platform reports which OS-specific integrations are usable on the
+// current host. macOS-only code compiles everywhere and consults this package
+// to degrade gracefully instead of relying on build tags.
+package platform
+
+// HasAppleScript reports whether osascript automation is available. Terminal.app,
+// the Warp window controller and screen detection all depend on it.
+func HasAppleScript() bool {
+	return hasAppleScript
+}
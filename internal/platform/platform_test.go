@@ -0,0 +1,13 @@
+package platform
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestHasAppleScriptMatchesGOOS(t *testing.T) {
+	want := runtime.GOOS == "darwin"
+	if got := HasAppleScript(); got != want {
+		t.Errorf("HasAppleScript() = %v on %s, want %v", got, runtime.GOOS, want)
+	}
+}
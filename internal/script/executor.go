@@ -2,14 +2,20 @@ package script
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"strings"
 	"time"
+
+	"github.com/riricardoMa/claude-grid/internal/platform"
 )
 
 const defaultTimeout = 10 * time.Second
 
+// ErrAppleScriptUnsupported is returned by OSAExecutor on platforms without osascript.
+var ErrAppleScriptUnsupported = errors.New("AppleScript is only supported on macOS")
+
 // ScriptExecutor defines the interface for executing AppleScript
 type ScriptExecutor interface {
 	RunAppleScript(ctx context.Context, script string) (string, error)
@@ -36,6 +42,10 @@ func NewOSAExecutorWithTimeout(timeout time.Duration) *OSAExecutor {
 
 // RunAppleScript executes an AppleScript and returns the output
 func (e *OSAExecutor) RunAppleScript(ctx context.Context, script string) (string, error) {
+	if !platform.HasAppleScript() {
+		return "", fmt.Errorf("osascript execution failed: %w", ErrAppleScriptUnsupported)
+	}
+
 	// Create a context with timeout if not already set
 	if _, ok := ctx.Deadline(); !ok {
 		var cancel context.CancelFunc
@@ -58,20 +68,25 @@ func (e *OSAExecutor) RunAppleScript(ctx context.Context, script string) (string
 	return outputStr, nil
 }
 
+// EscapeAppleScriptString escapes s for use inside an AppleScript string
+// literal. Unlike SanitizeForAppleScript it leaves shell characters alone,
+// for text that is already shell-quoted or is typed rather than run.
+// Backslashes MUST be escaped first to avoid double-escaping.
+func EscapeAppleScriptString(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "\"", "\\\"")
+	s = strings.ReplaceAll(s, "\n", "\\n")
+	s = strings.ReplaceAll(s, "\r", "\\r")
+	return s
+}
+
 // SanitizeForAppleScript escapes special characters to prevent injection.
 // Order is critical: escape backslashes FIRST, then double quotes,
 // then characters that could break AppleScript strings or enable
 // shell injection in spawned terminals.
 func SanitizeForAppleScript(s string) string {
-	// Step 1: Escape backslashes (MUST be first to avoid double-escaping)
-	s = strings.ReplaceAll(s, "\\", "\\\\")
-
-	// Step 2: Escape double quotes
-	s = strings.ReplaceAll(s, "\"", "\\\"")
-
-	// Step 3: Escape newlines and carriage returns (break AppleScript strings)
-	s = strings.ReplaceAll(s, "\n", "\\n")
-	s = strings.ReplaceAll(s, "\r", "\\r")
+	// Steps 1-3: Escape backslashes, double quotes and line breaks
+	s = EscapeAppleScriptString(s)
 
 	// Step 4: Escape backticks (shell command substitution)
 	s = strings.ReplaceAll(s, "`", "\\`")
@@ -0,0 +1,55 @@
+//go:build darwin
+
+package script
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestOSAExecutorRunAppleScript tests the OSAExecutor implementation
+func TestOSAExecutorRunAppleScript(t *testing.T) {
+	executor := NewOSAExecutor()
+
+	tests := []struct {
+		name      string
+		script    string
+		shouldErr bool
+		errMsg    string
+	}{
+		{
+			name:      "simple echo command",
+			script:    "return \"hello\"",
+			shouldErr: false,
+		},
+		{
+			name:      "invalid AppleScript",
+			script:    "invalid syntax here }{",
+			shouldErr: true,
+			errMsg:    "error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			output, err := executor.RunAppleScript(ctx, tt.script)
+
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("RunAppleScript(%q) expected error, got nil", tt.script)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("RunAppleScript(%q) unexpected error: %v", tt.script, err)
+				}
+				if output == "" && tt.script != "" {
+					t.Logf("RunAppleScript(%q) returned empty output (may be expected)", tt.script)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !darwin
+
+package script
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestOSAExecutorUnsupported verifies OSAExecutor fails fast without osascript
+func TestOSAExecutorUnsupported(t *testing.T) {
+	_, err := NewOSAExecutor().RunAppleScript(context.Background(), "return \"hello\"")
+	if !errors.Is(err, ErrAppleScriptUnsupported) {
+		t.Errorf("RunAppleScript() error = %v, want ErrAppleScriptUnsupported", err)
+	}
+}
@@ -137,6 +137,40 @@ func TestSanitizeForAppleScript(t *testing.T) {
 	}
 }
 
+// TestEscapeAppleScriptString tests that only AppleScript string syntax is escaped
+func TestEscapeAppleScriptString(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "quotes and backslashes",
+			input:    "say \"hi\" \\o/",
+			expected: "say \\\"hi\\\" \\\\o/",
+		},
+		{
+			name:     "line breaks",
+			input:    "one\ntwo\r",
+			expected: "one\\ntwo\\r",
+		},
+		{
+			name:     "shell characters left alone",
+			input:    "run `go test` and $(echo hi) ${HOME} it's",
+			expected: "run `go test` and $(echo hi) ${HOME} it's",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := EscapeAppleScriptString(tt.input)
+			if result != tt.expected {
+				t.Errorf("EscapeAppleScriptString(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
 // MockExecutor is a mock implementation of ScriptExecutor for testing
 type MockExecutor struct {
 	RunAppleScriptFunc func(ctx context.Context, script string) (string, error)
@@ -155,52 +189,6 @@ func TestScriptExecutorInterface(t *testing.T) {
 	var _ ScriptExecutor = (*MockExecutor)(nil)
 }
 
-// TestOSAExecutorRunAppleScript tests the OSAExecutor implementation
-func TestOSAExecutorRunAppleScript(t *testing.T) {
-	executor := NewOSAExecutor()
-
-	tests := []struct {
-		name      string
-		script    string
-		shouldErr bool
-		errMsg    string
-	}{
-		{
-			name:      "simple echo command",
-			script:    "return \"hello\"",
-			shouldErr: false,
-		},
-		{
-			name:      "invalid AppleScript",
-			script:    "invalid syntax here }{",
-			shouldErr: true,
-			errMsg:    "error",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-
-			output, err := executor.RunAppleScript(ctx, tt.script)
-
-			if tt.shouldErr {
-				if err == nil {
-					t.Errorf("RunAppleScript(%q) expected error, got nil", tt.script)
-				}
-			} else {
-				if err != nil {
-					t.Errorf("RunAppleScript(%q) unexpected error: %v", tt.script, err)
-				}
-				if output == "" && tt.script != "" {
-					t.Logf("RunAppleScript(%q) returned empty output (may be expected)", tt.script)
-				}
-			}
-		})
-	}
-}
-
 // TestOSAExecutorTimeout tests that context timeout is respected
 func TestOSAExecutorTimeout(t *testing.T) {
 	executor := NewOSAExecutor()
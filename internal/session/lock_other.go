@@ -0,0 +1,14 @@
+//go:build !unix
+
+package session
+
+import "os"
+
+// Advisory locking is only implemented for unix; elsewhere writes are still atomic.
+func lockFile(f *os.File) error {
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
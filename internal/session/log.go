@@ -0,0 +1,101 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Lifecycle events recorded in a session's log.
+const (
+	LogSpawn       = "spawn"
+	LogWindowStart = "window_start"
+	LogCheckout    = "checkout"
+	LogBootstrap   = "bootstrap"
+	LogKill        = "kill"
+	LogClean       = "clean"
+	LogResume      = "resume"
+	LogTask        = "task"
+	LogMerge       = "merge"
+	LogVerify      = "verify"
+	LogPick        = "pick"
+	LogExit        = "exit"
+	LogError       = "error"
+)
+
+// LogEntry is one lifecycle event of a session.
+type LogEntry struct {
+	Time  time.Time `json:"time" yaml:"time"`
+	Event string    `json:"event" yaml:"event"`
+	// Command is the claude-grid command line that caused the event.
+	Command string `json:"command,omitempty" yaml:"command,omitempty"`
+	// Window is numbered from 1; zero for events about the whole session.
+	Window  int    `json:"window,omitempty" yaml:"window,omitempty"`
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// AppendLog adds entry to the log of session name, creating it if needed.
+// Entries are only ever appended, and the log outlives the session file so
+// the history of killed and cleaned sessions can still be read.
+func (s *Store) AppendLog(name string, entry LogEntry) error {
+	if err := os.MkdirAll(s.baseDir, 0755); err != nil {
+		return fmt.Errorf("failed to create sessions directory: %w", err)
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal log entry: %w", err)
+	}
+
+	// A single append-mode write keeps concurrent writers from interleaving.
+	f, err := os.OpenFile(s.logPath(name), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open session log: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write session log: %w", err)
+	}
+	return f.Close()
+}
+
+// ReadLog returns the log of session name, oldest first. A session without a
+// log yields os.ErrNotExist; malformed lines are skipped.
+func (s *Store) ReadLog(name string) ([]LogEntry, error) {
+	f, err := os.Open(s.logPath(name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("no log for session %q: %w", name, os.ErrNotExist)
+		}
+		return nil, fmt.Errorf("failed to open session log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []LogEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry LogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read session log: %w", err)
+	}
+	return entries, nil
+}
+
+// logPath keeps the log next to the session file. Its .jsonl extension keeps
+// it out of ScanSessions.
+func (s *Store) logPath(name string) string {
+	return filepath.Join(s.baseDir, name+".log.jsonl")
+}
@@ -0,0 +1,77 @@
+package session
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestAppendAndReadLog(t *testing.T) {
+	store := NewStore(t.TempDir())
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	entries := []LogEntry{
+		{Time: base, Event: LogSpawn, Command: "claude-grid 2 --worktrees", Message: "2 windows"},
+		{Time: base, Event: LogWindowStart, Window: 1},
+		{Time: base.Add(time.Hour), Event: LogKill, Command: "claude-grid kill sprint"},
+	}
+	for _, e := range entries {
+		if err := store.AppendLog("sprint", e); err != nil {
+			t.Fatalf("AppendLog() error = %v", err)
+		}
+	}
+
+	got, err := store.ReadLog("sprint")
+	if err != nil {
+		t.Fatalf("ReadLog() error = %v", err)
+	}
+	if len(got) != len(entries) {
+		t.Fatalf("ReadLog() returned %d entries, want %d", len(got), len(entries))
+	}
+	for i := range entries {
+		if !got[i].Time.Equal(entries[i].Time) || got[i].Event != entries[i].Event || got[i].Window != entries[i].Window || got[i].Command != entries[i].Command {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], entries[i])
+		}
+	}
+}
+
+func TestAppendLogSetsTime(t *testing.T) {
+	store := NewStore(t.TempDir())
+	if err := store.AppendLog("sprint", LogEntry{Event: LogResume}); err != nil {
+		t.Fatalf("AppendLog() error = %v", err)
+	}
+	got, err := store.ReadLog("sprint")
+	if err != nil || len(got) != 1 || got[0].Time.IsZero() {
+		t.Errorf("ReadLog() = %+v, %v; want one entry with a time", got, err)
+	}
+}
+
+func TestReadLogMissing(t *testing.T) {
+	_, err := NewStore(t.TempDir()).ReadLog("none")
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ReadLog() error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestLogSurvivesDeleteAndIsNotASession(t *testing.T) {
+	store := NewStore(t.TempDir())
+	if err := store.SaveSession(Session{Name: "sprint"}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+	if err := store.AppendLog("sprint", LogEntry{Event: LogSpawn}); err != nil {
+		t.Fatalf("AppendLog() error = %v", err)
+	}
+
+	sessions, corrupt, err := store.ScanSessions()
+	if err != nil || len(sessions) != 1 || len(corrupt) != 0 {
+		t.Fatalf("ScanSessions() = %d sessions, %v corrupt, %v; want only the session", len(sessions), corrupt, err)
+	}
+
+	if err := store.DeleteSession("sprint"); err != nil {
+		t.Fatalf("DeleteSession() error = %v", err)
+	}
+	if entries, err := store.ReadLog("sprint"); err != nil || len(entries) != 1 {
+		t.Errorf("ReadLog() after delete = %v, %v; want the logged entry", entries, err)
+	}
+}
@@ -0,0 +1,120 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/riricardoMa/claude-grid/internal/agent"
+)
+
+// CurrentSchemaVersion is the session file schema written by this build.
+// Files without a schema_version field are version 0.
+const CurrentSchemaVersion = 2
+
+// ErrNewerSchema is returned for session files written by a newer claude-grid.
+var ErrNewerSchema = errors.New("session file uses a newer schema")
+
+// migrations[v] upgrades a decoded session file from schema version v to v+1.
+// Append new steps and bump CurrentSchemaVersion; never edit existing ones.
+var migrations = []func(raw map[string]any) error{
+	migrateV0ToV1,
+	migrateV1ToV2,
+}
+
+// migrateV0ToV1 makes the implicit "active" status of unversioned files explicit.
+func migrateV0ToV1(raw map[string]any) error {
+	if status, _ := raw["status"].(string); status == "" {
+		raw["status"] = "active"
+	}
+	return nil
+}
+
+// migrateV1ToV2 records that sessions from before configurable agents ran claude.
+func migrateV1ToV2(raw map[string]any) error {
+	if _, ok := raw["agent"]; ok {
+		return nil
+	}
+	data, err := json.Marshal(agent.Default())
+	if err != nil {
+		return err
+	}
+	var profile map[string]any
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return err
+	}
+	raw["agent"] = profile
+	return nil
+}
+
+// decodeSession parses a session file of any supported schema version and
+// migrates it in memory to CurrentSchemaVersion. The file on disk is only
+// upgraded by the next write, so older claude-grid builds sharing the
+// directory can keep reading it until then.
+func decodeSession(data []byte) (Session, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Session{}, err
+	}
+
+	version, err := schemaVersion(raw)
+	if err != nil {
+		return Session{}, err
+	}
+	if version > CurrentSchemaVersion {
+		return Session{}, fmt.Errorf("%w: version %d, this claude-grid supports up to %d; upgrade claude-grid", ErrNewerSchema, version, CurrentSchemaVersion)
+	}
+
+	for v := version; v < CurrentSchemaVersion; v++ {
+		if err := migrations[v](raw); err != nil {
+			return Session{}, fmt.Errorf("migrate schema %d to %d: %w", v, v+1, err)
+		}
+	}
+	raw["schema_version"] = CurrentSchemaVersion
+
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return Session{}, err
+	}
+
+	var session Session
+	if err := json.Unmarshal(migrated, &session); err != nil {
+		return Session{}, err
+	}
+	return session, nil
+}
+
+// checkWritable refuses to overwrite a session file written by a newer schema,
+// which would silently drop fields this build does not know about.
+func checkWritable(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+
+	version, err := schemaVersion(raw)
+	if err == nil && version > CurrentSchemaVersion {
+		return fmt.Errorf("%w: version %d, this claude-grid supports up to %d; refusing to overwrite", ErrNewerSchema, version, CurrentSchemaVersion)
+	}
+	return nil
+}
+
+func schemaVersion(raw map[string]any) (int, error) {
+	value, ok := raw["schema_version"]
+	if !ok || value == nil {
+		return 0, nil
+	}
+
+	number, ok := value.(float64)
+	if !ok || number < 0 || number != math.Trunc(number) {
+		return 0, fmt.Errorf("invalid schema_version %v", value)
+	}
+	return int(number), nil
+}
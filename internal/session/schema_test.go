@@ -0,0 +1,153 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeRawSession(t *testing.T, store *Store, name, data string) {
+	t.Helper()
+	if err := os.MkdirAll(store.baseDir, 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(store.baseDir, name+".json"), []byte(data), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+func TestLoadSessionMigratesUnversioned(t *testing.T) {
+	store := NewStore(t.TempDir())
+	writeRawSession(t, store, "grid-v0", `{"name": "grid-v0", "backend": "terminal", "count": 1}`)
+
+	loaded, err := store.LoadSession("grid-v0")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if loaded.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", loaded.SchemaVersion, CurrentSchemaVersion)
+	}
+	if loaded.Status != "active" {
+		t.Errorf("Status = %q, want %q", loaded.Status, "active")
+	}
+
+	data, err := os.ReadFile(filepath.Join(store.baseDir, "grid-v0.json"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(data), "schema_version") {
+		t.Errorf("LoadSession() should not rewrite the file: %s", data)
+	}
+}
+
+func TestLoadSessionKeepsStoppedStatus(t *testing.T) {
+	store := NewStore(t.TempDir())
+	writeRawSession(t, store, "grid-v0", `{"name": "grid-v0", "status": "stopped"}`)
+
+	loaded, err := store.LoadSession("grid-v0")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if loaded.Status != "stopped" {
+		t.Errorf("Status = %q, want %q", loaded.Status, "stopped")
+	}
+}
+
+func TestLoadSessionMigratesAgent(t *testing.T) {
+	store := NewStore(t.TempDir())
+	writeRawSession(t, store, "grid-v1", `{"schema_version": 1, "name": "grid-v1", "status": "stopped"}`)
+	writeRawSession(t, store, "grid-aider", `{"schema_version": 1, "name": "grid-aider", "agent": {"name": "aider", "command": "aider", "prompt_mode": "flag", "prompt_flag": "--message"}}`)
+
+	loaded, err := store.LoadSession("grid-v1")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if loaded.Agent.Name != "claude" || loaded.Agent.Command != "claude" || loaded.Agent.ResumeFlag == "" {
+		t.Errorf("Agent = %+v, want the claude profile", loaded.Agent)
+	}
+
+	loaded, err = store.LoadSession("grid-aider")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if loaded.Agent.Name != "aider" || loaded.Agent.PromptFlag != "--message" {
+		t.Errorf("Agent = %+v, want the stored aider profile", loaded.Agent)
+	}
+}
+
+func TestLoadSessionRejectsNewerSchema(t *testing.T) {
+	store := NewStore(t.TempDir())
+	writeRawSession(t, store, "grid-new", `{"schema_version": 99, "name": "grid-new"}`)
+
+	_, err := store.LoadSession("grid-new")
+	if !errors.Is(err, ErrNewerSchema) {
+		t.Fatalf("LoadSession() error = %v, want ErrNewerSchema", err)
+	}
+	if !strings.Contains(err.Error(), "upgrade claude-grid") {
+		t.Errorf("error = %q, want upgrade hint", err)
+	}
+
+	sessions, corrupt, err := store.ScanSessions()
+	if err != nil {
+		t.Fatalf("ScanSessions() error = %v", err)
+	}
+	if len(sessions) != 0 || len(corrupt) != 1 || !errors.Is(corrupt[0].Err, ErrNewerSchema) {
+		t.Errorf("ScanSessions() = %v, %v; want the newer session reported", sessions, corrupt)
+	}
+}
+
+func TestLoadSessionRejectsInvalidSchemaVersion(t *testing.T) {
+	store := NewStore(t.TempDir())
+	writeRawSession(t, store, "grid-bad", `{"schema_version": "one", "name": "grid-bad"}`)
+
+	if _, err := store.LoadSession("grid-bad"); err == nil {
+		t.Fatal("LoadSession() error = nil, want invalid schema_version error")
+	}
+}
+
+func TestWriteRefusesNewerSchema(t *testing.T) {
+	store := NewStore(t.TempDir())
+	original := `{"schema_version": 99, "name": "grid-new", "future_field": true}`
+	writeRawSession(t, store, "grid-new", original)
+
+	err := store.UpdateSession(Session{Name: "grid-new", Status: "stopped"})
+	if !errors.Is(err, ErrNewerSchema) {
+		t.Fatalf("UpdateSession() error = %v, want ErrNewerSchema", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(store.baseDir, "grid-new.json"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(data) != original {
+		t.Errorf("file was overwritten: %s", data)
+	}
+}
+
+func TestSaveSessionStampsSchemaVersion(t *testing.T) {
+	store := NewStore(t.TempDir())
+	if err := store.SaveSession(Session{Name: "grid-cur", Status: "active"}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(store.baseDir, "grid-cur.json"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if raw["schema_version"] != float64(CurrentSchemaVersion) {
+		t.Errorf("schema_version = %v, want %d", raw["schema_version"], CurrentSchemaVersion)
+	}
+}
+
+func TestMigrationsCoverEveryVersion(t *testing.T) {
+	if len(migrations) != CurrentSchemaVersion {
+		t.Errorf("len(migrations) = %d, want %d (one per version step)", len(migrations), CurrentSchemaVersion)
+	}
+}
@@ -7,35 +7,68 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
+
+	"github.com/riricardoMa/claude-grid/internal/agent"
 )
 
 // Session represents a stored session with window references.
 type Session struct {
-	Name         string        `json:"name"`
-	Backend      string        `json:"backend"`
-	Count        int           `json:"count"`
-	Dir          string        `json:"dir"`
-	CreatedAt    time.Time     `json:"created_at"`
-	Windows      []WindowRef   `json:"windows"`
-	Worktrees    []WorktreeRef `json:"worktrees,omitempty"`
-	Status       string        `json:"status,omitempty"`
-	RepoPath     string        `json:"repo_path,omitempty"`
-	Dirs         []string      `json:"dirs,omitempty"`
-	Prompts      []string      `json:"prompts,omitempty"`
-	ManifestPath string        `json:"manifest_path,omitempty"`
+	SchemaVersion int           `json:"schema_version"`
+	Name          string        `json:"name"`
+	Backend       string        `json:"backend"`
+	Count         int           `json:"count"`
+	Dir           string        `json:"dir"`
+	CreatedAt     time.Time     `json:"created_at"`
+	Windows       []WindowRef   `json:"windows"`
+	Worktrees     []WorktreeRef `json:"worktrees,omitempty"`
+	Status        string        `json:"status,omitempty"`
+	RepoPath      string        `json:"repo_path,omitempty"`
+	Dirs          []string      `json:"dirs,omitempty"`
+	Prompts       []string      `json:"prompts,omitempty"`
+	ManifestPath  string        `json:"manifest_path,omitempty"`
+	Layout        string        `json:"layout,omitempty"`
+	Agent         agent.Profile `json:"agent"`
+	// Hooks records that Claude Code hooks reporting to the session's event
+	// log were installed in its worktrees.
+	Hooks bool `json:"hooks,omitempty"`
+	// Tasks is the task queue of sessions spawned with --queue, in the order
+	// the tasks are dispatched.
+	Tasks []Task `json:"tasks,omitempty"`
+	// Verify is the command that checks each worktree's work, set with
+	// --verify, and Verifications the outcome of its last run.
+	Verify        string         `json:"verify,omitempty"`
+	Verifications []Verification `json:"verifications,omitempty"`
 }
 
 // WindowRef represents a reference to a spawned window.
 type WindowRef struct {
-	ID    string `json:"id"`
-	Index int    `json:"index"`
+	ID             string `json:"id"`
+	Index          int    `json:"index"`
+	ConversationID string `json:"conversation_id,omitempty"`
+	// LogPath receives the output of a headless session's agent, whose ID is
+	// its process ID.
+	LogPath string `json:"log_path,omitempty"`
+	// ProcessStart is when that process started, so a later process reusing
+	// its ID is not mistaken for it.
+	ProcessStart string `json:"process_start,omitempty"`
+	// ExitCode is set once a headless session's agent has exited; -1 if it
+	// was killed by a signal.
+	ExitCode *int `json:"exit_code,omitempty"`
 }
 
 // WorktreeRef represents a reference to a git worktree.
 type WorktreeRef struct {
 	Path   string `json:"path"`
 	Branch string `json:"branch"`
+	// Base is the ref a new branch was created from, e.g. HEAD or
+	// origin/main, and BaseCommit the commit it pointed to at the time.
+	Base       string `json:"base,omitempty"`
+	BaseCommit string `json:"base_commit,omitempty"`
+	// Existing records that the branch existed before the session and was
+	// checked out rather than created.
+	Existing bool `json:"existing,omitempty"`
 }
 
 // Store manages session persistence to JSON files.
@@ -60,91 +93,124 @@ func NewStore(baseDir string) *Store {
 }
 
 // GenerateSessionName generates a unique session name in format "grid-XXXX"
-// where XXXX is 4 random hex characters.
+// where XXXX is 4 random hex characters. The name is reserved atomically with
+// an O_EXCL marker file so concurrent invocations never receive the same name;
+// the reservation is dropped by SaveSession or ReleaseSessionName.
 func (s *Store) GenerateSessionName() string {
+	_ = os.MkdirAll(s.baseDir, 0755)
+
 	for {
 		b := make([]byte, 2)
 		rand.Read(b)
 		name := "grid-" + hex.EncodeToString(b)
 
-		// Check for collision
-		path := filepath.Join(s.baseDir, name+".json")
-		if _, err := os.Stat(path); err != nil {
-			// File doesn't exist, name is unique
+		// Check for collision with a saved session, or the log of a
+		// removed one
+		if _, err := os.Stat(s.sessionPath(name)); err == nil {
+			continue
+		}
+		if _, err := os.Stat(s.logPath(name)); err == nil {
+			continue
+		}
+
+		f, err := os.OpenFile(s.reservationPath(name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err != nil {
+			if os.IsExist(err) {
+				continue
+			}
+			// Reservation is best effort; SaveSession reports unwritable directories.
 			return name
 		}
+		f.Close()
+
+		// A session may have been saved between the stat and the reservation.
+		if _, err := os.Stat(s.sessionPath(name)); err == nil {
+			s.ReleaseSessionName(name)
+			continue
+		}
+		return name
 	}
 }
 
+// ReleaseSessionName drops a reservation made by GenerateSessionName.
+// It is safe to call for names that were never reserved.
+func (s *Store) ReleaseSessionName(name string) {
+	_ = os.Remove(s.reservationPath(name))
+}
+
 // SaveSession saves a session to disk as JSON.
 // Auto-creates the sessions directory if it doesn't exist.
 func (s *Store) SaveSession(session Session) error {
-	if err := os.MkdirAll(s.baseDir, 0755); err != nil {
-		return fmt.Errorf("failed to create sessions directory: %w", err)
-	}
-
-	path := filepath.Join(s.baseDir, session.Name+".json")
-	data, err := json.MarshalIndent(session, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal session: %w", err)
-	}
-
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		return fmt.Errorf("failed to write session file: %w", err)
-	}
-
-	return nil
+	return s.withLock(func() error {
+		if err := s.writeSession(session); err != nil {
+			return err
+		}
+		s.ReleaseSessionName(session.Name)
+		return nil
+	})
 }
 
 // UpdateSession overwrites an existing session file with new data.
 // Identical to SaveSession but with semantic distinction for updates.
+// Prefer ModifySession when the new data is derived from the stored session.
 func (s *Store) UpdateSession(session Session) error {
-	if err := os.MkdirAll(s.baseDir, 0755); err != nil {
-		return fmt.Errorf("failed to create sessions directory: %w", err)
-	}
-
-	path := filepath.Join(s.baseDir, session.Name+".json")
-	data, err := json.MarshalIndent(session, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal session: %w", err)
-	}
-
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		return fmt.Errorf("failed to write session file: %w", err)
-	}
+	return s.withLock(func() error {
+		return s.writeSession(session)
+	})
+}
 
-	return nil
+// ModifySession loads a session, applies fn and writes the result while
+// holding the store lock, so concurrent read-modify-write cycles never lose
+// updates. Nothing is written if fn returns an error.
+func (s *Store) ModifySession(name string, fn func(*Session) error) error {
+	return s.withLock(func() error {
+		session, err := s.LoadSession(name)
+		if err != nil {
+			return err
+		}
+		if err := fn(&session); err != nil {
+			return err
+		}
+		return s.writeSession(session)
+	})
 }
 
 // LoadSession loads a session from disk by name.
 func (s *Store) LoadSession(name string) (Session, error) {
-	path := filepath.Join(s.baseDir, name+".json")
-	data, err := os.ReadFile(path)
+	data, err := os.ReadFile(s.sessionPath(name))
 	if err != nil {
 		return Session{}, fmt.Errorf("failed to read session file: %w", err)
 	}
 
-	var session Session
-	if err := json.Unmarshal(data, &session); err != nil {
+	session, err := decodeSession(data)
+	if err != nil {
 		return Session{}, fmt.Errorf("failed to unmarshal session: %w", err)
 	}
 
 	return session, nil
 }
 
-// ListSessions returns all sessions from the sessions directory.
-func (s *Store) ListSessions() ([]Session, error) {
+// CorruptSession describes a session file that exists but could not be loaded.
+type CorruptSession struct {
+	Name string
+	Err  error
+}
+
+// ScanSessions returns all loadable sessions along with the session files
+// that could not be read or parsed.
+func (s *Store) ScanSessions() ([]Session, []CorruptSession, error) {
 	entries, err := os.ReadDir(s.baseDir)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return []Session{}, nil
+			return []Session{}, nil, nil
 		}
-		return nil, fmt.Errorf("failed to read sessions directory: %w", err)
+		return nil, nil, fmt.Errorf("failed to read sessions directory: %w", err)
 	}
 
 	var sessions []Session
+	var corrupt []CorruptSession
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
 			continue
 		}
 
@@ -155,20 +221,142 @@ func (s *Store) ListSessions() ([]Session, error) {
 		name := entry.Name()[:len(entry.Name())-5]
 		session, err := s.LoadSession(name)
 		if err != nil {
+			corrupt = append(corrupt, CorruptSession{Name: name, Err: err})
 			continue
 		}
 
 		sessions = append(sessions, session)
 	}
 
-	return sessions, nil
+	return sessions, corrupt, nil
+}
+
+// ListSessions returns all sessions from the sessions directory.
+// Unreadable session files are skipped; use ScanSessions to report them.
+func (s *Store) ListSessions() ([]Session, error) {
+	sessions, _, err := s.ScanSessions()
+	return sessions, err
 }
 
 // DeleteSession removes a session file from disk.
 func (s *Store) DeleteSession(name string) error {
-	path := filepath.Join(s.baseDir, name+".json")
-	if err := os.Remove(path); err != nil {
-		return fmt.Errorf("failed to delete session file: %w", err)
+	return s.withLock(func() error {
+		if err := os.Remove(s.sessionPath(name)); err != nil {
+			return fmt.Errorf("failed to delete session file: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeleteOutputLogs removes the directory holding the run, bootstrap and
+// verification logs of session name. The event log is kept.
+func (s *Store) DeleteOutputLogs(name string) error {
+	if err := os.RemoveAll(filepath.Join(s.baseDir, name)); err != nil {
+		return fmt.Errorf("failed to delete output logs: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) sessionPath(name string) string {
+	return filepath.Join(s.baseDir, name+".json")
+}
+
+// RunLogPath returns the file receiving the output of window, numbered from 1,
+// of headless session name. Run logs are kept in a directory named after the
+// session, which ScanSessions skips.
+func (s *Store) RunLogPath(name string, window int) string {
+	return filepath.Join(s.baseDir, name, fmt.Sprintf("%d.log", window))
+}
+
+// BootstrapLogPath returns the file receiving the output of the bootstrap
+// setup commands of window, numbered from 1, of session name. It sits next to
+// the run logs of headless sessions.
+func (s *Store) BootstrapLogPath(name string, window int) string {
+	return filepath.Join(s.baseDir, name, fmt.Sprintf("bootstrap-%d.log", window))
+}
+
+// VerifyLogPath returns the file receiving the output of the verification
+// command in window, numbered from 1, of session name.
+func (s *Store) VerifyLogPath(name string, window int) string {
+	return filepath.Join(s.baseDir, name, fmt.Sprintf("verify-%d.log", window))
+}
+
+func (s *Store) reservationPath(name string) string {
+	return filepath.Join(s.baseDir, "."+name+".reserved")
+}
+
+// writeSession marshals and atomically writes a session at the current schema
+// version. Callers hold the lock.
+func (s *Store) writeSession(session Session) error {
+	if err := checkWritable(s.sessionPath(session.Name)); err != nil {
+		return err
+	}
+
+	session.SchemaVersion = CurrentSchemaVersion
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	if err := writeFileAtomic(s.sessionPath(session.Name), data, 0644); err != nil {
+		return fmt.Errorf("failed to write session file: %w", err)
+	}
+
+	return nil
+}
+
+// withLock runs fn while holding an exclusive advisory lock on the sessions
+// directory. The lock is shared with every other claude-grid process.
+func (s *Store) withLock(fn func() error) error {
+	if err := os.MkdirAll(s.baseDir, 0755); err != nil {
+		return fmt.Errorf("failed to create sessions directory: %w", err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(s.baseDir, ".lock"), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open session lock: %w", err)
+	}
+	defer f.Close()
+
+	if err := lockFile(f); err != nil {
+		return fmt.Errorf("failed to lock sessions directory: %w", err)
+	}
+	defer unlockFile(f)
+
+	return fn()
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory and
+// renames it over path, so readers never observe a partially written file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
 	}
 	return nil
 }
@@ -5,12 +5,14 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
 
 func TestGenerateSessionName(t *testing.T) {
-	store := NewStore("")
+	store := NewStore(t.TempDir())
 
 	// Test format: grid-XXXX (4 random hex chars)
 	name := store.GenerateSessionName()
@@ -271,6 +273,31 @@ func TestDeleteSessionNotFound(t *testing.T) {
 	}
 }
 
+func TestDeleteOutputLogs(t *testing.T) {
+	store := NewStore(t.TempDir())
+	for _, path := range []string{store.RunLogPath("grid-logs", 1), store.VerifyLogPath("grid-logs", 1)} {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("output\n"), 0644)
+	}
+	if err := store.AppendLog("grid-logs", LogEntry{Event: LogClean}); err != nil {
+		t.Fatalf("AppendLog() error = %v", err)
+	}
+
+	if err := store.DeleteOutputLogs("grid-logs"); err != nil {
+		t.Fatalf("DeleteOutputLogs() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Dir(store.RunLogPath("grid-logs", 1))); !os.IsNotExist(err) {
+		t.Errorf("output log directory still exists: %v", err)
+	}
+	if entries, err := store.ReadLog("grid-logs"); err != nil || len(entries) != 1 {
+		t.Errorf("ReadLog() = %v, %v; want the event log kept", entries, err)
+	}
+
+	if err := store.DeleteOutputLogs("grid-logs"); err != nil {
+		t.Errorf("DeleteOutputLogs() without logs error = %v", err)
+	}
+}
+
 func TestAutoCreateDirectory(t *testing.T) {
 	tempDir := t.TempDir()
 	// Use a non-existent subdirectory
@@ -491,8 +518,11 @@ func TestBackwardCompatibilityOldSessionFormat(t *testing.T) {
 	if len(loaded.Worktrees) != 0 {
 		t.Errorf("Worktrees should be empty for old format, got %d", len(loaded.Worktrees))
 	}
-	if loaded.Status != "" {
-		t.Errorf("Status should be empty for old format, got %q", loaded.Status)
+	if loaded.Status != "active" {
+		t.Errorf("Status = %q, want old format migrated to %q", loaded.Status, "active")
+	}
+	if loaded.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", loaded.SchemaVersion, CurrentSchemaVersion)
 	}
 	if loaded.RepoPath != "" {
 		t.Errorf("RepoPath should be empty for old format, got %q", loaded.RepoPath)
@@ -585,3 +615,170 @@ func TestUpdateSession(t *testing.T) {
 		t.Errorf("Worktrees count = %d, want 1", len(loaded.Worktrees))
 	}
 }
+
+func TestGenerateSessionNameReservesName(t *testing.T) {
+	tempDir := t.TempDir()
+	store := NewStore(tempDir)
+
+	name := store.GenerateSessionName()
+	reservation := filepath.Join(tempDir, "sessions", "."+name+".reserved")
+	if _, err := os.Stat(reservation); err != nil {
+		t.Fatalf("reservation for %q not created: %v", name, err)
+	}
+
+	if err := store.SaveSession(Session{Name: name, Backend: "terminal", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+	if _, err := os.Stat(reservation); !os.IsNotExist(err) {
+		t.Errorf("reservation still present after SaveSession: %v", err)
+	}
+
+	other := store.GenerateSessionName()
+	store.ReleaseSessionName(other)
+	if _, err := os.Stat(filepath.Join(tempDir, "sessions", "."+other+".reserved")); !os.IsNotExist(err) {
+		t.Errorf("reservation still present after ReleaseSessionName: %v", err)
+	}
+}
+
+func TestGenerateSessionNameConcurrent(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	names := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := store.GenerateSessionName()
+			mu.Lock()
+			defer mu.Unlock()
+			if names[name] {
+				t.Errorf("GenerateSessionName() handed out %q twice", name)
+			}
+			names[name] = true
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSaveSessionLeavesNoTempFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	store := NewStore(tempDir)
+
+	for i := 0; i < 3; i++ {
+		if err := store.SaveSession(Session{Name: "grid-atomic", Count: i, CreatedAt: time.Now()}); err != nil {
+			t.Fatalf("SaveSession() error = %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(tempDir, "sessions"))
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".tmp-") {
+			t.Errorf("temporary file left behind: %s", e.Name())
+		}
+	}
+}
+
+func TestModifySessionConcurrent(t *testing.T) {
+	store := NewStore(t.TempDir())
+	if err := store.SaveSession(Session{Name: "grid-counter", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	const workers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := store.ModifySession("grid-counter", func(s *Session) error {
+				s.Count++
+				return nil
+			})
+			if err != nil {
+				t.Errorf("ModifySession() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	loaded, err := store.LoadSession("grid-counter")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if loaded.Count != workers {
+		t.Errorf("Count = %d, want %d (lost updates)", loaded.Count, workers)
+	}
+}
+
+func TestModifySessionErrorSkipsWrite(t *testing.T) {
+	store := NewStore(t.TempDir())
+	if err := store.SaveSession(Session{Name: "grid-keep", Status: "active", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	err := store.ModifySession("grid-keep", func(s *Session) error {
+		s.Status = "stopped"
+		return os.ErrInvalid
+	})
+	if err == nil {
+		t.Fatal("ModifySession() expected error, got nil")
+	}
+
+	loaded, _ := store.LoadSession("grid-keep")
+	if loaded.Status != "active" {
+		t.Errorf("Status = %q, want unchanged %q", loaded.Status, "active")
+	}
+}
+
+func TestScanSessionsReportsCorrupt(t *testing.T) {
+	tempDir := t.TempDir()
+	store := NewStore(tempDir)
+	if err := store.SaveSession(Session{Name: "grid-good", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	sessionDir := filepath.Join(tempDir, "sessions")
+	os.WriteFile(filepath.Join(sessionDir, "grid-bad.json"), []byte(`{"name": "grid-b`), 0644)
+	os.WriteFile(filepath.Join(sessionDir, ".grid-good.json.tmp-123"), []byte(`{`), 0644)
+
+	sessions, corrupt, err := store.ScanSessions()
+	if err != nil {
+		t.Fatalf("ScanSessions() error = %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].Name != "grid-good" {
+		t.Errorf("sessions = %+v, want only grid-good", sessions)
+	}
+	if len(corrupt) != 1 || corrupt[0].Name != "grid-bad" || corrupt[0].Err == nil {
+		t.Errorf("corrupt = %+v, want grid-bad with error", corrupt)
+	}
+}
+
+func TestRunLogPathSkippedByScan(t *testing.T) {
+	tempDir := t.TempDir()
+	store := NewStore(tempDir)
+	code := 2
+	sess := Session{Name: "grid-run", CreatedAt: time.Now(), Windows: []WindowRef{{ID: "4242", LogPath: store.RunLogPath("grid-run", 1), ExitCode: &code}}}
+	if err := store.SaveSession(sess); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	logPath := store.RunLogPath("grid-run", 1)
+	if want := filepath.Join(tempDir, "sessions", "grid-run", "1.log"); logPath != want {
+		t.Errorf("RunLogPath() = %q, want %q", logPath, want)
+	}
+	os.MkdirAll(filepath.Dir(logPath), 0755)
+	os.WriteFile(logPath, []byte("done\n"), 0644)
+
+	sessions, corrupt, err := store.ScanSessions()
+	if err != nil || len(corrupt) != 0 || len(sessions) != 1 {
+		t.Fatalf("ScanSessions() = %d sessions, %v corrupt, %v; want only grid-run", len(sessions), corrupt, err)
+	}
+	if w := sessions[0].Windows[0]; w.ExitCode == nil || *w.ExitCode != 2 || w.LogPath != logPath {
+		t.Errorf("window = %+v, want exit code 2 and log path", w)
+	}
+}
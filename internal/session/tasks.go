@@ -0,0 +1,71 @@
+package session
+
+import "time"
+
+// States of a queued task.
+const (
+	TaskPending = "pending"
+	TaskRunning = "running"
+	TaskDone    = "done"
+	TaskFailed  = "failed"
+)
+
+// Task is one prompt of a session's task queue.
+type Task struct {
+	Prompt string `json:"prompt" yaml:"prompt"`
+	State  string `json:"state" yaml:"state"`
+	// Window is the window the task was dispatched to, numbered from 1; zero
+	// while the task is pending.
+	Window     int       `json:"window,omitempty" yaml:"window,omitempty"`
+	StartedAt  time.Time `json:"started_at,omitzero" yaml:"started_at,omitempty"`
+	FinishedAt time.Time `json:"finished_at,omitzero" yaml:"finished_at,omitempty"`
+	// Error says why a failed task failed.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// NewTaskQueue queues prompts for a session of count windows. The first count
+// prompts are marked running in windows 1 to count, started at now, since
+// they are passed to the windows when they are spawned; the rest are pending.
+func NewTaskQueue(prompts []string, count int, now time.Time) []Task {
+	tasks := make([]Task, len(prompts))
+	for i, prompt := range prompts {
+		tasks[i] = Task{Prompt: prompt, State: TaskPending}
+		if i < count {
+			tasks[i].State = TaskRunning
+			tasks[i].Window = i + 1
+			tasks[i].StartedAt = now
+		}
+	}
+	return tasks
+}
+
+// RunningTask returns the index of the task running in window, numbered from
+// 1, or -1 if the window is free.
+func (s Session) RunningTask(window int) int {
+	for i, t := range s.Tasks {
+		if t.State == TaskRunning && t.Window == window {
+			return i
+		}
+	}
+	return -1
+}
+
+// NextPendingTask returns the index of the first pending task, or -1 if none
+// is left.
+func (s Session) NextPendingTask() int {
+	for i, t := range s.Tasks {
+		if t.State == TaskPending {
+			return i
+		}
+	}
+	return -1
+}
+
+// TaskCounts returns how many tasks of the session are in each state.
+func (s Session) TaskCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, t := range s.Tasks {
+		counts[t.State]++
+	}
+	return counts
+}
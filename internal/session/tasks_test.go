@@ -0,0 +1,67 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewTaskQueue(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	tasks := NewTaskQueue([]string{"a", "b", "c"}, 2, now)
+
+	if len(tasks) != 3 {
+		t.Fatalf("NewTaskQueue() returned %d tasks, want 3", len(tasks))
+	}
+	for i, want := range []Task{
+		{Prompt: "a", State: TaskRunning, Window: 1, StartedAt: now},
+		{Prompt: "b", State: TaskRunning, Window: 2, StartedAt: now},
+		{Prompt: "c", State: TaskPending},
+	} {
+		if tasks[i] != want {
+			t.Errorf("task %d = %+v, want %+v", i, tasks[i], want)
+		}
+	}
+}
+
+func TestTaskLookups(t *testing.T) {
+	sess := Session{Tasks: []Task{
+		{Prompt: "a", State: TaskDone, Window: 1},
+		{Prompt: "b", State: TaskRunning, Window: 2},
+		{Prompt: "c", State: TaskRunning, Window: 1},
+		{Prompt: "d", State: TaskPending},
+		{Prompt: "e", State: TaskPending},
+	}}
+
+	if got := sess.RunningTask(1); got != 2 {
+		t.Errorf("RunningTask(1) = %d, want 2", got)
+	}
+	if got := sess.RunningTask(3); got != -1 {
+		t.Errorf("RunningTask(3) = %d, want -1", got)
+	}
+	if got := sess.NextPendingTask(); got != 3 {
+		t.Errorf("NextPendingTask() = %d, want 3", got)
+	}
+	counts := sess.TaskCounts()
+	if counts[TaskDone] != 1 || counts[TaskRunning] != 2 || counts[TaskPending] != 2 {
+		t.Errorf("TaskCounts() = %v", counts)
+	}
+	if got := (Session{}).NextPendingTask(); got != -1 {
+		t.Errorf("NextPendingTask() of empty queue = %d, want -1", got)
+	}
+}
+
+func TestTasksRoundTrip(t *testing.T) {
+	store := NewStore(t.TempDir())
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	if err := store.SaveSession(Session{Name: "queue", Tasks: NewTaskQueue([]string{"a", "b"}, 1, now)}); err != nil {
+		t.Fatalf("SaveSession() error = %v", err)
+	}
+
+	loaded, err := store.LoadSession("queue")
+	if err != nil {
+		t.Fatalf("LoadSession() error = %v", err)
+	}
+	if len(loaded.Tasks) != 2 || !loaded.Tasks[0].StartedAt.Equal(now) || loaded.Tasks[1].State != TaskPending || !loaded.Tasks[1].StartedAt.IsZero() {
+		t.Errorf("Tasks = %+v", loaded.Tasks)
+	}
+}
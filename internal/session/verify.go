@@ -0,0 +1,26 @@
+package session
+
+import "time"
+
+// Verification is the outcome of a session's verification command in the
+// worktree of one window.
+type Verification struct {
+	// Window is numbered from 1.
+	Window int `json:"window" yaml:"window"`
+	// Rank orders the worktrees from 1, the best, by whether they changed
+	// anything, whether they passed, the size of their changes and the
+	// command's runtime. A worktree without changes ranks below every one
+	// with changes, even a failing one.
+	Rank   int  `json:"rank" yaml:"rank"`
+	Passed bool `json:"passed" yaml:"passed"`
+	// ExitCode is -1 when the command could not run, timed out or was killed.
+	ExitCode     int           `json:"exit_code" yaml:"exit_code"`
+	Duration     time.Duration `json:"duration" yaml:"duration"`
+	FilesChanged int           `json:"files_changed" yaml:"files_changed"`
+	Insertions   int           `json:"insertions" yaml:"insertions"`
+	Deletions    int           `json:"deletions" yaml:"deletions"`
+	LogPath      string        `json:"log_path,omitempty" yaml:"log_path,omitempty"`
+	FinishedAt   time.Time     `json:"finished_at" yaml:"finished_at"`
+	// Error says why the worktree did not pass.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
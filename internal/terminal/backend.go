@@ -1,20 +1,22 @@
-//go:build darwin
-
 package terminal
 
 import (
 	"context"
 	"fmt"
+	"runtime"
 	"strings"
 
+	"github.com/riricardoMa/claude-grid/internal/agent"
+	"github.com/riricardoMa/claude-grid/internal/claude"
 	"github.com/riricardoMa/claude-grid/internal/grid"
 	"github.com/riricardoMa/claude-grid/internal/screen"
 	"github.com/riricardoMa/claude-grid/internal/script"
+	"github.com/riricardoMa/claude-grid/internal/session"
 )
 
 // TerminalBackend defines the interface for spawning and managing terminal windows.
 type TerminalBackend interface {
-	// Name returns the name of the backend (e.g., "terminal", "warp").
+	// Name returns the name of the backend (e.g., "terminal", "warp", "tmux").
 	Name() string
 
 	// Available checks if the backend is available on the system.
@@ -28,14 +30,27 @@ type TerminalBackend interface {
 	CloseSession(sessionID string) error
 }
 
+// InputSender is implemented by backends that can deliver input to a window
+// they spawned, as if it were typed followed by Return.
+type InputSender interface {
+	SendInput(ctx context.Context, window session.WindowRef, text string) error
+}
+
+// OutputCapturer is implemented by backends that can read back the text shown
+// in a window. With scrollback set, the window's whole history is returned
+// instead of only its visible screen.
+type OutputCapturer interface {
+	CaptureOutput(ctx context.Context, window session.WindowRef, scrollback bool) (string, error)
+}
+
 // SpawnOptions contains the configuration for spawning terminal windows.
 type SpawnOptions struct {
 	// Count is the number of windows to spawn.
 	Count int
 
-	// Command is the command to run in each window (e.g., "claude").
-	// Defaults to "claude" if empty.
-	Command string
+	// Agent describes the program each window runs and how it receives its
+	// prompt. Defaults to the claude profile if Agent.Command is empty.
+	Agent agent.Profile
 
 	// Dir is the absolute working directory for the windows.
 	Dir string
@@ -44,10 +59,14 @@ type SpawnOptions struct {
 	Dirs []string
 
 	// Prompts is an optional list of per-window initial prompts. If set, Prompts[i] is passed
-	// to the claude command for window i as a positional argument. If empty or index out of
+	// to the agent for window i as its prompt mode describes. If empty or index out of
 	// range, no prompt is passed for that window.
 	Prompts []string
 
+	// ConversationIDs is an optional list of per-window Claude Code conversation IDs. If
+	// ConversationIDs[i] is set and the agent can resume, window i continues that conversation.
+	ConversationIDs []string
+
 	// Grid specifies the grid layout (rows and columns).
 	Grid grid.GridLayout
 
@@ -64,30 +83,64 @@ type SpawnOptions struct {
 
 // WindowInfo contains information about a spawned terminal window.
 type WindowInfo struct {
-	// ID is the unique identifier for the window (Terminal.app window ID or Warp window index).
+	// ID is the unique identifier for the window (Terminal.app window ID, Warp window title marker, or tmux pane ID).
 	ID string
 
 	// Index is the 0-based position in the grid.
 	Index int
 
-	// Backend is the name of the backend that created this window ("terminal" or "warp").
+	// Backend is the name of the backend that created this window ("terminal", "warp", or "tmux").
 	Backend string
 }
 
+// spawnProfile returns the agent the windows of opts run, the default when
+// opts names none.
+func spawnProfile(opts SpawnOptions) agent.Profile {
+	if strings.TrimSpace(opts.Agent.Command) == "" {
+		return agent.Default()
+	}
+	return opts.Agent
+}
+
+// windowCommands returns the shell command line each window runs: the agent
+// with that window's prompt, or resuming its conversation.
+func windowCommands(opts SpawnOptions) []string {
+	profile := spawnProfile(opts)
+	commands := make([]string, opts.Count)
+	for i := range commands {
+		var prompt, conversationID string
+		if i < len(opts.Prompts) {
+			prompt = opts.Prompts[i]
+		}
+		if i < len(opts.ConversationIDs) && claude.IsConversationID(opts.ConversationIDs[i]) {
+			conversationID = opts.ConversationIDs[i]
+		}
+		commands[i] = profile.CommandLine(prompt, conversationID)
+	}
+	return commands
+}
+
 // DetectBackend detects and returns the appropriate terminal backend.
-// If preferred is non-empty, it attempts to use that backend first.
-// Falls back to auto-detection (Warp > Terminal.app) if preferred is unavailable.
+// If preferred is non-empty, that backend is returned or an error explains why it is unavailable.
+// Auto-detection picks the first available of Warp > Terminal.app > tmux.
 func DetectBackend(preferred string) (TerminalBackend, error) {
 	executor := script.NewOSAExecutor()
 	warpBackend := NewWarpBackend(executor)
 	terminalBackend := NewTerminalAppBackend(executor)
+	tmuxBackend := NewTmuxBackend()
 
 	normalized := strings.ToLower(strings.TrimSpace(preferred))
 	if normalized == "" || normalized == "auto" {
 		if warpBackend.Available() {
 			return warpBackend, nil
 		}
-		return terminalBackend, nil
+		if terminalBackend.Available() {
+			return terminalBackend, nil
+		}
+		if tmuxBackend.Available() {
+			return tmuxBackend, nil
+		}
+		return nil, fmt.Errorf("no terminal backend available on %s. Install tmux and use --terminal tmux", runtime.GOOS)
 	}
 
 	switch normalized {
@@ -100,8 +153,13 @@ func DetectBackend(preferred string) (TerminalBackend, error) {
 		if terminalBackend.Available() {
 			return terminalBackend, nil
 		}
-		return nil, fmt.Errorf("terminal backend is not available")
+		return nil, fmt.Errorf("terminal backend is not available on %s (requires macOS)", runtime.GOOS)
+	case "tmux":
+		if tmuxBackend.Available() {
+			return tmuxBackend, nil
+		}
+		return nil, fmt.Errorf("tmux backend is not available. Install tmux or use --terminal terminal")
 	default:
-		return nil, fmt.Errorf("unsupported terminal backend %q. Available options: warp, terminal", preferred)
+		return nil, fmt.Errorf("unsupported terminal backend %q. Available options: warp, terminal, tmux", preferred)
 	}
 }
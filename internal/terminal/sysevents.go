@@ -1,5 +1,3 @@
-//go:build darwin
-
 package terminal
 
 import (
@@ -11,17 +9,21 @@ import (
 	"github.com/riricardoMa/claude-grid/internal/script"
 )
 
-func TileWarpWindows(executor script.ScriptExecutor, bounds []grid.WindowBounds) error {
+// TileWarpWindows moves the Warp windows of sessionID into bounds, in window
+// index order. Windows are found by their WarpWindowTitle, since System Events
+// numbers windows by stacking order, which changes as they are raised.
+func TileWarpWindows(executor script.ScriptExecutor, sessionID string, bounds []grid.WindowBounds) error {
 	lines := []string{
 		"tell application \"System Events\"",
 		"  tell process \"Warp\"",
 	}
 
 	for i, b := range bounds {
-		index := i + 1
+		title := script.SanitizeForAppleScript(WarpWindowTitle(sessionID, i))
 		lines = append(lines,
-			fmt.Sprintf("    set position of window %d to {%d, %d}", index, b.X, b.Y),
-			fmt.Sprintf("    set size of window %d to {%d, %d}", index, b.Width, b.Height),
+			fmt.Sprintf("    set w to first window whose name is \"%s\"", title),
+			fmt.Sprintf("    set position of w to {%d, %d}", b.X, b.Y),
+			fmt.Sprintf("    set size of w to {%d, %d}", b.Width, b.Height),
 		)
 	}
 
@@ -1,5 +1,3 @@
-//go:build darwin
-
 package terminal
 
 import (
@@ -8,19 +6,25 @@ import (
 	"strings"
 
 	"github.com/riricardoMa/claude-grid/internal/grid"
+	"github.com/riricardoMa/claude-grid/internal/platform"
 	"github.com/riricardoMa/claude-grid/internal/script"
 	"github.com/riricardoMa/claude-grid/internal/session"
 )
 
 const (
-	terminalTellStart   = "tell application \"Terminal\""
-	terminalTellEnd     = "end tell"
-	defaultSpawnCommand = "claude"
+	terminalTellStart = "tell application \"Terminal\""
+	terminalTellEnd   = "end tell"
+)
+
+var (
+	_ InputSender    = (*TerminalAppBackend)(nil)
+	_ OutputCapturer = (*TerminalAppBackend)(nil)
 )
 
 type TerminalAppBackend struct {
-	executor script.ScriptExecutor
-	store    *session.Store
+	executor       script.ScriptExecutor
+	store          *session.Store
+	hasAppleScript func() bool
 }
 
 func NewTerminalAppBackend(executor script.ScriptExecutor) *TerminalAppBackend {
@@ -29,8 +33,9 @@ func NewTerminalAppBackend(executor script.ScriptExecutor) *TerminalAppBackend {
 	}
 
 	return &TerminalAppBackend{
-		executor: executor,
-		store:    session.NewStore(""),
+		executor:       executor,
+		store:          session.NewStore(""),
+		hasAppleScript: platform.HasAppleScript,
 	}
 }
 
@@ -39,7 +44,7 @@ func (b *TerminalAppBackend) Name() string {
 }
 
 func (b *TerminalAppBackend) Available() bool {
-	return true
+	return b.hasAppleScript()
 }
 
 func (b *TerminalAppBackend) SpawnWindows(ctx context.Context, opts SpawnOptions) ([]WindowInfo, error) {
@@ -51,11 +56,6 @@ func (b *TerminalAppBackend) SpawnWindows(ctx context.Context, opts SpawnOptions
 		return nil, fmt.Errorf("insufficient bounds: got %d, need %d", len(opts.Bounds), opts.Count)
 	}
 
-	command := opts.Command
-	if strings.TrimSpace(command) == "" {
-		command = defaultSpawnCommand
-	}
-
 	var dirs []string
 	if len(opts.Dirs) > 0 {
 		dirs = opts.Dirs
@@ -66,7 +66,7 @@ func (b *TerminalAppBackend) SpawnWindows(ctx context.Context, opts SpawnOptions
 		}
 	}
 
-	spawnScript := buildSpawnScript(opts.Count, dirs, opts.Prompts, command, opts.Bounds)
+	spawnScript := buildSpawnScript(opts.Count, dirs, windowCommands(opts), opts.Bounds)
 	output, err := b.executor.RunAppleScript(ctx, spawnScript)
 	if err != nil {
 		return nil, fmt.Errorf("failed to spawn terminal windows: %w", err)
@@ -103,21 +103,39 @@ func (b *TerminalAppBackend) CloseSession(sessionID string) error {
 	return nil
 }
 
-func buildSpawnScript(count int, dirs []string, prompts []string, command string, bounds []grid.WindowBounds) string {
-	lines := []string{terminalTellStart}
+// SendInput runs text in the window's shell with do script, which types it
+// into the front tab followed by Return. The agent reads the text rather than
+// a shell, so it is escaped for the AppleScript string only.
+func (b *TerminalAppBackend) SendInput(ctx context.Context, window session.WindowRef, text string) error {
+	_, err := b.executor.RunAppleScript(ctx, buildSendInputScript(window.ID, text))
+	if err != nil {
+		return fmt.Errorf("send input to terminal window %s: %w", window.ID, err)
+	}
+	return nil
+}
 
-	sanitizedCommand := script.SanitizeForAppleScript(command)
+// CaptureOutput returns the contents of the window's selected tab, or its
+// history when scrollback is set.
+func (b *TerminalAppBackend) CaptureOutput(ctx context.Context, window session.WindowRef, scrollback bool) (string, error) {
+	output, err := b.executor.RunAppleScript(ctx, buildCaptureScript(window.ID, scrollback))
+	if err != nil {
+		return "", fmt.Errorf("capture terminal window %s: %w", window.ID, err)
+	}
+	return output, nil
+}
+
+func buildSpawnScript(count int, dirs []string, commands []string, bounds []grid.WindowBounds) string {
+	lines := []string{terminalTellStart}
 
 	for i := 0; i < count; i++ {
+		// The command is already shell-quoted, so only the AppleScript
+		// string needs escaping; the dir sits inside \"…\" in the shell.
+		sanitizedCommand := script.EscapeAppleScriptString(commands[i])
 		sanitizedDir := script.SanitizeForAppleScript(dirs[i])
 		windowCommand := sanitizedCommand
 		if strings.TrimSpace(sanitizedDir) != "" {
 			windowCommand = fmt.Sprintf("cd \\\"%s\\\" && %s", sanitizedDir, sanitizedCommand)
 		}
-		if i < len(prompts) && strings.TrimSpace(prompts[i]) != "" {
-			sanitizedPrompt := script.SanitizeForAppleScript(prompts[i])
-			windowCommand = fmt.Sprintf("%s \"%s\"", windowCommand, sanitizedPrompt)
-		}
 
 		bound := bounds[i]
 		right := bound.X + bound.Width
@@ -178,3 +196,21 @@ func buildCloseWindowScript(windowID string) string {
 
 	return strings.Join(lines, "\n")
 }
+
+func buildSendInputScript(windowID, text string) string {
+	lines := []string{
+		terminalTellStart,
+		fmt.Sprintf("do script \"%s\" in window id %s", script.EscapeAppleScriptString(text), script.SanitizeForAppleScript(windowID)),
+		terminalTellEnd,
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func buildCaptureScript(windowID string, scrollback bool) string {
+	property := "contents"
+	if scrollback {
+		property = "history"
+	}
+	return fmt.Sprintf("tell application \"Terminal\" to get %s of selected tab of window id %s", property, script.SanitizeForAppleScript(windowID))
+}
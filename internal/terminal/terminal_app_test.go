@@ -1,5 +1,3 @@
-//go:build darwin
-
 package terminal
 
 import (
@@ -9,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/riricardoMa/claude-grid/internal/agent"
 	"github.com/riricardoMa/claude-grid/internal/grid"
 	"github.com/riricardoMa/claude-grid/internal/script"
 	"github.com/riricardoMa/claude-grid/internal/session"
@@ -37,9 +36,12 @@ func TestTerminalAppName(t *testing.T) {
 }
 
 func TestTerminalAppAvailable(t *testing.T) {
-	backend := NewTerminalAppBackend(&mockScriptExecutor{})
-	if !backend.Available() {
-		t.Error("Available() = false, want true")
+	for _, hasAppleScript := range []bool{true, false} {
+		backend := NewTerminalAppBackend(&mockScriptExecutor{})
+		backend.hasAppleScript = func() bool { return hasAppleScript }
+		if got := backend.Available(); got != hasAppleScript {
+			t.Errorf("Available() with AppleScript=%v = %v, want %v", hasAppleScript, got, hasAppleScript)
+		}
 	}
 }
 
@@ -80,10 +82,10 @@ func TestTerminalAppSpawnScript(t *testing.T) {
 			defer cancel()
 
 			windows, err := backend.SpawnWindows(ctx, SpawnOptions{
-				Count:   tt.count,
-				Command: tt.command,
-				Dir:     tt.dir,
-				Bounds:  tt.bounds,
+				Count:  tt.count,
+				Agent:  agent.Profile{Command: tt.command, PromptMode: agent.PromptPositional},
+				Dir:    tt.dir,
+				Bounds: tt.bounds,
 			})
 
 			if (err != nil) != tt.wantErr {
@@ -204,10 +206,10 @@ func TestBuildSpawnScriptPerWindowDirsBackwardCompat(t *testing.T) {
 	defer cancel()
 
 	_, err := backend.SpawnWindows(ctx, SpawnOptions{
-		Count:   2,
-		Dir:     "/tmp/single-dir",
-		Command: "claude",
-		Bounds:  bounds,
+		Count:  2,
+		Dir:    "/tmp/single-dir",
+		Agent:  agent.Default(),
+		Bounds: bounds,
 	})
 	if err != nil {
 		t.Fatalf("SpawnWindows() error = %v", err)
@@ -226,12 +228,12 @@ func TestTerminalAppEscaping(t *testing.T) {
 	backend := NewTerminalAppBackend(executor)
 
 	dir := `/tmp/My "Special" Dir`
-	command := `printf "path\\value"`
+	command := `/opt/my "agent"/bin/run`
 
 	_, err := backend.SpawnWindows(context.Background(), SpawnOptions{
-		Count:   1,
-		Dir:     dir,
-		Command: command,
+		Count: 1,
+		Dir:   dir,
+		Agent: agent.Profile{Command: command, PromptMode: agent.PromptPositional},
 		Bounds: []grid.WindowBounds{
 			{X: 1, Y: 2, Width: 300, Height: 400},
 		},
@@ -246,7 +248,7 @@ func TestTerminalAppEscaping(t *testing.T) {
 
 	gotScript := executor.runs[0]
 	wantDir := script.SanitizeForAppleScript(dir)
-	wantCommand := script.SanitizeForAppleScript(command)
+	wantCommand := script.EscapeAppleScriptString(agent.ShellQuote(command))
 
 	if !strings.Contains(gotScript, wantDir) {
 		t.Errorf("script does not contain sanitized dir: %q", wantDir)
@@ -255,6 +257,25 @@ func TestTerminalAppEscaping(t *testing.T) {
 	if !strings.Contains(gotScript, wantCommand) {
 		t.Errorf("script does not contain sanitized command: %q", wantCommand)
 	}
+
+	// The prompt is single-quoted for the shell, where backslashes are kept
+	// literally, so shell characters must reach it unescaped.
+	executor = &mockScriptExecutor{output: "202"}
+	backend = NewTerminalAppBackend(executor)
+	_, err = backend.SpawnWindows(context.Background(), SpawnOptions{
+		Count:   1,
+		Dir:     "/tmp",
+		Prompts: []string{"run `go test` and $(echo hi) it's"},
+		Bounds:  []grid.WindowBounds{{X: 1, Y: 2, Width: 300, Height: 400}},
+	})
+	if err != nil {
+		t.Fatalf("SpawnWindows() error = %v", err)
+	}
+
+	wantLine := `do script "cd \"/tmp\" && claude 'run ` + "`go test`" + ` and $(echo hi) it'\\''s'"`
+	if !strings.Contains(executor.runs[0], wantLine+"\n") {
+		t.Errorf("script missing line %s\nscript:\n%s", wantLine, executor.runs[0])
+	}
 }
 
 func TestTerminalAppCloseGraceful(t *testing.T) {
@@ -322,7 +343,7 @@ func TestBuildSpawnScriptPerWindowPrompts(t *testing.T) {
 		{
 			name:        "prompt with special chars escaped",
 			prompts:     []string{`say "hello"`},
-			wantPrompts: []string{script.SanitizeForAppleScript(`say "hello"`)},
+			wantPrompts: []string{script.EscapeAppleScriptString(`say "hello"`)},
 		},
 	}
 
@@ -357,3 +378,48 @@ func TestBuildSpawnScriptPerWindowPrompts(t *testing.T) {
 func TestTerminalAppImplementsInterface(t *testing.T) {
 	var _ TerminalBackend = (*TerminalAppBackend)(nil)
 }
+
+func TestTerminalAppSendInput(t *testing.T) {
+	executor := &mockScriptExecutor{}
+	backend := NewTerminalAppBackend(executor)
+
+	err := backend.SendInput(context.Background(), session.WindowRef{ID: "42"}, `say "hi" \ run `+"`go test`"+` $(pwd)`)
+	if err != nil {
+		t.Fatalf("SendInput() error = %v", err)
+	}
+	if len(executor.runs) != 1 {
+		t.Fatalf("script count = %d, want 1", len(executor.runs))
+	}
+	want := `do script "say \"hi\" \\ run ` + "`go test`" + ` $(pwd)" in window id 42`
+	if !strings.Contains(executor.runs[0], want) {
+		t.Errorf("script = %q, want it to contain %q", executor.runs[0], want)
+	}
+
+	executor.err = errors.New("window not found")
+	if err := backend.SendInput(context.Background(), session.WindowRef{ID: "42"}, "x"); err == nil {
+		t.Fatal("SendInput() error = nil, want error")
+	}
+}
+
+func TestTerminalAppCaptureOutput(t *testing.T) {
+	executor := &mockScriptExecutor{output: "Last login\n$ claude"}
+	backend := NewTerminalAppBackend(executor)
+
+	got, err := backend.CaptureOutput(context.Background(), session.WindowRef{ID: "42"}, false)
+	if err != nil {
+		t.Fatalf("CaptureOutput() error = %v", err)
+	}
+	if got != "Last login\n$ claude" {
+		t.Errorf("CaptureOutput() = %q", got)
+	}
+	if want := "get contents of selected tab of window id 42"; !strings.Contains(executor.runs[0], want) {
+		t.Errorf("script = %q, want it to contain %q", executor.runs[0], want)
+	}
+
+	if _, err := backend.CaptureOutput(context.Background(), session.WindowRef{ID: "42"}, true); err != nil {
+		t.Fatalf("CaptureOutput(scrollback) error = %v", err)
+	}
+	if want := "get history of selected tab of window id 42"; !strings.Contains(executor.runs[1], want) {
+		t.Errorf("script = %q, want it to contain %q", executor.runs[1], want)
+	}
+}
@@ -0,0 +1,234 @@
+package terminal
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/riricardoMa/claude-grid/internal/grid"
+	"github.com/riricardoMa/claude-grid/internal/session"
+)
+
+const (
+	tmuxSessionPrefix = "claude-grid-"
+	tmuxPaneFormat    = "#{pane_id}"
+	tmuxDefaultWidth  = 240
+	tmuxDefaultHeight = 64
+)
+
+var (
+	_ TerminalBackend = (*TmuxBackend)(nil)
+	_ InputSender     = (*TmuxBackend)(nil)
+	_ OutputCapturer  = (*TmuxBackend)(nil)
+)
+
+// TmuxBackend lays out one tmux pane per instance inside a dedicated tmux
+// session, so claude-grid works over SSH and on machines without a GUI terminal.
+type TmuxBackend struct {
+	lookPath func(file string) (string, error)
+	runTmux  func(ctx context.Context, args ...string) (string, error)
+}
+
+func NewTmuxBackend() *TmuxBackend {
+	b := &TmuxBackend{
+		lookPath: exec.LookPath,
+	}
+	b.runTmux = b.defaultRunTmux
+	return b
+}
+
+func (b *TmuxBackend) Name() string {
+	return "tmux"
+}
+
+func (b *TmuxBackend) Available() bool {
+	_, err := b.lookPath("tmux")
+	return err == nil
+}
+
+// SpawnWindows creates a detached tmux session named after opts.SessionID and
+// splits it into opts.Count panes arranged row-major according to opts.Grid.
+// The returned window IDs are tmux pane IDs (e.g. "%3").
+func (b *TmuxBackend) SpawnWindows(ctx context.Context, opts SpawnOptions) ([]WindowInfo, error) {
+	if opts.Count <= 0 {
+		return nil, fmt.Errorf("window count must be positive")
+	}
+
+	layout := opts.Grid
+	if layout.Rows <= 0 || layout.Cols <= 0 {
+		layout = grid.CalculateGrid(opts.Count)
+	}
+	if layout.Rows*layout.Cols < opts.Count {
+		return nil, fmt.Errorf("layout %dx%d cannot fit %d panes", layout.Rows, layout.Cols, opts.Count)
+	}
+
+	var dirs []string
+	if len(opts.Dirs) > 0 {
+		dirs = opts.Dirs
+	} else {
+		dirs = make([]string, opts.Count)
+		for i := range dirs {
+			dirs[i] = opts.Dir
+		}
+	}
+	if len(dirs) < opts.Count {
+		return nil, fmt.Errorf("insufficient dirs: got %d, need %d", len(dirs), opts.Count)
+	}
+
+	commands := windowCommands(opts)
+	sessionName := TmuxSessionName(opts.SessionID)
+
+	paneIDs := make([]string, opts.Count)
+	first, err := b.runTmux(ctx, "new-session", "-d",
+		"-s", sessionName,
+		"-x", strconv.Itoa(tmuxDefaultWidth),
+		"-y", strconv.Itoa(tmuxDefaultHeight),
+		"-c", dirs[0],
+		"-P", "-F", tmuxPaneFormat,
+		commands[0])
+	if err != nil {
+		return nil, fmt.Errorf("create tmux session %q: %w", sessionName, err)
+	}
+	paneIDs[0] = strings.TrimSpace(first)
+
+	// Split off one row at a time from the bottom remainder, then split each
+	// row into columns the same way, so every pane ends up the same size.
+	rows := (opts.Count + layout.Cols - 1) / layout.Cols
+	remainder := paneIDs[0]
+	for r := 1; r < rows; r++ {
+		idx := r * layout.Cols
+		id, err := b.splitPane(ctx, remainder, "-v", rows-r, rows-r+1, dirs[idx], commands[idx])
+		if err != nil {
+			_ = b.killSession(sessionName)
+			return nil, err
+		}
+		paneIDs[idx] = id
+		remainder = id
+	}
+
+	for r := 0; r < rows; r++ {
+		rowStart := r * layout.Cols
+		inRow := layout.Cols
+		if opts.Count-rowStart < inRow {
+			inRow = opts.Count - rowStart
+		}
+
+		remainder := paneIDs[rowStart]
+		for c := 1; c < inRow; c++ {
+			idx := rowStart + c
+			id, err := b.splitPane(ctx, remainder, "-h", inRow-c, inRow-c+1, dirs[idx], commands[idx])
+			if err != nil {
+				_ = b.killSession(sessionName)
+				return nil, err
+			}
+			paneIDs[idx] = id
+			remainder = id
+		}
+	}
+
+	windows := make([]WindowInfo, opts.Count)
+	for i := 0; i < opts.Count; i++ {
+		windows[i] = WindowInfo{
+			ID:      paneIDs[i],
+			Index:   i,
+			Backend: b.Name(),
+		}
+	}
+
+	return windows, nil
+}
+
+// CloseSession kills the tmux session created for sessionID. A session that
+// no longer exists is treated as already closed.
+func (b *TmuxBackend) CloseSession(sessionID string) error {
+	err := b.killSession(TmuxSessionName(sessionID))
+	if err != nil && isTmuxSessionMissing(err) {
+		return nil
+	}
+	return err
+}
+
+// SendInput types text into the pane window.ID literally, then presses Enter.
+func (b *TmuxBackend) SendInput(ctx context.Context, window session.WindowRef, text string) error {
+	if _, err := b.runTmux(ctx, "send-keys", "-t", window.ID, "-l", text); err != nil {
+		return fmt.Errorf("send input to tmux pane %s: %w", window.ID, err)
+	}
+	if _, err := b.runTmux(ctx, "send-keys", "-t", window.ID, "Enter"); err != nil {
+		return fmt.Errorf("send input to tmux pane %s: %w", window.ID, err)
+	}
+	return nil
+}
+
+// CaptureOutput returns the text of pane window.ID with wrapped lines joined,
+// including its scrollback history when scrollback is set.
+func (b *TmuxBackend) CaptureOutput(ctx context.Context, window session.WindowRef, scrollback bool) (string, error) {
+	args := []string{"capture-pane", "-p", "-J", "-t", window.ID}
+	if scrollback {
+		args = append(args, "-S", "-")
+	}
+	output, err := b.runTmux(ctx, args...)
+	if err != nil {
+		return "", fmt.Errorf("capture tmux pane %s: %w", window.ID, err)
+	}
+	return output, nil
+}
+
+// PaneIDs returns the IDs of all panes currently alive in the tmux session
+// created for sessionID.
+func (b *TmuxBackend) PaneIDs(ctx context.Context, sessionID string) ([]string, error) {
+	output, err := b.runTmux(ctx, "list-panes", "-s", "-t", "="+TmuxSessionName(sessionID), "-F", tmuxPaneFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, line := range strings.Split(output, "\n") {
+		if id := strings.TrimSpace(line); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// TmuxSessionName returns the tmux session name used for a claude-grid session.
+// tmux forbids '.' and ':' in session names, so they are replaced with '_'.
+func TmuxSessionName(sessionID string) string {
+	replacer := strings.NewReplacer(".", "_", ":", "_")
+	return tmuxSessionPrefix + replacer.Replace(sessionID)
+}
+
+func (b *TmuxBackend) splitPane(ctx context.Context, target, direction string, newShare, totalShare int, dir, command string) (string, error) {
+	percent := newShare * 100 / totalShare
+	output, err := b.runTmux(ctx, "split-window", direction,
+		"-t", target,
+		"-l", fmt.Sprintf("%d%%", percent),
+		"-c", dir,
+		"-P", "-F", tmuxPaneFormat,
+		command)
+	if err != nil {
+		return "", fmt.Errorf("split tmux pane %s: %w", target, err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+func (b *TmuxBackend) killSession(sessionName string) error {
+	_, err := b.runTmux(context.Background(), "kill-session", "-t", "="+sessionName)
+	return err
+}
+
+func (b *TmuxBackend) defaultRunTmux(ctx context.Context, args ...string) (string, error) {
+	cmd := execCommandContext(ctx, "tmux", args...)
+	output, err := cmd.CombinedOutput()
+	outputStr := strings.TrimSpace(string(output))
+	if err != nil {
+		return "", fmt.Errorf("tmux %s failed: %w (output: %s)", args[0], err, outputStr)
+	}
+	return outputStr, nil
+}
+
+func isTmuxSessionMissing(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "can't find session") || strings.Contains(msg, "no server running")
+}
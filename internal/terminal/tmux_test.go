@@ -0,0 +1,372 @@
+package terminal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/riricardoMa/claude-grid/internal/grid"
+	"github.com/riricardoMa/claude-grid/internal/session"
+)
+
+type tmuxCall struct {
+	args []string
+}
+
+func newFakeTmuxBackend(runFn func(args []string) (string, error)) (*TmuxBackend, *[]tmuxCall) {
+	calls := &[]tmuxCall{}
+	nextPane := 0
+	b := NewTmuxBackend()
+	b.runTmux = func(ctx context.Context, args ...string) (string, error) {
+		*calls = append(*calls, tmuxCall{args: args})
+		if runFn != nil {
+			return runFn(args)
+		}
+		if args[0] == "new-session" || args[0] == "split-window" {
+			id := fmt.Sprintf("%%%d", nextPane)
+			nextPane++
+			return id, nil
+		}
+		return "", nil
+	}
+	return b, calls
+}
+
+func argValue(args []string, flag string) string {
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] == flag {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+func TestTmuxName(t *testing.T) {
+	b := NewTmuxBackend()
+	if got := b.Name(); got != "tmux" {
+		t.Fatalf("Name() = %q, want %q", got, "tmux")
+	}
+}
+
+func TestTmuxAvailable(t *testing.T) {
+	tests := []struct {
+		name     string
+		lookErr  error
+		expected bool
+	}{
+		{name: "tmux on PATH", expected: true},
+		{name: "tmux missing", lookErr: errors.New("not found"), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewTmuxBackend()
+			b.lookPath = func(file string) (string, error) {
+				if file != "tmux" {
+					t.Fatalf("lookPath(%q), want %q", file, "tmux")
+				}
+				return "/usr/bin/tmux", tt.lookErr
+			}
+			if got := b.Available(); got != tt.expected {
+				t.Fatalf("Available() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTmuxSessionName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "grid-ab12", want: "claude-grid-grid-ab12"},
+		{in: "v1.2:x", want: "claude-grid-v1_2_x"},
+	}
+	for _, tt := range tests {
+		if got := TmuxSessionName(tt.in); got != tt.want {
+			t.Errorf("TmuxSessionName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTmuxSpawnWindowsLayout(t *testing.T) {
+	tests := []struct {
+		name        string
+		count       int
+		layout      grid.GridLayout
+		wantSplitsV int
+		wantSplitsH int
+	}{
+		{name: "single pane", count: 1, layout: grid.GridLayout{Rows: 1, Cols: 1}},
+		{name: "one row of three", count: 3, layout: grid.GridLayout{Rows: 1, Cols: 3}, wantSplitsH: 2},
+		{name: "2x2", count: 4, layout: grid.GridLayout{Rows: 2, Cols: 2}, wantSplitsV: 1, wantSplitsH: 2},
+		{name: "partial last row", count: 5, layout: grid.GridLayout{Rows: 2, Cols: 3}, wantSplitsV: 1, wantSplitsH: 3},
+		{name: "auto layout", count: 6, wantSplitsV: 1, wantSplitsH: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, calls := newFakeTmuxBackend(nil)
+
+			windows, err := b.SpawnWindows(context.Background(), SpawnOptions{
+				Count:     tt.count,
+				Dir:       "/tmp",
+				Grid:      tt.layout,
+				SessionID: "grid-test",
+			})
+			if err != nil {
+				t.Fatalf("SpawnWindows() error = %v", err)
+			}
+
+			if (*calls)[0].args[0] != "new-session" {
+				t.Fatalf("first tmux call = %v, want new-session", (*calls)[0].args)
+			}
+			if got := argValue((*calls)[0].args, "-s"); got != "claude-grid-grid-test" {
+				t.Errorf("session name = %q, want %q", got, "claude-grid-grid-test")
+			}
+
+			splitsV, splitsH := 0, 0
+			for _, c := range (*calls)[1:] {
+				if c.args[0] != "split-window" {
+					t.Fatalf("unexpected tmux call %v", c.args)
+				}
+				switch c.args[1] {
+				case "-v":
+					splitsV++
+				case "-h":
+					splitsH++
+				}
+			}
+			if splitsV != tt.wantSplitsV || splitsH != tt.wantSplitsH {
+				t.Errorf("splits = %d vertical, %d horizontal; want %d, %d", splitsV, splitsH, tt.wantSplitsV, tt.wantSplitsH)
+			}
+
+			if len(windows) != tt.count {
+				t.Fatalf("len(windows) = %d, want %d", len(windows), tt.count)
+			}
+			seen := make(map[string]bool)
+			for i, w := range windows {
+				if w.Index != i || w.Backend != "tmux" {
+					t.Errorf("window %d = %+v", i, w)
+				}
+				if !strings.HasPrefix(w.ID, "%") || seen[w.ID] {
+					t.Errorf("window %d has invalid or duplicate pane id %q", i, w.ID)
+				}
+				seen[w.ID] = true
+			}
+		})
+	}
+}
+
+func TestTmuxSpawnEqualSplits(t *testing.T) {
+	b, calls := newFakeTmuxBackend(nil)
+
+	_, err := b.SpawnWindows(context.Background(), SpawnOptions{
+		Count:     3,
+		Dir:       "/tmp",
+		Grid:      grid.GridLayout{Rows: 3, Cols: 1},
+		SessionID: "grid-test",
+	})
+	if err != nil {
+		t.Fatalf("SpawnWindows() error = %v", err)
+	}
+
+	want := []string{"66%", "50%"}
+	for i, w := range want {
+		if got := argValue((*calls)[i+1].args, "-l"); got != w {
+			t.Errorf("split %d size = %q, want %q", i, got, w)
+		}
+	}
+}
+
+func TestTmuxPerPaneDirsAndPrompts(t *testing.T) {
+	b, calls := newFakeTmuxBackend(nil)
+
+	windows, err := b.SpawnWindows(context.Background(), SpawnOptions{
+		Count:     3,
+		Dirs:      []string{"/repo/a", "/repo/b", "/repo/c"},
+		Prompts:   []string{"fix login", "", "it's broken"},
+		Grid:      grid.GridLayout{Rows: 1, Cols: 3},
+		SessionID: "grid-test",
+	})
+	if err != nil {
+		t.Fatalf("SpawnWindows() error = %v", err)
+	}
+
+	wantDirs := []string{"/repo/a", "/repo/b", "/repo/c"}
+	wantCommands := []string{"claude 'fix login'", "claude", `claude 'it'\''s broken'`}
+	for i, c := range *calls {
+		if got := argValue(c.args, "-c"); got != wantDirs[i] {
+			t.Errorf("pane %d dir = %q, want %q", i, got, wantDirs[i])
+		}
+		if got := c.args[len(c.args)-1]; got != wantCommands[i] {
+			t.Errorf("pane %d command = %q, want %q", i, got, wantCommands[i])
+		}
+	}
+	if len(windows) != 3 {
+		t.Fatalf("len(windows) = %d, want 3", len(windows))
+	}
+}
+
+func TestTmuxSpawnErrors(t *testing.T) {
+	t.Run("zero count", func(t *testing.T) {
+		b, _ := newFakeTmuxBackend(nil)
+		if _, err := b.SpawnWindows(context.Background(), SpawnOptions{Count: 0}); err == nil {
+			t.Fatal("expected error for zero count")
+		}
+	})
+
+	t.Run("layout too small", func(t *testing.T) {
+		b, _ := newFakeTmuxBackend(nil)
+		_, err := b.SpawnWindows(context.Background(), SpawnOptions{
+			Count: 5,
+			Dir:   "/tmp",
+			Grid:  grid.GridLayout{Rows: 2, Cols: 2},
+		})
+		if err == nil {
+			t.Fatal("expected error for layout too small")
+		}
+	})
+
+	t.Run("split failure kills session", func(t *testing.T) {
+		b, calls := newFakeTmuxBackend(func(args []string) (string, error) {
+			switch args[0] {
+			case "new-session":
+				return "%0", nil
+			case "split-window":
+				return "", errors.New("no space for new pane")
+			}
+			return "", nil
+		})
+
+		_, err := b.SpawnWindows(context.Background(), SpawnOptions{
+			Count:     2,
+			Dir:       "/tmp",
+			SessionID: "grid-test",
+		})
+		if err == nil {
+			t.Fatal("expected split error")
+		}
+
+		last := (*calls)[len(*calls)-1].args
+		if last[0] != "kill-session" || argValue(last, "-t") != "=claude-grid-grid-test" {
+			t.Errorf("last tmux call = %v, want kill-session of the grid session", last)
+		}
+	})
+}
+
+func TestTmuxCloseSession(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr bool
+	}{
+		{name: "session killed"},
+		{name: "session already gone", err: errors.New("can't find session: claude-grid-grid-test")},
+		{name: "no server", err: errors.New("no server running on /tmp/tmux-501/default")},
+		{name: "other failure", err: errors.New("permission denied"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, calls := newFakeTmuxBackend(func(args []string) (string, error) {
+				return "", tt.err
+			})
+
+			err := b.CloseSession("grid-test")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CloseSession() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if len(*calls) != 1 {
+				t.Fatalf("tmux call count = %d, want 1", len(*calls))
+			}
+			args := (*calls)[0].args
+			if args[0] != "kill-session" || argValue(args, "-t") != "=claude-grid-grid-test" {
+				t.Errorf("tmux args = %v, want exact kill-session target", args)
+			}
+		})
+	}
+}
+
+func TestTmuxPaneIDs(t *testing.T) {
+	b, _ := newFakeTmuxBackend(func(args []string) (string, error) {
+		return "%1\n%2\n\n%5\n", nil
+	})
+
+	ids, err := b.PaneIDs(context.Background(), "grid-test")
+	if err != nil {
+		t.Fatalf("PaneIDs() error = %v", err)
+	}
+	if strings.Join(ids, ",") != "%1,%2,%5" {
+		t.Errorf("PaneIDs() = %v, want [%%1 %%2 %%5]", ids)
+	}
+}
+
+func TestTmuxResumesConversations(t *testing.T) {
+	b, calls := newFakeTmuxBackend(nil)
+
+	_, err := b.SpawnWindows(context.Background(), SpawnOptions{
+		Count:           2,
+		Dir:             "/tmp",
+		Prompts:         []string{"", "add tests"},
+		ConversationIDs: []string{"1205b5d1-2d9e-4429-8617-4c7087ce0bf6", "not-a-uuid"},
+		SessionID:       "grid-test",
+	})
+	if err != nil {
+		t.Fatalf("SpawnWindows() error = %v", err)
+	}
+
+	want := []string{"claude --resume 1205b5d1-2d9e-4429-8617-4c7087ce0bf6", "claude 'add tests'"}
+	for i, c := range *calls {
+		if got := c.args[len(c.args)-1]; got != want[i] {
+			t.Errorf("pane %d command = %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestTmuxSendInput(t *testing.T) {
+	b, calls := newFakeTmuxBackend(nil)
+
+	if err := b.SendInput(context.Background(), session.WindowRef{ID: "%3", Index: 2}, "run the tests; commit"); err != nil {
+		t.Fatalf("SendInput() error = %v", err)
+	}
+
+	if len(*calls) != 2 {
+		t.Fatalf("tmux call count = %d, want 2", len(*calls))
+	}
+	typed := (*calls)[0].args
+	if typed[0] != "send-keys" || argValue(typed, "-t") != "%3" || argValue(typed, "-l") != "run the tests; commit" {
+		t.Errorf("first send-keys args = %v, want literal text to %%3", typed)
+	}
+	enter := (*calls)[1].args
+	if enter[0] != "send-keys" || argValue(enter, "-t") != "%3" || enter[len(enter)-1] != "Enter" {
+		t.Errorf("second send-keys args = %v, want Enter to %%3", enter)
+	}
+}
+
+func TestTmuxCaptureOutput(t *testing.T) {
+	for _, scrollback := range []bool{false, true} {
+		b, calls := newFakeTmuxBackend(func(args []string) (string, error) {
+			return "$ make test\nok", nil
+		})
+
+		got, err := b.CaptureOutput(context.Background(), session.WindowRef{ID: "%1"}, scrollback)
+		if err != nil {
+			t.Fatalf("CaptureOutput() error = %v", err)
+		}
+		if got != "$ make test\nok" {
+			t.Errorf("CaptureOutput() = %q", got)
+		}
+
+		args := (*calls)[0].args
+		if args[0] != "capture-pane" || argValue(args, "-t") != "%1" {
+			t.Errorf("tmux args = %v, want capture-pane of %%1", args)
+		}
+		if hasHistory := argValue(args, "-S") == "-"; hasHistory != scrollback {
+			t.Errorf("scrollback=%v: tmux args = %v", scrollback, args)
+		}
+	}
+}
@@ -1,5 +1,3 @@
-//go:build darwin
-
 package terminal
 
 import (
@@ -8,44 +6,58 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/riricardoMa/claude-grid/internal/grid"
+	"github.com/riricardoMa/claude-grid/internal/platform"
 	"github.com/riricardoMa/claude-grid/internal/script"
+	"github.com/riricardoMa/claude-grid/internal/session"
+	"gopkg.in/yaml.v3"
 )
 
 const (
 	warpAppPath            = "/Applications/Warp.app"
-	warpSpawnDelay         = 500 * time.Millisecond
 	warpInitialBackoff     = 100 * time.Millisecond
 	warpMaxBackoff         = time.Second
 	warpWaitTimeout        = 15 * time.Second
 	warpFreshLaunchDelay   = 3 * time.Second
 	warpAccessibilityGuide = "Accessibility permission required. Go to System Settings > Privacy & Security > Accessibility and add your terminal app"
+	warpTitlePrefix        = "claude-grid:"
 )
 
 var execCommandContext = exec.CommandContext
 
-var _ TerminalBackend = (*WarpBackend)(nil)
+var warpUnsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9.-]`)
+
+var (
+	_ TerminalBackend = (*WarpBackend)(nil)
+	_ InputSender     = (*WarpBackend)(nil)
+)
 
 type WarpBackend struct {
-	executor script.ScriptExecutor
-	statFn   func(path string) (os.FileInfo, error)
-	runOpen  func(ctx context.Context, uri string) error
-	sleepFn  func(d time.Duration)
+	executor        script.ScriptExecutor
+	launchConfigDir string
+	statFn          func(path string) (os.FileInfo, error)
+	runOpen         func(ctx context.Context, uri string) error
+	sleepFn         func(d time.Duration)
+	hasAppleScript  func() bool
 
 	isWarpRunningFn      func(ctx context.Context) (bool, error)
-	waitForWindowCountFn func(ctx context.Context, target int) error
-	tileWindowsFn        func(ctx context.Context, bounds []grid.WindowBounds) error
+	waitForWindowCountFn func(ctx context.Context, sessionID string, target int) error
+	tileWindowsFn        func(ctx context.Context, sessionID string, bounds []grid.WindowBounds) error
 }
 
 func NewWarpBackend(executor script.ScriptExecutor) *WarpBackend {
 	b := &WarpBackend{
-		executor: executor,
-		statFn:   os.Stat,
-		sleepFn:  time.Sleep,
+		executor:        executor,
+		launchConfigDir: defaultWarpLaunchConfigDir(),
+		statFn:          os.Stat,
+		sleepFn:         time.Sleep,
+		hasAppleScript:  platform.HasAppleScript,
 	}
 	b.runOpen = b.defaultRunOpen
 	b.isWarpRunningFn = b.isWarpRunning
@@ -58,11 +70,19 @@ func (b *WarpBackend) Name() string {
 	return "warp"
 }
 
+// Available reports whether Warp is installed. Window control goes through
+// System Events, so the backend is unavailable where AppleScript is missing.
 func (b *WarpBackend) Available() bool {
+	if !b.hasAppleScript() {
+		return false
+	}
 	_, err := b.statFn(warpAppPath)
 	return err == nil
 }
 
+// SpawnWindows writes a launch configuration with one window per instance,
+// opens it through Warp's URI scheme and tiles the resulting windows. Each
+// window runs its command from the configuration, so nothing is typed into it.
 func (b *WarpBackend) SpawnWindows(ctx context.Context, opts SpawnOptions) ([]WindowInfo, error) {
 	if opts.Count <= 0 {
 		return nil, fmt.Errorf("count must be > 0")
@@ -71,66 +91,44 @@ func (b *WarpBackend) SpawnWindows(ctx context.Context, opts SpawnOptions) ([]Wi
 		return nil, fmt.Errorf("insufficient bounds: got %d, need %d", len(opts.Bounds), opts.Count)
 	}
 
+	config, err := BuildWarpLaunchConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	wasRunning, err := b.isWarpRunningFn(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	var dirs []string
-	if len(opts.Dirs) > 0 {
-		dirs = opts.Dirs
-	} else {
-		dirs = make([]string, opts.Count)
-		for i := range dirs {
-			dirs[i] = opts.Dir
-		}
+	configPath := b.launchConfigPath(opts.SessionID)
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+		return nil, fmt.Errorf("create warp launch configuration dir: %w", err)
+	}
+	if err := os.WriteFile(configPath, config, 0o644); err != nil {
+		return nil, fmt.Errorf("write warp launch configuration: %w", err)
 	}
 
-	for i := 0; i < opts.Count; i++ {
-		encodedPath := strings.ReplaceAll(url.PathEscape(dirs[i]), "%2F", "/")
-		uri := fmt.Sprintf("warp://action/new_window?path=%s", encodedPath)
-		if err := b.runOpen(ctx, uri); err != nil {
-			return nil, fmt.Errorf("open warp uri: %w", err)
-		}
-		if i < opts.Count-1 {
-			b.sleepFn(warpSpawnDelay)
-		}
+	if err := b.runOpen(ctx, "warp://launch/"+url.PathEscape(filepath.Base(configPath))); err != nil {
+		return nil, fmt.Errorf("open warp uri: %w", err)
 	}
 
 	if !wasRunning {
 		b.sleepFn(warpFreshLaunchDelay)
 	}
 
-	if err := b.waitForWindowCountFn(ctx, opts.Count); err != nil {
+	if err := b.waitForWindowCountFn(ctx, opts.SessionID, opts.Count); err != nil {
 		return nil, err
 	}
 
-	if err := b.tileWindowsFn(ctx, opts.Bounds[:opts.Count]); err != nil {
+	if err := b.tileWindowsFn(ctx, opts.SessionID, opts.Bounds[:opts.Count]); err != nil {
 		return nil, err
 	}
 
-	baseCommand := opts.Command
-	if strings.TrimSpace(baseCommand) == "" {
-		baseCommand = "claude"
-	}
-
-	commands := make([]string, opts.Count)
-	for i := 0; i < opts.Count; i++ {
-		if i < len(opts.Prompts) && strings.TrimSpace(opts.Prompts[i]) != "" {
-			commands[i] = fmt.Sprintf("%s \"%s\"", baseCommand, opts.Prompts[i])
-		} else {
-			commands[i] = baseCommand
-		}
-	}
-
-	if err := b.sendCommandsToWindows(ctx, commands); err != nil {
-		return nil, fmt.Errorf("send command to warp windows: %w", err)
-	}
-
 	windows := make([]WindowInfo, opts.Count)
 	for i := 0; i < opts.Count; i++ {
 		windows[i] = WindowInfo{
-			ID:      strconv.Itoa(i + 1),
+			ID:      WarpWindowTitle(opts.SessionID, i),
 			Index:   i,
 			Backend: b.Name(),
 		}
@@ -139,23 +137,159 @@ func (b *WarpBackend) SpawnWindows(ctx context.Context, opts SpawnOptions) ([]Wi
 	return windows, nil
 }
 
+// CloseSession closes the Warp windows whose title carries the marker of
+// sessionID and removes the session's launch configuration. Other Warp
+// windows, including other grids, are left open.
 func (b *WarpBackend) CloseSession(sessionID string) error {
-	_ = sessionID
-	scriptText := strings.Join([]string{
+	_, err := b.executor.RunAppleScript(context.Background(), buildWarpCloseScript(sessionID))
+	if err != nil {
+		return wrapAccessibilityError(fmt.Errorf("close warp windows: %w", err))
+	}
+
+	if err := os.Remove(b.launchConfigPath(sessionID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove warp launch configuration: %w", err)
+	}
+	return nil
+}
+
+// SendInput raises the window titled with window.ID and types text into it
+// through System Events, followed by Return.
+func (b *WarpBackend) SendInput(ctx context.Context, window session.WindowRef, text string) error {
+	_, err := b.executor.RunAppleScript(ctx, buildWarpSendInputScript(window.ID, text))
+	if err != nil {
+		return wrapAccessibilityError(fmt.Errorf("send input to warp window %s: %w", window.ID, err))
+	}
+	return nil
+}
+
+// WarpWindowTitle returns the title marker given to window index of a
+// session. It doubles as the window ID, since System Events exposes no
+// stable identity for Warp windows.
+func WarpWindowTitle(sessionID string, index int) string {
+	return fmt.Sprintf("%s%s:%d", warpTitlePrefix, sessionID, index+1)
+}
+
+type warpLaunchConfig struct {
+	Name    string             `yaml:"name"`
+	Windows []warpLaunchWindow `yaml:"windows"`
+}
+
+type warpLaunchWindow struct {
+	Tabs []warpLaunchTab `yaml:"tabs"`
+}
+
+type warpLaunchTab struct {
+	Title  string         `yaml:"title"`
+	Layout warpLaunchPane `yaml:"layout"`
+}
+
+type warpLaunchPane struct {
+	Cwd      string              `yaml:"cwd"`
+	Commands []warpLaunchCommand `yaml:"commands"`
+}
+
+type warpLaunchCommand struct {
+	Exec string `yaml:"exec"`
+}
+
+// BuildWarpLaunchConfig returns the Warp launch configuration YAML for opts:
+// one window per instance, titled with its WarpWindowTitle marker, opened in
+// its directory and running its command.
+func BuildWarpLaunchConfig(opts SpawnOptions) ([]byte, error) {
+	if opts.Count <= 0 {
+		return nil, fmt.Errorf("count must be > 0")
+	}
+
+	dirs := opts.Dirs
+	if len(dirs) == 0 {
+		dirs = make([]string, opts.Count)
+		for i := range dirs {
+			dirs[i] = opts.Dir
+		}
+	}
+	if len(dirs) < opts.Count {
+		return nil, fmt.Errorf("insufficient dirs: got %d, need %d", len(dirs), opts.Count)
+	}
+
+	commands := windowCommands(opts)
+	if spawnProfile(opts).Name == "claude" {
+		// Claude Code retitles its terminal, which would drop the marker.
+		// Exported, so it reaches claude at the end of a pipe too.
+		for i := range commands {
+			commands[i] = "export CLAUDE_CODE_DISABLE_TERMINAL_TITLE=1; " + commands[i]
+		}
+	}
+
+	config := warpLaunchConfig{Name: "claude-grid-" + opts.SessionID}
+	for i := 0; i < opts.Count; i++ {
+		config.Windows = append(config.Windows, warpLaunchWindow{
+			Tabs: []warpLaunchTab{{
+				Title: WarpWindowTitle(opts.SessionID, i),
+				Layout: warpLaunchPane{
+					Cwd:      dirs[i],
+					Commands: []warpLaunchCommand{{Exec: commands[i]}},
+				},
+			}},
+		})
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("encode warp launch configuration: %w", err)
+	}
+	return append([]byte("# Auto-generated by claude-grid\n"), data...), nil
+}
+
+// launchConfigPath returns where the launch configuration for sessionID is
+// written. Characters other than letters, digits, '-' and '.' are replaced
+// with '_' so any session name yields a single file name.
+func (b *WarpBackend) launchConfigPath(sessionID string) string {
+	name := warpUnsafeFileChars.ReplaceAllString(sessionID, "_")
+	return filepath.Join(b.launchConfigDir, "_claude_grid_"+name+".yaml")
+}
+
+func defaultWarpLaunchConfigDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "~/.warp/launch_configurations"
+	}
+	return filepath.Join(home, ".warp", "launch_configurations")
+}
+
+func buildWarpCloseScript(sessionID string) string {
+	marker := script.SanitizeForAppleScript(warpSessionMarker(sessionID))
+	return strings.Join([]string{
 		"tell application \"System Events\"",
 		"  tell process \"Warp\"",
-		"    repeat while (count windows) > 0",
-		"      close window 1",
+		fmt.Sprintf("    repeat with w in (get every window whose name contains \"%s\")", marker),
+		"      try",
+		"        click (first button of w whose subrole is \"AXCloseButton\")",
+		"      end try",
 		"    end repeat",
 		"  end tell",
 		"end tell",
 	}, "\n")
+}
 
-	_, err := b.executor.RunAppleScript(context.Background(), scriptText)
-	if err != nil {
-		return wrapAccessibilityError(fmt.Errorf("close warp windows: %w", err))
-	}
-	return nil
+func buildWarpSendInputScript(title, text string) string {
+	return strings.Join([]string{
+		"tell application \"System Events\"",
+		"  tell process \"Warp\"",
+		"    set frontmost to true",
+		// An exact match, since window 1's title is a prefix of window 10's.
+		fmt.Sprintf("    set w to first window whose name is \"%s\"", script.SanitizeForAppleScript(title)),
+		"    perform action \"AXRaise\" of w",
+		"    delay 0.2",
+		// Typed into the agent, not a shell, so shell characters stay as is.
+		fmt.Sprintf("    keystroke \"%s\"", script.EscapeAppleScriptString(text)),
+		"    keystroke return",
+		"  end tell",
+		"end tell",
+	}, "\n")
+}
+
+func warpSessionMarker(sessionID string) string {
+	return warpTitlePrefix + sessionID + ":"
 }
 
 func (b *WarpBackend) defaultRunOpen(ctx context.Context, uri string) error {
@@ -176,7 +310,9 @@ func (b *WarpBackend) isWarpRunning(ctx context.Context) (bool, error) {
 	return value == "true", nil
 }
 
-func (b *WarpBackend) waitForWindowCount(ctx context.Context, target int) error {
+// waitForWindowCount polls until target windows carrying the marker of
+// sessionID exist, so windows that were already open are not counted.
+func (b *WarpBackend) waitForWindowCount(ctx context.Context, sessionID string, target int) error {
 	deadline := time.Now().Add(warpWaitTimeout)
 	delay := warpInitialBackoff
 
@@ -185,7 +321,7 @@ func (b *WarpBackend) waitForWindowCount(ctx context.Context, target int) error
 			return err
 		}
 
-		count, err := b.currentWindowCount(ctx)
+		count, err := b.currentWindowCount(ctx, sessionID)
 		if err != nil {
 			return err
 		}
@@ -205,8 +341,9 @@ func (b *WarpBackend) waitForWindowCount(ctx context.Context, target int) error
 	}
 }
 
-func (b *WarpBackend) currentWindowCount(ctx context.Context) (int, error) {
-	output, err := b.executor.RunAppleScript(ctx, `tell application "System Events" to tell process "Warp" to count windows`)
+func (b *WarpBackend) currentWindowCount(ctx context.Context, sessionID string) (int, error) {
+	marker := script.SanitizeForAppleScript(warpSessionMarker(sessionID))
+	output, err := b.executor.RunAppleScript(ctx, fmt.Sprintf(`tell application "System Events" to tell process "Warp" to count (every window whose name contains "%s")`, marker))
 	if err != nil {
 		return 0, wrapAccessibilityError(fmt.Errorf("count warp windows: %w", err))
 	}
@@ -218,34 +355,9 @@ func (b *WarpBackend) currentWindowCount(ctx context.Context) (int, error) {
 	return count, nil
 }
 
-func (b *WarpBackend) sendCommandsToWindows(ctx context.Context, commands []string) error {
-	lines := []string{
-		"tell application \"System Events\"",
-		"  tell process \"Warp\"",
-	}
-	for i, cmd := range commands {
-		sanitizedCmd := script.SanitizeForAppleScript(cmd)
-		lines = append(lines,
-			"    set frontmost to true",
-			fmt.Sprintf("    perform action \"AXRaise\" of window %d", i+1),
-			"    delay 0.3",
-			fmt.Sprintf("    keystroke \"%s\"", sanitizedCmd),
-			"    keystroke return",
-			"    delay 0.2",
-		)
-	}
-	lines = append(lines, "  end tell", "end tell")
-
-	_, err := b.executor.RunAppleScript(ctx, strings.Join(lines, "\n"))
-	if err != nil {
-		return wrapAccessibilityError(err)
-	}
-	return nil
-}
-
-func (b *WarpBackend) tileWindows(ctx context.Context, bounds []grid.WindowBounds) error {
+func (b *WarpBackend) tileWindows(ctx context.Context, sessionID string, bounds []grid.WindowBounds) error {
 	if err := ctx.Err(); err != nil {
 		return err
 	}
-	return TileWarpWindows(b.executor, bounds)
+	return TileWarpWindows(b.executor, sessionID, bounds)
 }
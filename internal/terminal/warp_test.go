@@ -1,5 +1,3 @@
-//go:build darwin
-
 package terminal
 
 import (
@@ -7,11 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/riricardoMa/claude-grid/internal/agent"
 	"github.com/riricardoMa/claude-grid/internal/grid"
+	"github.com/riricardoMa/claude-grid/internal/session"
+	"gopkg.in/yaml.v3"
 )
 
 type warpMockExecutor struct {
@@ -36,18 +39,21 @@ func TestWarpName(t *testing.T) {
 
 func TestWarpAvailable(t *testing.T) {
 	tests := []struct {
-		name     string
-		statErr  error
-		expected bool
+		name       string
+		statErr    error
+		noAppleScr bool
+		expected   bool
 	}{
 		{name: "app exists", expected: true},
 		{name: "app missing", statErr: os.ErrNotExist, expected: false},
 		{name: "permission error", statErr: errors.New("permission denied"), expected: false},
+		{name: "no applescript", noAppleScr: true, expected: false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			b := NewWarpBackend(&warpMockExecutor{})
+			b.hasAppleScript = func() bool { return !tt.noAppleScr }
 			b.statFn = func(path string) (os.FileInfo, error) {
 				if path != "/Applications/Warp.app" {
 					t.Fatalf("stat path = %q, want %q", path, "/Applications/Warp.app")
@@ -62,35 +68,180 @@ func TestWarpAvailable(t *testing.T) {
 	}
 }
 
-func TestWarpURIConstruction(t *testing.T) {
-	b := NewWarpBackend(&warpMockExecutor{})
+// newTestWarpBackend returns a WarpBackend that writes launch configurations
+// to a temp dir and never talks to Warp.
+func newTestWarpBackend(t *testing.T, executor *warpMockExecutor) *WarpBackend {
+	t.Helper()
+	b := NewWarpBackend(executor)
+	b.launchConfigDir = t.TempDir()
+	b.runOpen = func(ctx context.Context, uri string) error { return nil }
+	b.sleepFn = func(time.Duration) {}
+	b.isWarpRunningFn = func(context.Context) (bool, error) { return true, nil }
+	b.waitForWindowCountFn = func(context.Context, string, int) error { return nil }
+	b.tileWindowsFn = func(context.Context, string, []grid.WindowBounds) error { return nil }
+	return b
+}
+
+func testBounds(count int) []grid.WindowBounds {
+	bounds := make([]grid.WindowBounds, count)
+	for i := range bounds {
+		bounds[i] = grid.WindowBounds{X: i * 100, Y: 0, Width: 100, Height: 100}
+	}
+	return bounds
+}
+
+func TestWarpSpawnOpensLaunchConfig(t *testing.T) {
+	b := newTestWarpBackend(t, &warpMockExecutor{})
 	var uris []string
 	b.runOpen = func(ctx context.Context, uri string) error {
 		uris = append(uris, uri)
 		return nil
 	}
-	b.sleepFn = func(time.Duration) {}
-	b.waitForWindowCountFn = func(context.Context, int) error { return nil }
-	b.tileWindowsFn = func(context.Context, []grid.WindowBounds) error { return nil }
 
-	_, err := b.SpawnWindows(context.Background(), SpawnOptions{
-		Count: 1,
-		Dir:   "/Users/bob/my project",
-		Bounds: []grid.WindowBounds{
-			{X: 0, Y: 0, Width: 100, Height: 100},
-		},
-	})
+	opts := SpawnOptions{
+		Count:     2,
+		Dir:       "/Users/bob/my project",
+		SessionID: "my sprint",
+		Bounds:    testBounds(2),
+	}
+	got, err := b.SpawnWindows(context.Background(), opts)
 	if err != nil {
 		t.Fatalf("SpawnWindows() error = %v", err)
 	}
 
-	if len(uris) != 1 {
-		t.Fatalf("open call count = %d, want 1", len(uris))
+	want := []string{"warp://launch/_claude_grid_my_sprint.yaml"}
+	if !reflect.DeepEqual(uris, want) {
+		t.Fatalf("URIs = %q, want %q", uris, want)
+	}
+
+	written, err := os.ReadFile(filepath.Join(b.launchConfigDir, "_claude_grid_my_sprint.yaml"))
+	if err != nil {
+		t.Fatalf("launch configuration not written: %v", err)
+	}
+	expected, err := BuildWarpLaunchConfig(opts)
+	if err != nil {
+		t.Fatalf("BuildWarpLaunchConfig() error = %v", err)
+	}
+	if string(written) != string(expected) {
+		t.Errorf("written config =\n%s\nwant\n%s", written, expected)
 	}
 
-	want := "warp://action/new_window?path=/Users/bob/my%20project"
-	if uris[0] != want {
-		t.Fatalf("URI = %q, want %q", uris[0], want)
+	for i, wantID := range []string{"claude-grid:my sprint:1", "claude-grid:my sprint:2"} {
+		if got[i].ID != wantID || got[i].Index != i || got[i].Backend != "warp" {
+			t.Errorf("window %d = %+v, want ID %q", i, got[i], wantID)
+		}
+	}
+}
+
+func TestBuildWarpLaunchConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      SpawnOptions
+		wantCwds  []string
+		wantExecs []string
+	}{
+		{
+			name:      "dir shared by all windows",
+			opts:      SpawnOptions{Count: 2, Dir: "/shared", SessionID: "s"},
+			wantCwds:  []string{"/shared", "/shared"},
+			wantExecs: []string{"export CLAUDE_CODE_DISABLE_TERMINAL_TITLE=1; claude", "export CLAUDE_CODE_DISABLE_TERMINAL_TITLE=1; claude"},
+		},
+		{
+			name: "per-window dirs and prompts",
+			opts: SpawnOptions{
+				Count:     3,
+				Dir:       "/fallback",
+				Dirs:      []string{"/proj/alpha", "/proj/beta", "/proj/gamma"},
+				Prompts:   []string{"fix login", "", "don't panic"},
+				SessionID: "s",
+			},
+			wantCwds: []string{"/proj/alpha", "/proj/beta", "/proj/gamma"},
+			wantExecs: []string{
+				"export CLAUDE_CODE_DISABLE_TERMINAL_TITLE=1; claude 'fix login'",
+				"export CLAUDE_CODE_DISABLE_TERMINAL_TITLE=1; claude",
+				`export CLAUDE_CODE_DISABLE_TERMINAL_TITLE=1; claude 'don'\''t panic'`,
+			},
+		},
+		{
+			name: "custom command with conversation",
+			opts: SpawnOptions{
+				Count:           1,
+				Dir:             "/tmp",
+				Agent:           agent.Profile{Name: "claude", Command: "/usr/local/bin/claude", PromptMode: agent.PromptPositional, ResumeFlag: "--resume"},
+				ConversationIDs: []string{"0d9b2c6e-3c1a-4f1e-9f43-2b8f5f2f1a11"},
+				SessionID:       "s",
+			},
+			wantCwds:  []string{"/tmp"},
+			wantExecs: []string{"export CLAUDE_CODE_DISABLE_TERMINAL_TITLE=1; /usr/local/bin/claude --resume 0d9b2c6e-3c1a-4f1e-9f43-2b8f5f2f1a11"},
+		},
+		{
+			name: "stdin prompt",
+			opts: SpawnOptions{
+				Count:     1,
+				Dir:       "/tmp",
+				Agent:     agent.Profile{Name: "claude", Command: "claude", PromptMode: agent.PromptStdin},
+				Prompts:   []string{"fix login"},
+				SessionID: "s",
+			},
+			wantCwds:  []string{"/tmp"},
+			wantExecs: []string{`export CLAUDE_CODE_DISABLE_TERMINAL_TITLE=1; printf '%s\n' 'fix login' | claude`},
+		},
+		{
+			name: "other agent",
+			opts: SpawnOptions{
+				Count:     1,
+				Dir:       "/tmp",
+				Agent:     agent.Profile{Name: "codex", Command: "codex", PromptMode: agent.PromptPositional},
+				Prompts:   []string{"fix login"},
+				SessionID: "s",
+			},
+			wantCwds:  []string{"/tmp"},
+			wantExecs: []string{"codex 'fix login'"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := BuildWarpLaunchConfig(tt.opts)
+			if err != nil {
+				t.Fatalf("BuildWarpLaunchConfig() error = %v", err)
+			}
+
+			var config warpLaunchConfig
+			if err := yaml.Unmarshal(data, &config); err != nil {
+				t.Fatalf("config is not YAML: %v\n%s", err, data)
+			}
+			if config.Name != "claude-grid-s" {
+				t.Errorf("name = %q, want %q", config.Name, "claude-grid-s")
+			}
+			if len(config.Windows) != tt.opts.Count {
+				t.Fatalf("window count = %d, want %d", len(config.Windows), tt.opts.Count)
+			}
+			for i, w := range config.Windows {
+				if len(w.Tabs) != 1 {
+					t.Fatalf("window %d tab count = %d, want 1", i, len(w.Tabs))
+				}
+				tab := w.Tabs[0]
+				if want := WarpWindowTitle("s", i); tab.Title != want {
+					t.Errorf("window %d title = %q, want %q", i, tab.Title, want)
+				}
+				if tab.Layout.Cwd != tt.wantCwds[i] {
+					t.Errorf("window %d cwd = %q, want %q", i, tab.Layout.Cwd, tt.wantCwds[i])
+				}
+				if len(tab.Layout.Commands) != 1 || tab.Layout.Commands[0].Exec != tt.wantExecs[i] {
+					t.Errorf("window %d commands = %+v, want exec %q", i, tab.Layout.Commands, tt.wantExecs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBuildWarpLaunchConfigErrors(t *testing.T) {
+	if _, err := BuildWarpLaunchConfig(SpawnOptions{Count: 0}); err == nil {
+		t.Error("expected error for zero count")
+	}
+	if _, err := BuildWarpLaunchConfig(SpawnOptions{Count: 3, Dirs: []string{"/a"}}); err == nil {
+		t.Error("expected error for missing dirs")
 	}
 }
 
@@ -103,7 +254,7 @@ func TestWarpTilingScript(t *testing.T) {
 		{X: 100, Y: 80, Width: 100, Height: 80},
 	}
 
-	if err := TileWarpWindows(executor, bounds); err != nil {
+	if err := TileWarpWindows(executor, "sprint", bounds); err != nil {
 		t.Fatalf("TileWarpWindows() error = %v", err)
 	}
 
@@ -111,32 +262,35 @@ func TestWarpTilingScript(t *testing.T) {
 		t.Fatalf("RunAppleScript call count = %d, want 1", len(executor.scripts))
 	}
 
+	// Each window is addressed by its exact title, not its stacking order.
 	script := executor.scripts[0]
+	if strings.Contains(script, "of window 1") {
+		t.Fatalf("script addresses windows by index:\n%s", script)
+	}
 	for i, b := range bounds {
-		idx := i + 1
-		positionLine := fmt.Sprintf("set position of window %d to {%d, %d}", idx, b.X, b.Y)
-		sizeLine := fmt.Sprintf("set size of window %d to {%d, %d}", idx, b.Width, b.Height)
-		if !strings.Contains(script, positionLine) {
-			t.Fatalf("missing line: %q\nscript:\n%s", positionLine, script)
-		}
-		if !strings.Contains(script, sizeLine) {
-			t.Fatalf("missing line: %q\nscript:\n%s", sizeLine, script)
+		want := strings.Join([]string{
+			fmt.Sprintf(`    set w to first window whose name is "%s"`, WarpWindowTitle("sprint", i)),
+			fmt.Sprintf("    set position of w to {%d, %d}", b.X, b.Y),
+			fmt.Sprintf("    set size of w to {%d, %d}", b.Width, b.Height),
+		}, "\n")
+		if !strings.Contains(script, want+"\n") {
+			t.Fatalf("missing lines:\n%s\nscript:\n%s", want, script)
 		}
 	}
 }
 
 func TestWarpBackoff(t *testing.T) {
 	executor := &warpMockExecutor{}
-	b := NewWarpBackend(executor)
-
-	b.runOpen = func(context.Context, string) error { return nil }
-	b.sleepFn = func(time.Duration) {}
-	b.tileWindowsFn = func(context.Context, []grid.WindowBounds) error { return nil }
+	b := newTestWarpBackend(t, executor)
+	b.waitForWindowCountFn = b.waitForWindowCount
 
 	counts := []string{"0", "1", "2", "4"}
 	pollCount := 0
 	executor.runFn = func(ctx context.Context, script string) (string, error) {
-		if strings.Contains(script, "count windows") {
+		if strings.Contains(script, "count (every window") {
+			if !strings.Contains(script, `whose name contains "claude-grid:sprint:"`) {
+				t.Fatalf("poll does not filter by session marker:\n%s", script)
+			}
 			if pollCount >= len(counts) {
 				return counts[len(counts)-1], nil
 			}
@@ -148,14 +302,10 @@ func TestWarpBackoff(t *testing.T) {
 	}
 
 	_, err := b.SpawnWindows(context.Background(), SpawnOptions{
-		Count: 4,
-		Dir:   "/tmp",
-		Bounds: []grid.WindowBounds{
-			{X: 0, Y: 0, Width: 10, Height: 10},
-			{X: 10, Y: 0, Width: 10, Height: 10},
-			{X: 0, Y: 10, Width: 10, Height: 10},
-			{X: 10, Y: 10, Width: 10, Height: 10},
-		},
+		Count:     4,
+		Dir:       "/tmp",
+		SessionID: "sprint",
+		Bounds:    testBounds(4),
 	})
 	if err != nil {
 		t.Fatalf("SpawnWindows() error = %v", err)
@@ -173,7 +323,7 @@ func TestWarpAccessibilityError(t *testing.T) {
 		},
 	}
 
-	err := TileWarpWindows(executor, []grid.WindowBounds{{X: 0, Y: 0, Width: 100, Height: 100}})
+	err := TileWarpWindows(executor, "sprint", []grid.WindowBounds{{X: 0, Y: 0, Width: 100, Height: 100}})
 	if err == nil {
 		t.Fatalf("TileWarpWindows() error = nil, want error")
 	}
@@ -183,180 +333,47 @@ func TestWarpAccessibilityError(t *testing.T) {
 	}
 }
 
-func TestWarpSpawnWindowsScenarios(t *testing.T) {
-	tests := []struct {
-		name      string
-		opts      SpawnOptions
-		openErrAt int
-		wantErr   bool
-	}{
-		{
-			name: "single window success",
-			opts: SpawnOptions{
-				Count: 1,
-				Dir:   "/tmp",
-				Bounds: []grid.WindowBounds{
-					{X: 0, Y: 0, Width: 100, Height: 100},
-				},
-			},
-		},
-		{
-			name: "multiple windows success",
-			opts: SpawnOptions{
-				Count: 3,
-				Dir:   "/tmp",
-				Bounds: []grid.WindowBounds{
-					{X: 0, Y: 0, Width: 100, Height: 100},
-					{X: 100, Y: 0, Width: 100, Height: 100},
-					{X: 0, Y: 100, Width: 100, Height: 100},
-				},
-			},
-		},
-		{
-			name: "open error",
-			opts: SpawnOptions{
-				Count: 2,
-				Dir:   "/tmp",
-				Bounds: []grid.WindowBounds{
-					{X: 0, Y: 0, Width: 100, Height: 100},
-					{X: 100, Y: 0, Width: 100, Height: 100},
-				},
-			},
-			openErrAt: 2,
-			wantErr:   true,
-		},
-	}
+func TestWarpSpawnWindowsErrors(t *testing.T) {
+	t.Run("open error", func(t *testing.T) {
+		b := newTestWarpBackend(t, &warpMockExecutor{})
+		b.runOpen = func(ctx context.Context, uri string) error { return errors.New("open failed") }
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			b := NewWarpBackend(&warpMockExecutor{})
-			openCalls := 0
-			b.runOpen = func(ctx context.Context, uri string) error {
-				openCalls++
-				if tt.openErrAt > 0 && openCalls == tt.openErrAt {
-					return errors.New("open failed")
-				}
-				return nil
-			}
-			b.sleepFn = func(time.Duration) {}
-			b.waitForWindowCountFn = func(context.Context, int) error { return nil }
-			b.tileWindowsFn = func(context.Context, []grid.WindowBounds) error { return nil }
-
-			got, err := b.SpawnWindows(context.Background(), tt.opts)
-			if (err != nil) != tt.wantErr {
-				t.Fatalf("SpawnWindows() err = %v, wantErr %v", err, tt.wantErr)
-			}
-			if tt.wantErr {
-				return
-			}
-
-			if len(got) != tt.opts.Count {
-				t.Fatalf("window info count = %d, want %d", len(got), tt.opts.Count)
-			}
-			for i := range got {
-				if got[i].Backend != "warp" {
-					t.Fatalf("window %d backend = %q, want %q", i, got[i].Backend, "warp")
-				}
-				if got[i].Index != i {
-					t.Fatalf("window %d index = %d, want %d", i, got[i].Index, i)
-				}
-			}
-		})
-	}
-}
-
-func TestWarpPerWindowDirs(t *testing.T) {
-	tests := []struct {
-		name     string
-		opts     SpawnOptions
-		wantURIs []string
-	}{
-		{
-			name: "dirs override produces per-window URIs",
-			opts: SpawnOptions{
-				Count: 3,
-				Dir:   "/fallback",
-				Dirs:  []string{"/proj/alpha", "/proj/beta", "/proj/gamma"},
-				Bounds: []grid.WindowBounds{
-					{X: 0, Y: 0, Width: 100, Height: 100},
-					{X: 100, Y: 0, Width: 100, Height: 100},
-					{X: 0, Y: 100, Width: 100, Height: 100},
-				},
-			},
-			wantURIs: []string{
-				"warp://action/new_window?path=/proj/alpha",
-				"warp://action/new_window?path=/proj/beta",
-				"warp://action/new_window?path=/proj/gamma",
-			},
-		},
-		{
-			name: "empty dirs falls back to Dir for all windows",
-			opts: SpawnOptions{
-				Count: 2,
-				Dir:   "/shared",
-				Bounds: []grid.WindowBounds{
-					{X: 0, Y: 0, Width: 100, Height: 100},
-					{X: 100, Y: 0, Width: 100, Height: 100},
-				},
-			},
-			wantURIs: []string{
-				"warp://action/new_window?path=/shared",
-				"warp://action/new_window?path=/shared",
-			},
-		},
-		{
-			name: "dirs with spaces are encoded correctly",
-			opts: SpawnOptions{
-				Count: 2,
-				Dir:   "/tmp",
-				Dirs:  []string{"/my project", "/other dir"},
-				Bounds: []grid.WindowBounds{
-					{X: 0, Y: 0, Width: 100, Height: 100},
-					{X: 100, Y: 0, Width: 100, Height: 100},
-				},
-			},
-			wantURIs: []string{
-				"warp://action/new_window?path=/my%20project",
-				"warp://action/new_window?path=/other%20dir",
-			},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			b := NewWarpBackend(&warpMockExecutor{})
-			var gotURIs []string
-			b.runOpen = func(ctx context.Context, uri string) error {
-				gotURIs = append(gotURIs, uri)
-				return nil
-			}
-			b.sleepFn = func(time.Duration) {}
-			b.waitForWindowCountFn = func(context.Context, int) error { return nil }
-			b.tileWindowsFn = func(context.Context, []grid.WindowBounds) error { return nil }
+		if _, err := b.SpawnWindows(context.Background(), SpawnOptions{Count: 1, Dir: "/tmp", Bounds: testBounds(1)}); err == nil {
+			t.Fatal("SpawnWindows() error = nil, want error")
+		}
+	})
 
-			_, err := b.SpawnWindows(context.Background(), tt.opts)
-			if err != nil {
-				t.Fatalf("SpawnWindows() error = %v", err)
-			}
+	t.Run("insufficient bounds", func(t *testing.T) {
+		b := newTestWarpBackend(t, &warpMockExecutor{})
+		if _, err := b.SpawnWindows(context.Background(), SpawnOptions{Count: 2, Dir: "/tmp", Bounds: testBounds(1)}); err == nil {
+			t.Fatal("SpawnWindows() error = nil, want error")
+		}
+	})
 
-			if len(gotURIs) != len(tt.wantURIs) {
-				t.Fatalf("URI count = %d, want %d", len(gotURIs), len(tt.wantURIs))
-			}
-			for i, want := range tt.wantURIs {
-				if gotURIs[i] != want {
-					t.Errorf("URI[%d] = %q, want %q", i, gotURIs[i], want)
-				}
+	t.Run("no keystrokes sent", func(t *testing.T) {
+		executor := &warpMockExecutor{}
+		b := newTestWarpBackend(t, executor)
+		if _, err := b.SpawnWindows(context.Background(), SpawnOptions{Count: 2, Dir: "/tmp", Bounds: testBounds(2)}); err != nil {
+			t.Fatalf("SpawnWindows() error = %v", err)
+		}
+		for _, s := range executor.scripts {
+			if strings.Contains(s, "keystroke") {
+				t.Fatalf("SpawnWindows typed into a window:\n%s", s)
 			}
-		})
-	}
+		}
+	})
 }
 
 func TestWarpCloseSession(t *testing.T) {
 	executor := &warpMockExecutor{}
-	b := NewWarpBackend(executor)
+	b := newTestWarpBackend(t, executor)
 
-	err := b.CloseSession("session-123")
-	if err != nil {
+	configPath := b.launchConfigPath("session-123")
+	if err := os.WriteFile(configPath, []byte("name: x\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if err := b.CloseSession("session-123"); err != nil {
 		t.Fatalf("CloseSession() error = %v", err)
 	}
 
@@ -364,79 +381,63 @@ func TestWarpCloseSession(t *testing.T) {
 		t.Fatalf("RunAppleScript call count = %d, want 1", len(executor.scripts))
 	}
 
-	if !strings.Contains(executor.scripts[0], "close window") {
-		t.Fatalf("CloseSession script missing close window command:\n%s", executor.scripts[0])
+	script := executor.scripts[0]
+	if !strings.Contains(script, `every window whose name contains "claude-grid:session-123:"`) {
+		t.Fatalf("CloseSession script does not filter by session marker:\n%s", script)
+	}
+	if !strings.Contains(script, "AXCloseButton") {
+		t.Fatalf("CloseSession script missing close button click:\n%s", script)
 	}
-}
 
-func TestWarpPerWindowPrompts(t *testing.T) {
-	tests := []struct {
-		name              string
-		prompts           []string
-		count             int
-		wantKeystrokeStrs []string
-	}{
-		{
-			name:              "per-window different prompts",
-			prompts:           []string{"fix login", "add tests", "update docs"},
-			count:             3,
-			wantKeystrokeStrs: []string{"fix login", "add tests", "update docs"},
-		},
-		{
-			name:              "no prompts — backward compat",
-			prompts:           nil,
-			count:             2,
-			wantKeystrokeStrs: []string{"claude"},
-		},
-		{
-			name:              "partial prompts — first window has prompt, rest bare",
-			prompts:           []string{"fix login"},
-			count:             3,
-			wantKeystrokeStrs: []string{"fix login", "claude"},
-		},
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		t.Errorf("launch configuration still exists after CloseSession: %v", err)
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			executor := &warpMockExecutor{}
-			b := NewWarpBackend(executor)
-			b.runOpen = func(ctx context.Context, uri string) error { return nil }
-			b.sleepFn = func(time.Duration) {}
-			b.waitForWindowCountFn = func(context.Context, int) error { return nil }
-			b.tileWindowsFn = func(context.Context, []grid.WindowBounds) error { return nil }
-
-			bounds := make([]grid.WindowBounds, tt.count)
-			for i := range bounds {
-				bounds[i] = grid.WindowBounds{X: i * 100, Y: 0, Width: 100, Height: 100}
-			}
+	// A second close finds no configuration to remove and still succeeds.
+	if err := b.CloseSession("session-123"); err != nil {
+		t.Fatalf("second CloseSession() error = %v", err)
+	}
+}
 
-			_, err := b.SpawnWindows(context.Background(), SpawnOptions{
-				Count:   tt.count,
-				Dir:     "/tmp",
-				Bounds:  bounds,
-				Prompts: tt.prompts,
-			})
-			if err != nil {
-				t.Fatalf("SpawnWindows() error = %v", err)
-			}
+func TestWarpSendInput(t *testing.T) {
+	executor := &warpMockExecutor{}
+	b := NewWarpBackend(executor)
 
-			// Find the keystroke script (last RunAppleScript call that contains "keystroke")
-			var keystrokeScript string
-			for _, s := range executor.scripts {
-				if strings.Contains(s, "keystroke") {
-					keystrokeScript = s
-					break
-				}
-			}
-			if keystrokeScript == "" {
-				t.Fatalf("no keystroke script found in captured scripts: %v", executor.scripts)
-			}
+	title := WarpWindowTitle("sprint", 1)
+	if err := b.SendInput(context.Background(), session.WindowRef{ID: title, Index: 1}, `fix "it" with `+"`go test`"+` and $(pwd)`); err != nil {
+		t.Fatalf("SendInput() error = %v", err)
+	}
+	if len(executor.scripts) != 1 {
+		t.Fatalf("script count = %d, want 1", len(executor.scripts))
+	}
+	got := executor.scripts[0]
+	for _, want := range []string{
+		`first window whose name is "claude-grid:sprint:2"`,
+		`perform action "AXRaise"`,
+		`keystroke "fix \"it\" with ` + "`go test`" + ` and $(pwd)"`,
+		"keystroke return",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("script missing %q:\n%s", want, got)
+		}
+	}
 
-			for _, want := range tt.wantKeystrokeStrs {
-				if !strings.Contains(keystrokeScript, want) {
-					t.Errorf("keystroke script missing %q\nscript:\n%s", want, keystrokeScript)
-				}
-			}
-		})
+	// Window 1's title is a prefix of window 10's, so it must match exactly.
+	for _, index := range []int{0, 9} {
+		executor.scripts = nil
+		title := WarpWindowTitle("sprint", index)
+		if err := b.SendInput(context.Background(), session.WindowRef{ID: title, Index: index}, "go"); err != nil {
+			t.Fatalf("SendInput() error = %v", err)
+		}
+		if want := fmt.Sprintf(`first window whose name is "%s"`+"\n", title); !strings.Contains(executor.scripts[0], want) {
+			t.Errorf("window %d script missing %q:\n%s", index+1, want, executor.scripts[0])
+		}
+	}
+
+	executor.runFn = func(ctx context.Context, script string) (string, error) {
+		return "", errors.New("not allowed assistive access")
+	}
+	if err := b.SendInput(context.Background(), session.WindowRef{ID: title}, "x"); err == nil {
+		t.Fatal("SendInput() error = nil, want error")
 	}
 }
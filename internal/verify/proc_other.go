@@ -0,0 +1,8 @@
+//go:build !unix
+
+package verify
+
+import "os/exec"
+
+// Process groups are only used on unix; elsewhere only the shell is killed.
+func setProcessGroup(cmd *exec.Cmd) {}
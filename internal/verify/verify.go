@@ -0,0 +1,108 @@
+// Package verify runs a verification command, such as a test suite, a linter
+// or a benchmark, in the worktrees of a best-of-N session and ranks them by
+// its outcome.
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"time"
+)
+
+// DefaultTimeout bounds one run of the verification command when Run is
+// given no timeout.
+const DefaultTimeout = 30 * time.Minute
+
+// Result is the outcome of one run of the verification command.
+type Result struct {
+	// ExitCode is the command's exit code, or -1 if it could not be started,
+	// timed out or was killed by a signal.
+	ExitCode int
+	Duration time.Duration
+	// Err describes why the command did not pass; nil when it exited 0.
+	Err error
+}
+
+// Passed reports whether the command exited with code 0.
+func (r Result) Passed() bool {
+	return r.Err == nil
+}
+
+// Run runs command with sh -c in dir, writing its combined output to output.
+// The command and every process it starts are killed once timeout has passed;
+// zero means DefaultTimeout.
+func Run(ctx context.Context, dir, command string, timeout time.Duration, output io.Writer) Result {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Stdout = output
+	cmd.Stderr = output
+	setProcessGroup(cmd)
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	// ExitCode is -1 for a command that never started or was signalled.
+	result := Result{ExitCode: cmd.ProcessState.ExitCode(), Duration: time.Since(start)}
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.ExitCode = -1
+		result.Err = fmt.Errorf("timed out after %s", timeout)
+	case errors.As(err, &exitErr) && result.ExitCode > 0:
+		result.Err = fmt.Errorf("exited with code %d", result.ExitCode)
+	case err != nil:
+		result.Err = err
+	}
+	return result
+}
+
+// Score is what a worktree is ranked by.
+type Score struct {
+	Passed bool
+	// Lines is the number of lines the worktree changes.
+	Lines    int
+	Duration time.Duration
+}
+
+// Candidate reports whether the worktree can win: it passed and changes
+// something. A worktree whose agent did nothing usually passes the base's
+// checks, but is no answer.
+func (s Score) Candidate() bool {
+	return s.Passed && s.Lines > 0
+}
+
+// Rank returns the indexes of scores from best to worst: worktrees with
+// changes before those without, passing before failing, then smaller changes
+// before larger ones, then faster runs before slower ones. Equal scores keep
+// their order.
+func Rank(scores []Score) []int {
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		x, y := scores[order[a]], scores[order[b]]
+		if changedX, changedY := x.Lines > 0, y.Lines > 0; changedX != changedY {
+			return changedX
+		}
+		if x.Passed != y.Passed {
+			return x.Passed
+		}
+		if x.Lines != y.Lines {
+			return x.Lines < y.Lines
+		}
+		return x.Duration < y.Duration
+	})
+	return order
+}
@@ -0,0 +1,75 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+
+	var output bytes.Buffer
+	result := Run(context.Background(), dir, "pwd; echo oops >&2", 0, &output)
+	if !result.Passed() || result.ExitCode != 0 || result.Duration <= 0 {
+		t.Errorf("Run() = %+v, want a pass", result)
+	}
+	if !strings.Contains(output.String(), dir) || !strings.Contains(output.String(), "oops") {
+		t.Errorf("output = %q, want stdout and stderr of a command run in %s", output.String(), dir)
+	}
+
+	result = Run(context.Background(), dir, "exit 3", 0, &output)
+	if result.Passed() || result.ExitCode != 3 || result.Err.Error() != "exited with code 3" {
+		t.Errorf("Run() = %+v, want exit code 3", result)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	result := Run(context.Background(), t.TempDir(), "sleep 10", 100*time.Millisecond, &bytes.Buffer{})
+	if result.Passed() || result.ExitCode != -1 || !strings.Contains(result.Err.Error(), "timed out") {
+		t.Errorf("Run() = %+v, want a timeout", result)
+	}
+	if result.Duration > 5*time.Second {
+		t.Errorf("Duration = %s, want the command killed at the timeout", result.Duration)
+	}
+}
+
+func TestRunTimeoutKillsChildren(t *testing.T) {
+	dir := t.TempDir()
+	// sh forks the subshell, so it outlives a kill of sh alone.
+	result := Run(context.Background(), dir, "(sleep 1; touch late); echo done", 100*time.Millisecond, &bytes.Buffer{})
+	if result.Passed() || !strings.Contains(result.Err.Error(), "timed out") {
+		t.Fatalf("Run() = %+v, want a timeout", result)
+	}
+	if result.Duration > 900*time.Millisecond {
+		t.Errorf("Duration = %s, want Run to return at the timeout", result.Duration)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	if _, err := os.Stat(filepath.Join(dir, "late")); !os.IsNotExist(err) {
+		t.Error("command kept running after the timeout")
+	}
+}
+
+func TestRank(t *testing.T) {
+	scores := []Score{
+		{Passed: false, Lines: 1, Duration: time.Second},
+		{Passed: true, Lines: 50, Duration: time.Second},
+		{Passed: true, Lines: 10, Duration: 3 * time.Second},
+		{Passed: true, Lines: 10, Duration: 2 * time.Second},
+		{Passed: false, Lines: 1, Duration: time.Second},
+		// An agent that changed nothing passes the base's checks but ranks last.
+		{Passed: true, Lines: 0, Duration: time.Millisecond},
+	}
+	if got, want := Rank(scores), []int{3, 2, 1, 0, 4, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Rank() = %v, want %v", got, want)
+	}
+	if scores[5].Candidate() || scores[0].Candidate() || !scores[1].Candidate() {
+		t.Error("Candidate() should require a pass and changes")
+	}
+}